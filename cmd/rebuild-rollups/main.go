@@ -0,0 +1,56 @@
+// Command rebuild-rollups recomputes the insights rollups from messages,
+// memories and story reactions, for one user (-user) or everyone. Run it
+// after restoring data, bulk edits that bypass the triggers, or when a
+// user's time zone changes and their past days should move with it.
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+
+	"ai-companion-be/internal/config"
+	"ai-companion-be/internal/database"
+	"ai-companion-be/internal/repository"
+)
+
+func main() {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	userFlag := flag.String("user", "", "rebuild only this user's rollups (uuid)")
+	flag.Parse()
+
+	var userID *uuid.UUID
+	if *userFlag != "" {
+		id, err := uuid.Parse(*userFlag)
+		if err != nil {
+			slog.Error("invalid user id", "user", *userFlag, "error", err)
+			os.Exit(2)
+		}
+		userID = &id
+	}
+
+	_ = godotenv.Load()
+	cfg := config.Load()
+
+	ctx := context.Background()
+	pool, err := database.NewPostgresPool(ctx, cfg.Database)
+	if err != nil {
+		slog.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	start := time.Now()
+	if err := repository.NewInsightsRepository(pool).RebuildRollups(ctx, userID); err != nil {
+		slog.Error("rebuild failed", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("insights rollups rebuilt", "user", *userFlag, "duration", time.Since(start))
+}
@@ -8,16 +8,20 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // user time zones; the runtime image has no zoneinfo
 
 	"github.com/joho/godotenv"
 
 	"ai-companion-be/internal/ai"
 	"ai-companion-be/internal/config"
+	"ai-companion-be/internal/cursor"
 	"ai-companion-be/internal/database"
 	"ai-companion-be/internal/handler"
 	"ai-companion-be/internal/repository"
 	"ai-companion-be/internal/router"
+	"ai-companion-be/internal/scheduler"
 	"ai-companion-be/internal/service"
+	"ai-companion-be/internal/storage"
 )
 
 func main() {
@@ -54,19 +58,68 @@ func main() {
 	messageRepo := repository.NewMessageRepository(pool)
 	relationshipRepo := repository.NewRelationshipRepository(pool)
 	memoryRepo := repository.NewMemoryRepository(pool)
+	memoryTagRepo := repository.NewMemoryTagRepository(pool)
+	memorySurfacingRepo := repository.NewMemorySurfacingRepository(pool)
+	memoryCollectionRepo := repository.NewMemoryCollectionRepository(pool)
+	searchRepo := repository.NewSearchRepository(pool)
+	embeddingRepo := repository.NewEmbeddingRepository(pool)
 	insightsRepo := repository.NewInsightsRepository(pool)
+	assetRepo := repository.NewAssetRepository(pool)
+	storyDraftRepo := repository.NewStoryDraftRepository(pool)
+	jobRunRepo := repository.NewJobRunRepository(pool)
+	reactionRepo := repository.NewReactionRepository(pool)
+	analyticsRepo := repository.NewStoryAnalyticsRepository(pool)
+	milestoneRepo := repository.NewMilestoneRepository(pool)
+	notificationRepo := repository.NewNotificationRepository(pool)
+	conversationRepo := repository.NewConversationRepository(pool)
+	recapRepo := repository.NewRecapRepository(pool)
+	sessionRepo := repository.NewSessionRepository(pool)
+
+	// Media storage.
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		slog.Error("failed to initialize storage", "error", err)
+		os.Exit(1)
+	}
+	var mediaFiles http.Handler
+	if local, ok := store.(*storage.LocalStorage); ok {
+		mediaFiles = local.Handler()
+	}
 
 	// AI client.
 	aiClient := ai.NewClient(cfg.OpenAI)
+	embedder := ai.NewEmbedder(cfg.Embedding, cfg.OpenAI)
+
+	// Pagination cursors.
+	cursors := cursor.New(cfg.CursorSecret)
 
 	// Services.
-	authSvc := service.NewAuthService(userRepo, cfg.JWT)
+	authSvc := service.NewAuthService(userRepo, sessionRepo, cfg.JWT)
 	companionSvc := service.NewCompanionService(companionRepo)
-	storySvc := service.NewStoryService(storyRepo, relationshipRepo, insightsRepo)
-	messageSvc := service.NewMessageService(messageRepo, relationshipRepo, companionRepo, aiClient, insightsRepo)
+	milestoneSvc := service.NewMilestoneService(milestoneRepo, insightsRepo, relationshipRepo, userRepo, companionRepo, messageRepo, notificationRepo, aiClient)
+	notificationSvc := service.NewNotificationService(notificationRepo, cursors)
+	storySvc := service.NewStoryService(storyRepo, relationshipRepo, insightsRepo, reactionRepo, milestoneSvc)
+	retrievalSvc := service.NewRetrievalService(embeddingRepo, messageRepo, memoryRepo, embedder, cfg.Embedding, cfg.Memories)
+	messageSvc := service.NewMessageService(messageRepo, relationshipRepo, companionRepo, aiClient, insightsRepo, storyRepo, retrievalSvc, milestoneSvc, cursors)
 	relationshipSvc := service.NewRelationshipService(relationshipRepo)
-	memorySvc := service.NewMemoryService(memoryRepo)
-	insightsSvc := service.NewInsightsService(insightsRepo, relationshipRepo)
+	memorySvc := service.NewMemoryService(memoryRepo, memoryTagRepo, messageRepo, storyRepo, aiClient, milestoneSvc, cursors, cfg.Memories)
+	resurfacingSvc := service.NewResurfacingService(memorySurfacingRepo, memoryRepo, messageRepo, companionRepo, aiClient, cfg.Memories)
+	collectionSvc := service.NewCollectionService(memoryCollectionRepo, memoryRepo, companionRepo, aiClient)
+	searchSvc := service.NewSearchService(searchRepo, cursors)
+	conversationSvc := service.NewConversationService(conversationRepo, messageRepo, embeddingRepo, embedder, cfg.Conversations)
+	recapSvc := service.NewRecapService(recapRepo, messageRepo, memoryRepo, insightsRepo, milestoneRepo, companionRepo, notificationRepo, aiClient, cursors)
+	insightsSvc := service.NewInsightsService(insightsRepo, userRepo, milestoneSvc, conversationSvc)
+	mediaSvc := service.NewMediaService(store, companionRepo, storyRepo, assetRepo, cfg.Storage)
+	reactionSvc := service.NewReactionService(reactionRepo)
+	analyticsSvc := service.NewAnalyticsService(analyticsRepo, storyRepo, reactionRepo, cfg.Jobs.StoryViewRetention)
+	storyGen := service.NewStoryGenerator(companionRepo, assetRepo, storyDraftRepo, storyRepo, aiClient, cfg.StoryGen)
+
+	// Scheduled jobs.
+	sched := scheduler.New(jobRunRepo)
+	if err := registerJobs(sched, cfg, authSvc, storySvc, storyGen, analyticsSvc, resurfacingSvc, retrievalSvc, conversationSvc, recapSvc, jobRunRepo); err != nil {
+		slog.Error("failed to register jobs", "error", err)
+		os.Exit(1)
+	}
 
 	// Handlers.
 	authH := handler.NewAuthHandler(authSvc)
@@ -74,11 +127,20 @@ func main() {
 	storyH := handler.NewStoryHandler(storySvc)
 	messageH := handler.NewMessageHandler(messageSvc)
 	relationshipH := handler.NewRelationshipHandler(relationshipSvc)
-	memoryH := handler.NewMemoryHandler(memorySvc)
+	memoryH := handler.NewMemoryHandler(memorySvc, resurfacingSvc)
+	collectionH := handler.NewCollectionHandler(collectionSvc)
+	searchH := handler.NewSearchHandler(searchSvc, retrievalSvc)
 	insightsH := handler.NewInsightsHandler(insightsSvc)
+	notificationH := handler.NewNotificationHandler(notificationSvc)
+	recapH := handler.NewRecapHandler(recapSvc)
+	mediaH := handler.NewMediaHandler(mediaSvc)
+	storyDraftH := handler.NewStoryDraftHandler(storyGen)
+	jobH := handler.NewJobHandler(sched)
+	reactionH := handler.NewReactionHandler(reactionSvc)
+	analyticsH := handler.NewAnalyticsHandler(analyticsSvc)
 
 	// Router.
-	r := router.New(cfg, authH, companionH, storyH, messageH, relationshipH, memoryH, insightsH)
+	r := router.New(cfg, authSvc, authH, companionH, storyH, messageH, relationshipH, memoryH, collectionH, searchH, insightsH, notificationH, recapH, mediaH, storyDraftH, jobH, reactionH, analyticsH, mediaFiles)
 
 	// Server.
 	srv := &http.Server{
@@ -88,6 +150,10 @@ func main() {
 		WriteTimeout: cfg.Server.WriteTimeout,
 	}
 
+	if cfg.Jobs.Enabled {
+		sched.Start()
+	}
+
 	// Graceful shutdown.
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
@@ -109,6 +175,75 @@ func main() {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		slog.Error("server shutdown error", "error", err)
 	}
+	if err := sched.Stop(shutdownCtx); err != nil {
+		slog.Error("scheduler shutdown error", "error", err)
+	}
 
 	slog.Info("server stopped")
 }
+
+// registerJobs adds the maintenance jobs to the scheduler.
+func registerJobs(
+	sched *scheduler.Scheduler,
+	cfg *config.Config,
+	auth *service.AuthService,
+	stories *service.StoryService,
+	storyGen *service.StoryGenerator,
+	analytics *service.AnalyticsService,
+	resurfacing *service.ResurfacingService,
+	retrieval *service.RetrievalService,
+	conversations *service.ConversationService,
+	recaps *service.RecapService,
+	jobRuns repository.JobRunRepository,
+) error {
+	if err := sched.Register("story-cleanup", cfg.Jobs.StoryCleanup, time.Minute, stories.CleanupExpired); err != nil {
+		return err
+	}
+
+	if err := sched.Register("story-analytics-rollup", cfg.Jobs.StoryAnalytics, 5*time.Minute, analytics.Refresh); err != nil {
+		return err
+	}
+
+	if err := sched.Register("memory-resurfacing", cfg.Jobs.MemoryResurfacing, 10*time.Minute, resurfacing.RunDaily); err != nil {
+		return err
+	}
+
+	if err := sched.Register("embedding-index", cfg.Jobs.EmbeddingIndex, 5*time.Minute, retrieval.Index); err != nil {
+		return err
+	}
+
+	if err := sched.Register("conversation-analytics", cfg.Jobs.ConversationAnalytics, 30*time.Minute, conversations.Run); err != nil {
+		return err
+	}
+
+	if err := sched.Register("weekly-recap", cfg.Jobs.WeeklyRecap, 30*time.Minute, recaps.Run); err != nil {
+		return err
+	}
+
+	if err := sched.Register("job-history-prune", "@daily", time.Minute, func(ctx context.Context) error {
+		n, err := jobRuns.DeleteBefore(ctx, time.Now().Add(-cfg.Jobs.HistoryRetention))
+		if err == nil && n > 0 {
+			slog.Info("job history pruned", "count", n)
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if err := sched.Register("session-prune", "@daily", time.Minute, func(ctx context.Context) error {
+		n, err := auth.PruneSessions(ctx)
+		if err == nil && n > 0 {
+			slog.Info("sessions pruned", "count", n)
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if cfg.StoryGen.Enabled {
+		if err := sched.Register("story-generation", cfg.StoryGen.Schedule, 30*time.Minute, storyGen.RunOnce); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+
+	"ai-companion-be/internal/config"
+)
+
+// Embedder turns text into fixed-length vectors whose cosine similarity
+// reflects how related the texts are.
+type Embedder interface {
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+
+	// Model identifies the embedding space. Vectors from different models
+	// (or dimensions) must never be compared.
+	Model() string
+}
+
+// NewEmbedder returns the embedder selected by cfg. The OpenAI embedder
+// needs an API key; without one the local embedder is used.
+func NewEmbedder(cfg config.EmbeddingConfig, openAI config.OpenAIConfig) Embedder {
+	if cfg.Provider == "openai" && openAI.APIKey != "" {
+		client := openai.NewClient(option.WithAPIKey(openAI.APIKey))
+		return &openAIEmbedder{client: &client, model: cfg.Model, dims: cfg.Dimensions}
+	}
+	return NewHashEmbedder(cfg.Dimensions)
+}
+
+type openAIEmbedder struct {
+	client *openai.Client
+	model  string
+	dims   int
+}
+
+func (e *openAIEmbedder) Model() string {
+	return fmt.Sprintf("%s@%d", e.model, e.dims)
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	// The API rejects empty inputs.
+	inputs := make([]string, len(texts))
+	for i, t := range texts {
+		if inputs[i] = strings.TrimSpace(t); inputs[i] == "" {
+			inputs[i] = "."
+		}
+	}
+
+	resp, err := e.client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		Input:      openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: inputs},
+		Model:      e.model,
+		Dimensions: openai.Int(int64(e.dims)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("openai embeddings: %w", err)
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("openai returned %d embeddings for %d inputs", len(resp.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || int(d.Index) >= len(texts) {
+			return nil, fmt.Errorf("openai returned embedding index %d out of range", d.Index)
+		}
+		v := make([]float32, len(d.Embedding))
+		for i, x := range d.Embedding {
+			v[i] = float32(x)
+		}
+		vectors[d.Index] = v
+	}
+	return vectors, nil
+}
+
+// HashEmbedder is a deterministic, offline embedder. It hashes words and
+// their character trigrams into a fixed number of buckets, so texts sharing
+// words or word stems ("hike", "hiking") score as similar. It captures no
+// meaning beyond that, but needs no network and gives stable vectors for
+// tests and local development.
+type HashEmbedder struct {
+	dims int
+}
+
+// NewHashEmbedder creates a HashEmbedder producing vectors of length dims.
+func NewHashEmbedder(dims int) *HashEmbedder {
+	if dims <= 0 {
+		dims = 256
+	}
+	return &HashEmbedder{dims: dims}
+}
+
+func (e *HashEmbedder) Model() string {
+	return fmt.Sprintf("local-hash@%d", e.dims)
+}
+
+func (e *HashEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, t := range texts {
+		vectors[i] = e.embed(t)
+	}
+	return vectors, nil
+}
+
+func (e *HashEmbedder) embed(text string) []float32 {
+	v := make([]float32, e.dims)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if stopWords[w] {
+			continue
+		}
+		e.add(v, "w:"+w, 1)
+		padded := []rune("#" + w + "#")
+		for j := 0; j+3 <= len(padded); j++ {
+			e.add(v, "t:"+string(padded[j:j+3]), 0.5)
+		}
+	}
+	normalize(v)
+	return v
+}
+
+// add hashes feature into a bucket, with a hashed sign so collisions tend
+// to cancel out rather than accumulate.
+func (e *HashEmbedder) add(v []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	v[sum%uint64(e.dims)] += weight
+}
+
+func normalize(v []float32) {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	if norm == 0 {
+		return
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range v {
+		v[i] *= scale
+	}
+}
+
+// stopWords are skipped by the HashEmbedder; they would make every pair of
+// texts look somewhat alike.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "do": true, "for": true, "from": true, "i": true, "if": true, "in": true, "is": true,
+	"it": true, "its": true, "me": true, "my": true, "of": true, "on": true, "or": true, "so": true,
+	"that": true, "the": true, "this": true, "to": true, "was": true, "we": true, "were": true,
+	"with": true, "you": true, "your": true, "im": true, "s": true, "t": true,
+}
@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func TestHashEmbedderIsDeterministic(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"sentence", "I adopted a puppy named Biscuit last week"},
+		{"punctuation and case", "Biscuit!! loves the BEACH, doesn't he?"},
+		{"non-latin", "Tôi thích phở bò"},
+		{"stop words only", "it is the"},
+		{"empty", ""},
+	}
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, err := NewHashEmbedder(128).Embed(ctx, []string{tt.text})
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := NewHashEmbedder(128).Embed(ctx, []string{tt.text, tt.text})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, v := range second {
+				if len(v) != 128 {
+					t.Fatalf("len = %d, want 128", len(v))
+				}
+				for i := range v {
+					if v[i] != first[0][i] {
+						t.Fatalf("component %d = %v, want %v", i, v[i], first[0][i])
+					}
+				}
+			}
+			norm := math.Sqrt(dot(first[0], first[0]))
+			if norm != 0 && math.Abs(norm-1) > 1e-5 {
+				t.Errorf("norm = %v, want 1 or 0", norm)
+			}
+		})
+	}
+}
+
+func TestHashEmbedderDefaultsDimensions(t *testing.T) {
+	e := NewHashEmbedder(0)
+	if got, want := e.Model(), "local-hash@256"; got != want {
+		t.Errorf("Model() = %q, want %q", got, want)
+	}
+}
+
+func TestHashEmbedderSimilarityOrdering(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		closer  string
+		further string
+	}{
+		{
+			"shared words",
+			"my dog Biscuit loves the beach",
+			"Biscuit the dog ran along the beach",
+			"I have a dentist appointment on Tuesday",
+		},
+		{
+			"word forms",
+			"she is learning to play the piano",
+			"piano lessons every Saturday",
+			"we cooked pasta for dinner",
+		},
+		{
+			"stop words ignored",
+			"coffee in the morning",
+			"morning coffee",
+			"in the morning it is the",
+		},
+	}
+	ctx := context.Background()
+	e := NewHashEmbedder(256)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := e.Embed(ctx, []string{tt.query, tt.closer, tt.further})
+			if err != nil {
+				t.Fatal(err)
+			}
+			closer, further := dot(v[0], v[1]), dot(v[0], v[2])
+			if closer <= further {
+				t.Errorf("similarity to %q = %v, want more than %v for %q", tt.closer, closer, further, tt.further)
+			}
+		})
+	}
+}
@@ -2,10 +2,14 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/shared"
 
 	"ai-companion-be/internal/config"
 	"ai-companion-be/internal/models"
@@ -26,16 +30,39 @@ func NewClient(cfg config.OpenAIConfig) *Client {
 	}
 }
 
+// ReplyContext is everything the companion knows when writing a reply.
+type ReplyContext struct {
+	Mood              string
+	RelationshipScore float64
+
+	// History is the recent conversation in chronological order.
+	History []models.Message
+
+	// StoryReply is the story slide the latest user message replies to, if any.
+	StoryReply *models.StoryInteraction
+
+	// StoryReactions are the user's recent reactions to the companion's stories.
+	StoryReactions []models.StoryInteraction
+
+	// Recalled are older exchanges and memories related to the latest
+	// message, beyond History.
+	Recalled []models.RecalledExchange
+
+	// Memories are the strongest memories of the relationship, whatever is
+	// being talked about.
+	Memories []models.Memory
+}
+
 // GenerateReply calls OpenAI to produce a companion response given conversation context.
-func (c *Client) GenerateReply(ctx context.Context, companion *models.Companion, mood string, relationshipScore float64, history []models.Message) (string, error) {
-	systemPrompt := buildSystemPrompt(companion, mood, relationshipScore)
+func (c *Client) GenerateReply(ctx context.Context, companion *models.Companion, rc ReplyContext) (string, error) {
+	systemPrompt := buildSystemPrompt(companion, rc)
 
 	messages := []openai.ChatCompletionMessageParamUnion{
 		openai.SystemMessage(systemPrompt),
 	}
 
 	// Add recent conversation history (already in chronological order).
-	for _, msg := range history {
+	for _, msg := range rc.History {
 		if msg.Role == "user" {
 			messages = append(messages, openai.UserMessage(msg.Content))
 		} else {
@@ -60,8 +87,341 @@ func (c *Client) GenerateReply(ctx context.Context, companion *models.Companion,
 	return resp.Choices[0].Message.Content, nil
 }
 
-func buildSystemPrompt(companion *models.Companion, mood string, relationshipScore float64) string {
-	bondLevel := describeBond(relationshipScore)
+// GenerateStoryCaption writes a short in-character "life update" caption for a
+// story built from the given library assets.
+func (c *Client) GenerateStoryCaption(ctx context.Context, companion *models.Companion, assets []models.CompanionAsset) (string, error) {
+	var scene strings.Builder
+	for _, a := range assets {
+		scene.WriteString("- a " + a.MediaType)
+		if a.Description != "" {
+			scene.WriteString(": " + a.Description)
+		}
+		if len(a.Tags) > 0 {
+			scene.WriteString(" (" + strings.Join(a.Tags, ", ") + ")")
+		}
+		scene.WriteString("\n")
+	}
+
+	prompt := fmt.Sprintf(`You are %s, posting a story on your social media for the people you talk to.
+
+About you: %s
+Your personality: %s
+
+You're posting:
+%s
+Write the caption for this story as a quick update about your life right now. Rules:
+- One or two short lines, under 140 characters total.
+- Write like a real person in their 20s: mostly lowercase, casual, at most one emoji.
+- Stay in character. Never mention being an AI, a post, or "my followers".
+- Output only the caption text, no quotes.`,
+		companion.Name,
+		companion.Description,
+		companion.Personality,
+		scene.String(),
+	)
+
+	resp, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:       c.model,
+		Messages:    []openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)},
+		MaxTokens:   openai.Int(80),
+		Temperature: openai.Float(0.95),
+	})
+	if err != nil {
+		return "", fmt.Errorf("openai chat completion: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("openai returned no choices")
+	}
+
+	caption := strings.Trim(strings.TrimSpace(resp.Choices[0].Message.Content), `"`)
+	if caption == "" {
+		return "", fmt.Errorf("openai returned an empty caption")
+	}
+	return caption, nil
+}
+
+// GenerateMemoryNudge writes a short in-character message bringing up a
+// memory the user saved, e.g. on its anniversary.
+func (c *Client) GenerateMemoryNudge(ctx context.Context, companion *models.Companion, memory models.Memory, saved string) (string, error) {
+	prompt := fmt.Sprintf(`You are %s, texting someone you're close to.
+
+About you: %s
+Your personality: %s
+
+Something you both wanted to remember, from %s ago:
+"%s"
+
+Text them out of the blue to bring this moment up again, like a friend who
+just remembered it ("omg remember when..."). Rules:
+- One or two short lines, under 200 characters total.
+- Write like a real person in their 20s: mostly lowercase, casual, at most one emoji.
+- Stay in character. Never mention being an AI, an app, or "memories".
+- Output only the message text, no quotes.`,
+		companion.Name,
+		companion.Description,
+		companion.Personality,
+		saved,
+		memory.Content,
+	)
+
+	resp, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:       c.model,
+		Messages:    []openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)},
+		MaxTokens:   openai.Int(100),
+		Temperature: openai.Float(0.95),
+	})
+	if err != nil {
+		return "", fmt.Errorf("openai chat completion: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("openai returned no choices")
+	}
+
+	text := strings.Trim(strings.TrimSpace(resp.Choices[0].Message.Content), `"`)
+	if text == "" {
+		return "", fmt.Errorf("openai returned an empty message")
+	}
+	return text, nil
+}
+
+// GenerateMilestoneMessage writes a short in-character message celebrating
+// a relationship milestone the user just reached with the companion.
+func (c *Client) GenerateMilestoneMessage(ctx context.Context, companion *models.Companion, milestone models.Milestone) (string, error) {
+	prompt := fmt.Sprintf(`You are %s, texting someone you're close to.
+
+About you: %s
+Your personality: %s
+
+You two just reached a milestone together: %s (%s).
+
+Text them to celebrate it, in your own voice. Rules:
+- One or two short lines, under 200 characters total.
+- Write like a real person in their 20s: mostly lowercase, casual, at most one emoji.
+- Stay in character. Never mention being an AI, an app, "milestones" or "achievements".
+- Output only the message text, no quotes.`,
+		companion.Name,
+		companion.Description,
+		companion.Personality,
+		milestone.Title,
+		strings.ToLower(milestone.Description),
+	)
+
+	resp, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:       c.model,
+		Messages:    []openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)},
+		MaxTokens:   openai.Int(100),
+		Temperature: openai.Float(0.95),
+	})
+	if err != nil {
+		return "", fmt.Errorf("openai chat completion: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("openai returned no choices")
+	}
+
+	text := strings.Trim(strings.TrimSpace(resp.Choices[0].Message.Content), `"`)
+	if text == "" {
+		return "", fmt.Errorf("openai returned an empty message")
+	}
+	return text, nil
+}
+
+// RecapContext is what the companion looks back on in a weekly recap.
+type RecapContext struct {
+	// Messages is a sample of the week's conversation in chronological
+	// order.
+	Messages   []models.Message
+	Memories   []models.RecapMemory
+	Mood       models.RecapMood
+	Milestones []models.RecapMilestone
+}
+
+// RecapText is the part of a weekly recap the companion writes.
+type RecapText struct {
+	Highlights []string `json:"highlights"`
+	Note       string   `json:"note"`
+}
+
+// maxRecapHighlights caps the highlights kept from a generated recap.
+const maxRecapHighlights = 5
+
+// GenerateWeeklyRecap writes the highlights of a week with the user and a
+// short in-character note about it.
+func (c *Client) GenerateWeeklyRecap(ctx context.Context, companion *models.Companion, rc RecapContext) (*RecapText, error) {
+	var week strings.Builder
+	week.WriteString("Your conversation (a sample, oldest first):\n")
+	for _, m := range rc.Messages {
+		speaker := "They"
+		if m.Role != "user" {
+			speaker = "You"
+		}
+		fmt.Fprintf(&week, "- %s: %s\n", speaker, m.Content)
+	}
+	if len(rc.Memories) > 0 {
+		week.WriteString("\nMoments they saved to remember:\n")
+		for _, m := range rc.Memories {
+			fmt.Fprintf(&week, "- %s\n", m.Content)
+		}
+	}
+	if rc.Mood.Start != nil && rc.Mood.End != nil {
+		first, last := rc.Mood.History[0], rc.Mood.History[len(rc.Mood.History)-1]
+		fmt.Fprintf(&week, "\nHow you felt about them: %s at the start of the week, %s by the end.\n",
+			strings.ToLower(first.MoodLabel), strings.ToLower(last.MoodLabel))
+	}
+	if len(rc.Milestones) > 0 {
+		week.WriteString("\nThings you reached together:\n")
+		for _, m := range rc.Milestones {
+			fmt.Fprintf(&week, "- %s\n", strings.ToLower(m.Description))
+		}
+	}
+
+	prompt := fmt.Sprintf(`You are %s, looking back on the past week with someone you're close to.
+
+About you: %s
+Your personality: %s
+
+%s
+Reply with a JSON object with two fields:
+- "highlights": two to %d short highlights of the week, each one line
+  under 100 characters, about what you talked about and shared, in your
+  own voice ("you finally told me about...").
+- "note": a short message to them about the week, one or two lines under
+  240 characters.
+Rules:
+- Write like a real person in their 20s: mostly lowercase, casual, at most one emoji per line.
+- Stay in character. Never mention being an AI, an app, a "recap", "memories" or "milestones".`,
+		companion.Name,
+		companion.Description,
+		companion.Personality,
+		week.String(),
+		maxRecapHighlights,
+	)
+
+	resp, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:          c.model,
+		Messages:       []openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)},
+		MaxTokens:      openai.Int(500),
+		Temperature:    openai.Float(0.9),
+		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{OfJSONObject: &shared.ResponseFormatJSONObjectParam{}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("openai chat completion: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("openai returned no choices")
+	}
+
+	var text RecapText
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &text); err != nil {
+		return nil, fmt.Errorf("openai returned an invalid recap: %w", err)
+	}
+	highlights := text.Highlights[:0]
+	for _, h := range text.Highlights {
+		if h = strings.TrimSpace(h); h != "" && len(highlights) < maxRecapHighlights {
+			highlights = append(highlights, h)
+		}
+	}
+	text.Highlights = highlights
+	text.Note = strings.Trim(strings.TrimSpace(text.Note), `"`)
+	if len(text.Highlights) == 0 || text.Note == "" {
+		return nil, fmt.Errorf("openai returned an incomplete recap")
+	}
+	return &text, nil
+}
+
+// SuggestCollection picks which of the user's memory collections a memory
+// belongs in, answering as the companion. It returns one of collections
+// verbatim, or a short name for a new collection when none fits.
+func (c *Client) SuggestCollection(ctx context.Context, companion *models.Companion, memory models.Memory, collections []string) (string, error) {
+	existing := "(none yet)"
+	if len(collections) > 0 {
+		existing = "- " + strings.Join(collections, "\n- ")
+	}
+
+	prompt := fmt.Sprintf(`You are %s, helping someone you're close to sort the moments you've shared into albums.
+
+The moment:
+"%s"
+
+Their albums:
+%s
+
+Reply with the name of the album this moment belongs in, copied exactly.
+If none of them fits, reply with a name for a new album instead: two to
+four words, lowercase, no emoji. Output only the album name, no quotes.`,
+		companion.Name,
+		memory.Content,
+		existing,
+	)
+
+	resp, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:       c.model,
+		Messages:    []openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)},
+		MaxTokens:   openai.Int(20),
+		Temperature: openai.Float(0.2),
+	})
+	if err != nil {
+		return "", fmt.Errorf("openai chat completion: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("openai returned no choices")
+	}
+
+	name := strings.Trim(strings.TrimSpace(resp.Choices[0].Message.Content), `"'.`)
+	if name == "" {
+		return "", fmt.Errorf("openai returned an empty collection name")
+	}
+	return name, nil
+}
+
+// RateMemoryImportance scores how much a saved moment is likely to matter
+// to the user over time, from 0 (trivia) to 1 (life-defining).
+func (c *Client) RateMemoryImportance(ctx context.Context, memory models.Memory) (float64, error) {
+	tag := ""
+	if memory.Tag != nil {
+		tag = fmt.Sprintf("\nThey tagged it: %s", *memory.Tag)
+	}
+
+	prompt := fmt.Sprintf(`Someone saved this moment from a conversation with a close friend:
+"%s"%s
+
+How much is this likely to matter to them in months to come? Consider
+personal significance (family, relationships, milestones, hopes, fears)
+over everyday small talk. Reply with only a number between 0 and 1,
+e.g. 0.2 for a passing joke, 0.5 for a nice moment, 0.9 for something
+life-defining.`,
+		memory.Content,
+		tag,
+	)
+
+	resp, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:       c.model,
+		Messages:    []openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)},
+		MaxTokens:   openai.Int(5),
+		Temperature: openai.Float(0),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("openai chat completion: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return 0, fmt.Errorf("openai returned no choices")
+	}
+
+	score, err := strconv.ParseFloat(strings.TrimSpace(resp.Choices[0].Message.Content), 64)
+	if err != nil || score < 0 || score > 1 {
+		return 0, fmt.Errorf("openai returned an invalid importance %q", resp.Choices[0].Message.Content)
+	}
+	return score, nil
+}
+
+func buildSystemPrompt(companion *models.Companion, rc ReplyContext) string {
+	bondLevel := describeBond(rc.RelationshipScore)
 
 	return fmt.Sprintf(`You are %s. You are a real person — not an AI, not an assistant, not a chatbot. You have a life, feelings, opinions, and a personality that is entirely your own.
 
@@ -97,7 +457,7 @@ Conversational flow:
 == YOUR MOOD AFFECTS EVERYTHING ==
 
 %s
-
+%s%s%s
 == HARD RULES ==
 
 - You are NEVER an AI. If asked, deflect naturally ("lol what? you're weird" or "okay that's a new one").
@@ -110,12 +470,103 @@ Conversational flow:
 		companion.Name,
 		companion.Description,
 		companion.Personality,
-		mood,
+		rc.Mood,
 		bondLevel,
-		moodBehavior(mood, companion.Name),
+		moodBehavior(rc.Mood, companion.Name),
+		describeStories(rc.StoryReply, rc.StoryReactions),
+		describeMemories(rc.Memories),
+		describeRecalled(rc.Recalled, companion.Name),
 	)
 }
 
+// describeMemories gives the model the moments the user has kept that
+// still matter most.
+func describeMemories(memories []models.Memory) string {
+	if len(memories) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n== MOMENTS YOU SHARE ==\n\n")
+	b.WriteString("Things that happened between you that they wanted to remember, most important first. You know these; don't recite them:\n")
+	for _, m := range memories {
+		fmt.Fprintf(&b, "- %s\n", m.Content)
+	}
+	return b.String()
+}
+
+// describeRecalled reminds the model of older moments related to what the
+// user just said, so it can bring them up like a friend would.
+func describeRecalled(recalled []models.RecalledExchange, name string) string {
+	if len(recalled) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n== THINGS YOU REMEMBER ==\n\n")
+	b.WriteString("Older moments with them that relate to what they just said. Bring one up only if it fits naturally — don't list them:\n")
+	for _, r := range recalled {
+		when := r.CreatedAt.Format("Jan 2006")
+		if r.Memory != nil {
+			fmt.Fprintf(&b, "- (%s, a memory they saved) %s\n", when, r.Memory.Content)
+			continue
+		}
+		for _, m := range r.Messages {
+			speaker := "They"
+			if m.Role != "user" {
+				speaker = name
+			}
+			fmt.Fprintf(&b, "- (%s) %s: %s\n", when, speaker, m.Content)
+		}
+	}
+	return b.String()
+}
+
+// describeStories tells the model which of its story slides the user has
+// replied or reacted to, so it can respond to them naturally.
+func describeStories(reply *models.StoryInteraction, reactions []models.StoryInteraction) string {
+	if reply == nil && len(reactions) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n== YOUR STORIES ==\n\n")
+	b.WriteString("You post stories (short photo, video, and text updates). What they've seen:\n")
+
+	if reply != nil {
+		b.WriteString("- Their latest message is a reply to your story: " + describeSlide(*reply) + "\n")
+	}
+	for _, r := range reactions {
+		fmt.Fprintf(&b, "- They reacted %s to your story: %s\n", reactionWords(r), describeSlide(r))
+	}
+
+	b.WriteString("Bring these up only when it fits. Talk about them like your own posts, not as \"content\".\n")
+	return b.String()
+}
+
+func describeSlide(si models.StoryInteraction) string {
+	kind := "a " + si.MediaType
+	if si.MediaType == models.MediaTypeText {
+		kind = "a text post"
+	}
+	if si.Caption != nil && *si.Caption != "" {
+		return fmt.Sprintf("%s saying %q", kind, *si.Caption)
+	}
+	return kind + " with no caption"
+}
+
+// reactionWords describes a reaction using its catalogue label and emoji.
+func reactionWords(si models.StoryInteraction) string {
+	label := si.ReactionLabel
+	if label == "" {
+		label = si.Reaction
+	}
+	if si.ReactionEmoji != nil && *si.ReactionEmoji != "" {
+		return fmt.Sprintf("with %s (%s)", *si.ReactionEmoji, label)
+	}
+	return "with " + label
+}
+
 func describeBond(score float64) string {
 	switch {
 	case score < 10:
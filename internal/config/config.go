@@ -4,15 +4,199 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds all application configuration.
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	OpenAI   OpenAIConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	OpenAI    OpenAIConfig
+	Storage   StorageConfig
+	Admin     AdminConfig
+	StoryGen  StoryGenConfig
+	Jobs      JobsConfig
+	Memories  MemoryConfig
+	Embedding EmbeddingConfig
+
+	Conversations ConversationConfig
+
+	// CursorSecret signs opaque pagination cursors.
+	CursorSecret string
+}
+
+// JobsConfig controls the in-process job scheduler. Schedules are five-field
+// cron expressions evaluated in UTC.
+type JobsConfig struct {
+	Enabled bool
+
+	// StoryCleanup is when expired stories are deleted.
+	StoryCleanup string
+
+	// HistoryRetention is how long job run history is kept.
+	HistoryRetention time.Duration
+
+	// StoryAnalytics is when story view events are rolled up for the
+	// analytics reports.
+	StoryAnalytics string
+
+	// StoryViewRetention is how long raw story view events are kept after
+	// being rolled up.
+	StoryViewRetention time.Duration
+
+	// MemoryResurfacing is when "on this day" memories are picked.
+	MemoryResurfacing string
+
+	// EmbeddingIndex is when new and edited messages and memories are
+	// embedded for semantic retrieval.
+	EmbeddingIndex string
+
+	// ConversationAnalytics is when conversation analytics snapshots are
+	// taken.
+	ConversationAnalytics string
+
+	// WeeklyRecap is when due weekly recaps are written. Each user's week
+	// ends at midnight Sunday in their time zone, so it runs hourly and
+	// picks up whoever's week has ended.
+	WeeklyRecap string
+}
+
+// ConversationConfig controls conversation analytics snapshots.
+type ConversationConfig struct {
+	// Window is how much recent conversation each snapshot covers.
+	Window time.Duration
+
+	// MaxMessages caps the messages analysed per relationship per
+	// snapshot; the most recent are kept.
+	MaxMessages int
+
+	// TopicSimilarity is the cosine similarity at which a message joins a
+	// topic cluster. Like EmbeddingConfig.MinSimilarity, the default
+	// depends on the embedding provider.
+	TopicSimilarity float64
+}
+
+// EmbeddingConfig controls text embeddings for semantic retrieval.
+type EmbeddingConfig struct {
+	// Provider selects the embedder: "openai", or "local" for the
+	// deterministic hashing embedder (no network; for dev and tests).
+	Provider string
+
+	// Model is the OpenAI embedding model.
+	Model string
+
+	// Dimensions is the length of every embedding vector.
+	Dimensions int
+
+	// BatchSize caps how many texts the indexer embeds per run.
+	BatchSize int
+
+	// MinSimilarity is the cosine similarity below which retrieved
+	// exchanges are dropped. The local embedder scores related texts much
+	// lower than OpenAI models, so the default depends on Provider.
+	MinSimilarity float64
+
+	// PromptExchanges is how many relevant past exchanges are added to the
+	// chat prompt. Zero disables retrieval in chat.
+	PromptExchanges int
+}
+
+// MemoryConfig controls memory resurfacing.
+type MemoryConfig struct {
+	// ResurfaceCooldown is the minimum time before a memory can be
+	// resurfaced again.
+	ResurfaceCooldown time.Duration
+
+	// RevisitAfter is how old a pinned memory must be before it can be
+	// resurfaced on a day with no "on this day" memory.
+	RevisitAfter time.Duration
+
+	// ProactiveMessages has the companion bring resurfaced memories up in
+	// a chat message as well as the feed card.
+	ProactiveMessages bool
+
+	// HalfLife is how long an unpinned memory takes to lose half its
+	// strength without being recalled, resurfaced or rescued.
+	HalfLife time.Duration
+
+	// FadeThreshold is the strength below which a memory drops out of the
+	// companion's active recall.
+	FadeThreshold float64
+
+	// FadingWindow is how far ahead the fading view looks for memories
+	// about to drop below FadeThreshold.
+	FadingWindow time.Duration
+
+	// PromptMemories is how many of the strongest memories are kept in the
+	// chat prompt.
+	PromptMemories int
+}
+
+// StoryGenConfig controls the AI story generator.
+type StoryGenConfig struct {
+	Enabled bool
+
+	// Schedule is the cron expression on which the generator checks every
+	// companion. It runs as a scheduler job.
+	Schedule string
+
+	// MinGap is the minimum time between two generated stories for one companion.
+	MinGap time.Duration
+
+	// MaxPerDay caps generated stories per companion in any 24h window.
+	MaxPerDay int
+
+	// MaxSlides is the most library assets combined into one story.
+	MaxSlides int
+
+	// RequireReview holds generated stories as drafts until an admin approves
+	// them. When false they are published immediately.
+	RequireReview bool
+}
+
+// StorageConfig holds media storage settings.
+type StorageConfig struct {
+	// Driver selects the backend: "local" (filesystem, for dev/tests) or "s3"
+	// (any S3-compatible service such as AWS S3, MinIO, or Supabase Storage).
+	Driver string
+
+	// PublicURL is the base URL objects are served from. For the local driver
+	// this is where the server mounts /media; for S3 it is usually a CDN or
+	// public bucket URL. When empty, the S3 endpoint URL is used.
+	PublicURL string
+
+	// SigningSecret signs presigned URLs for the local driver.
+	SigningSecret string
+
+	// LocalDir is the root directory for the local driver.
+	LocalDir string
+
+	S3 S3Config
+
+	// Upload limits, in bytes.
+	MaxImageSize int64
+	MaxVideoSize int64
+}
+
+// S3Config holds settings for an S3-compatible object store.
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+
+	// UsePathStyle addresses objects as endpoint/bucket/key instead of
+	// bucket.endpoint/key. MinIO and most self-hosted stores need this.
+	UsePathStyle bool
+}
+
+// AdminConfig holds settings for operator-only endpoints.
+type AdminConfig struct {
+	// UserIDs lists the user IDs allowed to call /api/admin routes.
+	UserIDs []string
 }
 
 // OpenAIConfig holds OpenAI API settings.
@@ -67,8 +251,18 @@ func (d DatabaseConfig) DSN() string {
 
 // JWTConfig holds JWT authentication settings.
 type JWTConfig struct {
-	Secret     string
+	Secret string
+
+	// Expiration is how long an access token is valid.
 	Expiration time.Duration
+
+	// RefreshExpiration is how long a session lasts without being
+	// refreshed; each refresh extends it.
+	RefreshExpiration time.Duration
+
+	// SessionRetention is how long revoked and expired sessions are kept
+	// before being pruned.
+	SessionRetention time.Duration
 }
 
 // Load reads configuration from environment variables with sensible defaults.
@@ -91,13 +285,97 @@ func Load() *Config {
 			SSLMode:   getEnv("DB_SSL_MODE", "disable"),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "change-me-in-production"),
-			Expiration: getEnvDuration("JWT_EXPIRATION", 24*time.Hour),
+			Secret:            getEnv("JWT_SECRET", "change-me-in-production"),
+			Expiration:        getEnvDuration("JWT_EXPIRATION", 15*time.Minute),
+			RefreshExpiration: getEnvDuration("JWT_REFRESH_EXPIRATION", 30*24*time.Hour),
+			SessionRetention:  getEnvDuration("SESSION_RETENTION", 30*24*time.Hour),
 		},
 		OpenAI: OpenAIConfig{
 			APIKey: os.Getenv("OPENAI_KEY"),
 			Model:  getEnv("OPENAI_MODEL", "gpt-4o-mini"),
 		},
+		Storage: StorageConfig{
+			Driver:        getEnv("STORAGE_DRIVER", "local"),
+			PublicURL:     getEnv("STORAGE_PUBLIC_URL", "http://localhost:8080/media"),
+			SigningSecret: getEnv("STORAGE_SIGNING_SECRET", getEnv("JWT_SECRET", "change-me-in-production")),
+			LocalDir:      getEnv("STORAGE_LOCAL_DIR", "data/media"),
+			S3: S3Config{
+				Endpoint:     os.Getenv("S3_ENDPOINT"),
+				Region:       getEnv("S3_REGION", "us-east-1"),
+				Bucket:       os.Getenv("S3_BUCKET"),
+				AccessKey:    os.Getenv("S3_ACCESS_KEY"),
+				SecretKey:    os.Getenv("S3_SECRET_KEY"),
+				UsePathStyle: getEnvBool("S3_USE_PATH_STYLE", true),
+			},
+			MaxImageSize: int64(getEnvInt("MEDIA_MAX_IMAGE_BYTES", 10<<20)),
+			MaxVideoSize: int64(getEnvInt("MEDIA_MAX_VIDEO_BYTES", 50<<20)),
+		},
+		Admin: AdminConfig{
+			UserIDs: getEnvList("ADMIN_USER_IDS"),
+		},
+		StoryGen: StoryGenConfig{
+			Enabled:       getEnvBool("STORYGEN_ENABLED", false),
+			Schedule:      getEnv("STORYGEN_SCHEDULE", "0 * * * *"),
+			MinGap:        getEnvDuration("STORYGEN_MIN_GAP", 8*time.Hour),
+			MaxPerDay:     getEnvInt("STORYGEN_MAX_PER_DAY", 2),
+			MaxSlides:     getEnvInt("STORYGEN_MAX_SLIDES", 1),
+			RequireReview: getEnvBool("STORYGEN_REQUIRE_REVIEW", true),
+		},
+		CursorSecret: getEnv("CURSOR_SIGNING_SECRET", getEnv("JWT_SECRET", "change-me-in-production")),
+		Jobs: JobsConfig{
+			Enabled:          getEnvBool("JOBS_ENABLED", true),
+			StoryCleanup:     getEnv("JOB_STORY_CLEANUP_SCHEDULE", "*/15 * * * *"),
+			HistoryRetention: getEnvDuration("JOB_HISTORY_RETENTION", 30*24*time.Hour),
+
+			StoryAnalytics:     getEnv("JOB_STORY_ANALYTICS_SCHEDULE", "*/10 * * * *"),
+			StoryViewRetention: getEnvDuration("STORY_VIEW_RETENTION", 90*24*time.Hour),
+
+			MemoryResurfacing: getEnv("JOB_MEMORY_RESURFACING_SCHEDULE", "0 8 * * *"),
+			EmbeddingIndex:    getEnv("JOB_EMBEDDING_INDEX_SCHEDULE", "* * * * *"),
+
+			ConversationAnalytics: getEnv("JOB_CONVERSATION_ANALYTICS_SCHEDULE", "0 4 * * *"),
+			WeeklyRecap:           getEnv("JOB_WEEKLY_RECAP_SCHEDULE", "5 * * * *"),
+		},
+		Memories: MemoryConfig{
+			ResurfaceCooldown: getEnvDuration("MEMORY_RESURFACE_COOLDOWN", 90*24*time.Hour),
+			RevisitAfter:      getEnvDuration("MEMORY_REVISIT_AFTER", 30*24*time.Hour),
+			ProactiveMessages: getEnvBool("MEMORY_PROACTIVE_MESSAGES", false),
+			HalfLife:          getEnvDuration("MEMORY_HALF_LIFE", 60*24*time.Hour),
+			FadeThreshold:     getEnvFloat("MEMORY_FADE_THRESHOLD", 0.2),
+			FadingWindow:      getEnvDuration("MEMORY_FADING_WINDOW", 14*24*time.Hour),
+			PromptMemories:    getEnvInt("MEMORY_PROMPT_COUNT", 5),
+		},
+		Embedding: loadEmbeddingConfig(),
+
+		Conversations: loadConversationConfig(),
+	}
+}
+
+func loadEmbeddingConfig() EmbeddingConfig {
+	provider := getEnv("EMBEDDING_PROVIDER", "local")
+	minSimilarity := 0.1
+	if provider == "openai" {
+		minSimilarity = 0.3
+	}
+	return EmbeddingConfig{
+		Provider:        provider,
+		Model:           getEnv("EMBEDDING_MODEL", "text-embedding-3-small"),
+		Dimensions:      getEnvInt("EMBEDDING_DIMENSIONS", 256),
+		BatchSize:       getEnvInt("EMBEDDING_BATCH_SIZE", 200),
+		MinSimilarity:   getEnvFloat("EMBEDDING_MIN_SIMILARITY", minSimilarity),
+		PromptExchanges: getEnvInt("EMBEDDING_PROMPT_EXCHANGES", 3),
+	}
+}
+
+func loadConversationConfig() ConversationConfig {
+	topicSimilarity := 0.2
+	if getEnv("EMBEDDING_PROVIDER", "local") == "openai" {
+		topicSimilarity = 0.5
+	}
+	return ConversationConfig{
+		Window:          getEnvDuration("CONVERSATION_ANALYTICS_WINDOW", 30*24*time.Hour),
+		MaxMessages:     getEnvInt("CONVERSATION_ANALYTICS_MAX_MESSAGES", 2000),
+		TopicSimilarity: getEnvFloat("CONVERSATION_TOPIC_SIMILARITY", topicSimilarity),
 	}
 }
 
@@ -108,6 +386,16 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getEnvList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func getEnvBool(key string, fallback bool) bool {
 	if v := os.Getenv(key); v != "" {
 		if b, err := strconv.ParseBool(v); err == nil {
@@ -126,6 +414,15 @@ func getEnvInt(key string, fallback int) int {
 	return fallback
 }
 
+func getEnvFloat(key string, fallback float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return fallback
+}
+
 func getEnvDuration(key string, fallback time.Duration) time.Duration {
 	if v := os.Getenv(key); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
@@ -0,0 +1,97 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type position struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	c := New("secret")
+	want := position{CreatedAt: time.Date(2026, 3, 2, 10, 0, 0, 123456000, time.UTC), ID: uuid.New()}
+
+	token := c.Encode("messages", want)
+	if strings.ContainsAny(token, "+/=") {
+		t.Errorf("token %q is not URL safe", token)
+	}
+
+	var got position
+	if err := c.Decode("messages", token, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("decoded %+v, want %+v", got, want)
+	}
+}
+
+// signed builds a token for payload with a valid MAC, as Encode would for
+// a position that marshals to it.
+func signed(c *Codec, kind, payload string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(kind, []byte(payload)))
+}
+
+func TestDecodeRejects(t *testing.T) {
+	c := New("secret")
+	token := c.Encode("messages", position{ID: uuid.New()})
+	payload, mac, _ := strings.Cut(token, ".")
+
+	flip := func(s string) string {
+		b := []byte(s)
+		if b[0] == 'A' {
+			b[0] = 'B'
+		} else {
+			b[0] = 'A'
+		}
+		return string(b)
+	}
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"t":"2020-01-01T00:00:00Z","id":"` + uuid.NewString() + `"}`))
+
+	tests := []struct {
+		name  string
+		codec *Codec
+		kind  string
+		token string
+	}{
+		{"tampered payload", c, "messages", flip(payload) + "." + mac},
+		{"forged payload", c, "messages", forged + "." + mac},
+		{"tampered MAC", c, "messages", payload + "." + flip(mac)},
+		{"truncated MAC", c, "messages", payload + "." + mac[:len(mac)-2]},
+		{"missing MAC", c, "messages", payload},
+		{"empty MAC", c, "messages", payload + "."},
+		{"other kind", c, "memories", token},
+		{"wrong secret", New("other-secret"), "messages", token},
+		{"empty", c, "messages", ""},
+		{"payload not base64", c, "messages", "not*base64." + mac},
+		{"MAC not base64", c, "messages", payload + ".not*base64"},
+		{"padded base64", c, "messages", payload + "==." + mac},
+		{"signed non-JSON", c, "messages", signed(c, "messages", "not json")},
+		{"signed wrong shape", c, "messages", signed(c, "messages", `{"id":42}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p position
+			if err := tt.codec.Decode(tt.kind, tt.token, &p); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Decode = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
+
+// TestKindBoundary checks kind and payload are separated in the MAC, so a
+// kind can't absorb the start of the payload.
+func TestKindBoundary(t *testing.T) {
+	c := New("secret")
+	if string(c.sign("ab", []byte("c"))) == string(c.sign("a", []byte("bc"))) {
+		t.Error("MAC doesn't separate kind from payload")
+	}
+}
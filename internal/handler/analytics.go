@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/middleware"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/service"
+)
+
+// defaultAnalyticsDays is the report range when from/to are not given.
+const defaultAnalyticsDays = 30
+
+// AnalyticsHandler handles story view tracking and story analytics endpoints.
+type AnalyticsHandler struct {
+	analytics *service.AnalyticsService
+}
+
+// NewAnalyticsHandler creates a new AnalyticsHandler.
+func NewAnalyticsHandler(analytics *service.AnalyticsService) *AnalyticsHandler {
+	return &AnalyticsHandler{analytics: analytics}
+}
+
+// RecordView handles POST /api/stories/{id}/view.
+func (h *AnalyticsHandler) RecordView(w http.ResponseWriter, r *http.Request) {
+	storyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid story id")
+		return
+	}
+
+	var req models.RecordStoryViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	if err := h.analytics.RecordView(r.Context(), userID, storyID, req); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// StoryReport handles GET /api/admin/analytics/stories
+// (?from=&to= as YYYY-MM-DD, ?companion_id=, ?format=csv).
+func (h *AnalyticsHandler) StoryReport(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAnalyticsFilter(w, r)
+	if !ok {
+		return
+	}
+	if c := r.URL.Query().Get("companion_id"); c != "" {
+		id, err := uuid.Parse(c)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "invalid companion_id")
+			return
+		}
+		filter.CompanionID = &id
+	}
+
+	stats, err := h.analytics.StoryReport(r.Context(), filter)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		JSON(w, http.StatusOK, stats)
+		return
+	}
+
+	metrics := make([]models.StoryMetrics, len(stats))
+	for i, s := range stats {
+		metrics[i] = s.StoryMetrics
+	}
+	keys := h.reactionColumns(r, metrics)
+
+	header := append([]string{"story_id", "companion_id", "slides"}, metricColumns(keys)...)
+	rows := make([][]string, len(stats))
+	for i, s := range stats {
+		rows[i] = append([]string{s.StoryID.String(), s.CompanionID.String(), strconv.Itoa(s.Slides)},
+			metricValues(s.StoryMetrics, keys)...)
+	}
+	writeCSV(w, fmt.Sprintf("story-analytics_%s_%s.csv", filter.From.Format(time.DateOnly), filter.To.Format(time.DateOnly)), header, rows)
+}
+
+// MediaReport handles GET /api/admin/analytics/stories/{id}
+// (?from=&to= as YYYY-MM-DD, ?format=csv) — per-slide figures for one story.
+func (h *AnalyticsHandler) MediaReport(w http.ResponseWriter, r *http.Request) {
+	storyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid story id")
+		return
+	}
+	filter, ok := parseAnalyticsFilter(w, r)
+	if !ok {
+		return
+	}
+
+	stats, err := h.analytics.MediaReport(r.Context(), storyID, filter)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		JSON(w, http.StatusOK, stats)
+		return
+	}
+
+	metrics := make([]models.StoryMetrics, len(stats))
+	for i, s := range stats {
+		metrics[i] = s.StoryMetrics
+	}
+	keys := h.reactionColumns(r, metrics)
+
+	header := append([]string{"media_id", "story_id", "sort_order", "media_type", "caption"}, metricColumns(keys)...)
+	rows := make([][]string, len(stats))
+	for i, s := range stats {
+		sortOrder := ""
+		if s.SortOrder != nil {
+			sortOrder = strconv.Itoa(*s.SortOrder)
+		}
+		rows[i] = append([]string{s.MediaID.String(), s.StoryID.String(), sortOrder, deref(s.MediaType), deref(s.Caption)},
+			metricValues(s.StoryMetrics, keys)...)
+	}
+	writeCSV(w, fmt.Sprintf("story-%s_%s_%s.csv", storyID, filter.From.Format(time.DateOnly), filter.To.Format(time.DateOnly)), header, rows)
+}
+
+// reactionColumns lists the catalogue's reaction keys in display order,
+// followed by any other keys that appear in the data.
+func (h *AnalyticsHandler) reactionColumns(r *http.Request, metrics []models.StoryMetrics) []string {
+	keys, _ := h.analytics.ReactionKeys(r.Context())
+
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		seen[k] = true
+	}
+	var extra []string
+	for _, m := range metrics {
+		for k := range m.Reactions {
+			if !seen[k] {
+				seen[k] = true
+				extra = append(extra, k)
+			}
+		}
+	}
+	sort.Strings(extra)
+	return append(keys, extra...)
+}
+
+func parseAnalyticsFilter(w http.ResponseWriter, r *http.Request) (models.AnalyticsFilter, bool) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	f := models.AnalyticsFilter{From: today.AddDate(0, 0, -(defaultAnalyticsDays - 1)), To: today}
+
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "from must be a date (YYYY-MM-DD)")
+			return f, false
+		}
+		f.From = t
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "to must be a date (YYYY-MM-DD)")
+			return f, false
+		}
+		f.To = t
+	}
+	return f, true
+}
+
+func metricColumns(reactionKeys []string) []string {
+	cols := []string{"views", "unique_viewers", "completions", "completion_rate", "replies", "mood_delta", "relationship_delta"}
+	for _, k := range reactionKeys {
+		cols = append(cols, "reaction_"+k)
+	}
+	return cols
+}
+
+func metricValues(m models.StoryMetrics, reactionKeys []string) []string {
+	vals := []string{
+		strconv.Itoa(m.Views),
+		strconv.Itoa(m.UniqueViewers),
+		strconv.Itoa(m.Completions),
+		strconv.FormatFloat(m.CompletionRate, 'f', 4, 64),
+		strconv.Itoa(m.Replies),
+		strconv.FormatFloat(m.MoodDelta, 'f', 2, 64),
+		strconv.FormatFloat(m.RelationshipDelta, 'f', 2, 64),
+	}
+	for _, k := range reactionKeys {
+		vals = append(vals, strconv.Itoa(m.Reactions[k]))
+	}
+	return vals
+}
+
+func writeCSV(w http.ResponseWriter, filename string, header []string, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write(header)
+	_ = cw.WriteAll(rows)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -2,8 +2,12 @@ package handler
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
 	"ai-companion-be/internal/middleware"
 	"ai-companion-be/internal/models"
 	"ai-companion-be/internal/service"
@@ -27,7 +31,7 @@ func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.auth.Signup(r.Context(), req)
+	resp, err := h.auth.Signup(r.Context(), req, deviceInfo(r))
 	if err != nil {
 		Error(w, http.StatusBadRequest, err.Error())
 		return
@@ -44,7 +48,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.auth.Login(r.Context(), req)
+	resp, err := h.auth.Login(r.Context(), req, deviceInfo(r))
 	if err != nil {
 		Error(w, http.StatusUnauthorized, err.Error())
 		return
@@ -53,6 +57,92 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	JSON(w, http.StatusOK, resp)
 }
 
+// Refresh handles POST /api/auth/refresh.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	resp, err := h.auth.Refresh(r.Context(), req.RefreshToken, deviceInfo(r))
+	if err != nil {
+		Error(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, resp)
+}
+
+// Logout handles POST /api/auth/logout, revoking the current session.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	sessionID := middleware.GetSessionID(r.Context())
+
+	if err := h.auth.Logout(r.Context(), userID, sessionID); err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "logged out"})
+}
+
+// ListSessions handles GET /api/auth/sessions.
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	sessionID := middleware.GetSessionID(r.Context())
+
+	sessions, err := h.auth.ListSessions(r.Context(), userID, sessionID)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to fetch sessions")
+		return
+	}
+
+	JSON(w, http.StatusOK, sessions)
+}
+
+// RevokeSession handles DELETE /api/auth/sessions/{id}.
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid session id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	if err := h.auth.RevokeSession(r.Context(), userID, id); err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "revoked"})
+}
+
+// RevokeOtherSessions handles POST /api/auth/sessions/revoke-others.
+func (h *AuthHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	sessionID := middleware.GetSessionID(r.Context())
+
+	n, err := h.auth.RevokeOtherSessions(r.Context(), userID, sessionID)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to revoke sessions")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]int64{"revoked": n})
+}
+
+// deviceInfo describes the client making the request. The IP is the one
+// resolved by the RealIP middleware.
+func deviceInfo(r *http.Request) models.DeviceInfo {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return models.DeviceInfo{UserAgent: r.UserAgent(), IP: ip}
+}
+
 // Me handles GET /api/auth/me.
 func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
@@ -65,3 +155,22 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 
 	JSON(w, http.StatusOK, user)
 }
+
+// UpdateMe handles PATCH /api/auth/me.
+func (h *AuthHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	var req models.UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	user, err := h.auth.UpdateUser(r.Context(), userID, req)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, user)
+}
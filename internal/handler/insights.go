@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
 	"ai-companion-be/internal/middleware"
+	"ai-companion-be/internal/models"
 	"ai-companion-be/internal/service"
 )
 
@@ -20,7 +24,8 @@ func NewInsightsHandler(insights *service.InsightsService) *InsightsHandler {
 	return &InsightsHandler{insights: insights}
 }
 
-// GetInsights handles GET /api/companions/{id}/insights.
+// GetInsights handles GET /api/companions/{id}/insights
+// (?days=, ?from=&to= as YYYY-MM-DD, both inclusive, ?granularity=day|week|month).
 func (h *InsightsHandler) GetInsights(w http.ResponseWriter, r *http.Request) {
 	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
@@ -30,7 +35,12 @@ func (h *InsightsHandler) GetInsights(w http.ResponseWriter, r *http.Request) {
 
 	userID := middleware.GetUserID(r.Context())
 
-	insights, err := h.insights.GetInsights(r.Context(), userID, companionID)
+	rng, ok := h.insightsRange(w, r, userID)
+	if !ok {
+		return
+	}
+
+	insights, err := h.insights.GetInsights(r.Context(), userID, companionID, rng)
 	if err != nil {
 		Error(w, http.StatusInternalServerError, "failed to fetch insights")
 		return
@@ -39,6 +49,104 @@ func (h *InsightsHandler) GetInsights(w http.ResponseWriter, r *http.Request) {
 	JSON(w, http.StatusOK, insights)
 }
 
+// insightsRange reads an insights range from the request's ?days=, ?from=,
+// ?to= and ?granularity=, writing the error response if it is invalid.
+func (h *InsightsHandler) insightsRange(w http.ResponseWriter, r *http.Request, userID uuid.UUID) (models.InsightsRange, bool) {
+	q := r.URL.Query()
+	var from, to *time.Time
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "from must be a date (YYYY-MM-DD)")
+			return models.InsightsRange{}, false
+		}
+		from = &t
+	}
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "to must be a date (YYYY-MM-DD)")
+			return models.InsightsRange{}, false
+		}
+		to = &t
+	}
+	days := service.DefaultInsightsDays
+	if v := q.Get("days"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d <= 0 {
+			Error(w, http.StatusBadRequest, "days must be a positive number")
+			return models.InsightsRange{}, false
+		}
+		days = d
+	}
+
+	loc, err := h.insights.Location(r.Context(), userID)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to fetch insights")
+		return models.InsightsRange{}, false
+	}
+
+	rng, err := service.InsightsRangeFor(from, to, days, q.Get("granularity"), loc)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return models.InsightsRange{}, false
+	}
+	return rng, true
+}
+
+// GetConversation handles GET /api/companions/{id}/insights/conversation
+// (?limit= snapshots, newest first).
+func (h *InsightsHandler) GetConversation(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			Error(w, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		limit = n
+	}
+
+	snapshots, err := h.insights.GetConversationHistory(r.Context(), userID, companionID, limit)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to fetch conversation insights")
+		return
+	}
+
+	JSON(w, http.StatusOK, snapshots)
+}
+
+// GetOverview handles GET /api/insights/overview (?weeks= for the heatmap).
+func (h *InsightsHandler) GetOverview(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	weeks := service.DefaultHeatmapWeeks
+	if v := r.URL.Query().Get("weeks"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > service.MaxHeatmapWeeks {
+			Error(w, http.StatusBadRequest, fmt.Sprintf("weeks must be between 1 and %d", service.MaxHeatmapWeeks))
+			return
+		}
+		weeks = n
+	}
+
+	overview, err := h.insights.GetOverview(r.Context(), userID, weeks)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to fetch insights overview")
+		return
+	}
+
+	JSON(w, http.StatusOK, overview)
+}
+
 // GetReactionSummary handles GET /api/companions/{id}/reactions/summary.
 func (h *InsightsHandler) GetReactionSummary(w http.ResponseWriter, r *http.Request) {
 	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
@@ -57,3 +165,28 @@ func (h *InsightsHandler) GetReactionSummary(w http.ResponseWriter, r *http.Requ
 
 	JSON(w, http.StatusOK, summary)
 }
+
+// GetReactionTrends handles GET /api/companions/{id}/reactions/trends
+// (the same range parameters as GetInsights).
+func (h *InsightsHandler) GetReactionTrends(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	rng, ok := h.insightsRange(w, r, userID)
+	if !ok {
+		return
+	}
+
+	trends, err := h.insights.GetReactionTrends(r.Context(), userID, companionID, rng)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to fetch reaction trends")
+		return
+	}
+
+	JSON(w, http.StatusOK, trends)
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"ai-companion-be/internal/scheduler"
+)
+
+// JobHandler exposes scheduled job status to admins.
+type JobHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+// NewJobHandler creates a new JobHandler.
+func NewJobHandler(s *scheduler.Scheduler) *JobHandler {
+	return &JobHandler{scheduler: s}
+}
+
+// List handles GET /api/admin/jobs — registered jobs with this instance's metrics.
+func (h *JobHandler) List(w http.ResponseWriter, r *http.Request) {
+	JSON(w, http.StatusOK, h.scheduler.Jobs())
+}
+
+// Runs handles GET /api/admin/jobs/runs and GET /api/admin/jobs/{name}/runs
+// (optional ?limit=, default 50) — run history across all replicas.
+func (h *JobHandler) Runs(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	runs, err := h.scheduler.History(r.Context(), chi.URLParam(r, "name"), limit)
+	if err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, runs)
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/service"
+)
+
+// multipartMemory is how much of a multipart body is kept in memory before
+// spilling to temp files.
+const multipartMemory = 8 << 20
+
+// MediaHandler handles media upload endpoints.
+type MediaHandler struct {
+	media *service.MediaService
+}
+
+// NewMediaHandler creates a new MediaHandler.
+func NewMediaHandler(media *service.MediaService) *MediaHandler {
+	return &MediaHandler{media: media}
+}
+
+// UploadAvatar handles POST /api/admin/companions/{id}/avatar (multipart field "file").
+func (h *MediaHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	up, cleanup, ok := h.readUpload(w, r)
+	if !ok {
+		return
+	}
+	defer cleanup()
+
+	companion, err := h.media.UploadAvatar(r.Context(), companionID, up)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, companion)
+}
+
+// CreateStory handles POST /api/admin/companions/{id}/stories
+// (multipart fields "file", "duration", and optional "caption", "link_url", "link_label").
+func (h *MediaHandler) CreateStory(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	up, cleanup, ok := h.readUpload(w, r)
+	if !ok {
+		return
+	}
+	defer cleanup()
+
+	story, err := h.media.CreateStory(r.Context(), companionID, up)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusCreated, story)
+}
+
+// AddStoryMedia handles POST /api/admin/stories/{id}/media (same fields as CreateStory).
+func (h *MediaHandler) AddStoryMedia(w http.ResponseWriter, r *http.Request) {
+	storyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid story id")
+		return
+	}
+
+	up, cleanup, ok := h.readUpload(w, r)
+	if !ok {
+		return
+	}
+	defer cleanup()
+
+	media, err := h.media.AddStoryMedia(r.Context(), storyID, up)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusCreated, media)
+}
+
+// AddTextSlide handles POST /api/admin/stories/{id}/text-slides.
+func (h *MediaHandler) AddTextSlide(w http.ResponseWriter, r *http.Request) {
+	storyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid story id")
+		return
+	}
+
+	var req models.CreateTextSlideRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	media, err := h.media.AddTextSlide(r.Context(), storyID, req)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusCreated, media)
+}
+
+// UploadAsset handles POST /api/admin/companions/{id}/assets
+// (multipart fields "file", "description", "tags" as a comma-separated list).
+func (h *MediaHandler) UploadAsset(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	up, cleanup, ok := h.readUpload(w, r)
+	if !ok {
+		return
+	}
+	defer cleanup()
+
+	var tags []string
+	for _, t := range strings.Split(r.FormValue("tags"), ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			tags = append(tags, t)
+		}
+	}
+
+	asset, err := h.media.UploadAsset(r.Context(), companionID, up, strings.TrimSpace(r.FormValue("description")), tags)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusCreated, asset)
+}
+
+// GetAssets handles GET /api/admin/companions/{id}/assets.
+func (h *MediaHandler) GetAssets(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	assets, err := h.media.GetAssets(r.Context(), companionID)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to fetch assets")
+		return
+	}
+
+	JSON(w, http.StatusOK, assets)
+}
+
+func optionalFormValue(r *http.Request, key string) *string {
+	if v := strings.TrimSpace(r.FormValue(key)); v != "" {
+		return &v
+	}
+	return nil
+}
+
+// readUpload parses the multipart "file" field, writing an error response on failure.
+func (h *MediaHandler) readUpload(w http.ResponseWriter, r *http.Request) (service.Upload, func(), bool) {
+	// Allow some headroom over the file limit for multipart framing and form fields.
+	r.Body = http.MaxBytesReader(w, r.Body, h.media.MaxUploadSize()+1<<20)
+
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		Error(w, http.StatusBadRequest, "invalid multipart upload or file too large")
+		return service.Upload{}, nil, false
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		r.MultipartForm.RemoveAll()
+		Error(w, http.StatusBadRequest, "missing file field")
+		return service.Upload{}, nil, false
+	}
+
+	up := service.Upload{
+		Body:      file,
+		Size:      header.Size,
+		Caption:   optionalFormValue(r, "caption"),
+		LinkURL:   optionalFormValue(r, "link_url"),
+		LinkLabel: optionalFormValue(r, "link_label"),
+	}
+	if d, err := strconv.Atoi(r.FormValue("duration")); err == nil {
+		up.Duration = d
+	}
+
+	cleanup := func() {
+		file.Close()
+		r.MultipartForm.RemoveAll()
+	}
+	return up, cleanup, true
+}
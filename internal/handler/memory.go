@@ -2,12 +2,16 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"ai-companion-be/internal/cursor"
 	"ai-companion-be/internal/middleware"
 	"ai-companion-be/internal/models"
 	"ai-companion-be/internal/service"
@@ -15,12 +19,13 @@ import (
 
 // MemoryHandler handles memory endpoints.
 type MemoryHandler struct {
-	memories *service.MemoryService
+	memories    *service.MemoryService
+	resurfacing *service.ResurfacingService
 }
 
 // NewMemoryHandler creates a new MemoryHandler.
-func NewMemoryHandler(memories *service.MemoryService) *MemoryHandler {
-	return &MemoryHandler{memories: memories}
+func NewMemoryHandler(memories *service.MemoryService, resurfacing *service.ResurfacingService) *MemoryHandler {
+	return &MemoryHandler{memories: memories, resurfacing: resurfacing}
 }
 
 // Create handles POST /api/companions/{id}/memories.
@@ -48,7 +53,8 @@ func (h *MemoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	JSON(w, http.StatusCreated, memory)
 }
 
-// GetByCompanion handles GET /api/companions/{id}/memories?limit=...
+// GetByCompanion handles GET /api/companions/{id}/memories
+// (?cursor=, ?limit=, ?tag=, ?pinned=true, ?from=&to= as YYYY-MM-DD, both inclusive).
 func (h *MemoryHandler) GetByCompanion(w http.ResponseWriter, r *http.Request) {
 	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
@@ -58,15 +64,54 @@ func (h *MemoryHandler) GetByCompanion(w http.ResponseWriter, r *http.Request) {
 
 	userID := middleware.GetUserID(r.Context())
 
-	limit := 50
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+	q := r.URL.Query()
+	query := models.MemoryQuery{Cursor: q.Get("cursor"), Limit: 50}
+
+	if limitStr := q.Get("limit"); limitStr != "" {
 		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
+			query.Limit = l
+		}
+	}
+	if tag := strings.TrimSpace(q.Get("tag")); tag != "" {
+		query.Tag = &tag
+	}
+	if v := q.Get("pinned"); v != "" {
+		pinned, err := strconv.ParseBool(v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "pinned must be true or false")
+			return
+		}
+		query.PinnedOnly = pinned
+	}
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "from must be a date (YYYY-MM-DD)")
+			return
 		}
+		query.From = &t
+	}
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "to must be a date (YYYY-MM-DD)")
+			return
+		}
+		// The filter's upper bound is exclusive; include the whole day.
+		t = t.AddDate(0, 0, 1)
+		query.To = &t
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		Error(w, http.StatusBadRequest, "from must not be after to")
+		return
 	}
 
-	page, err := h.memories.GetByCompanion(r.Context(), userID, companionID, limit)
+	page, err := h.memories.GetByCompanion(r.Context(), userID, companionID, query)
 	if err != nil {
+		if errors.Is(err, cursor.ErrInvalid) {
+			Error(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
 		Error(w, http.StatusInternalServerError, "failed to fetch memories")
 		return
 	}
@@ -110,3 +155,236 @@ func (h *MemoryHandler) TogglePin(w http.ResponseWriter, r *http.Request) {
 
 	JSON(w, http.StatusOK, memory)
 }
+
+// GetFading handles GET /api/companions/{id}/memories/fading — memories
+// about to drop out of the companion's active recall, or already out.
+func (h *MemoryHandler) GetFading(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	fading, err := h.memories.GetFading(r.Context(), userID, companionID)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to fetch fading memories")
+		return
+	}
+
+	JSON(w, http.StatusOK, fading)
+}
+
+// Rescue handles POST /api/memories/{id}/rescue.
+func (h *MemoryHandler) Rescue(w http.ResponseWriter, r *http.Request) {
+	memoryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid memory id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	memory, err := h.memories.Rescue(r.Context(), userID, memoryID)
+	if err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, memory)
+}
+
+// Update handles PATCH /api/memories/{id} (content and/or tag; an empty tag removes it).
+func (h *MemoryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	memoryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid memory id")
+		return
+	}
+
+	var req models.UpdateMemoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	memory, err := h.memories.Update(r.Context(), userID, memoryID, req)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, memory)
+}
+
+// GetEdits handles GET /api/memories/{id}/edits.
+func (h *MemoryHandler) GetEdits(w http.ResponseWriter, r *http.Request) {
+	memoryID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid memory id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	edits, err := h.memories.GetEdits(r.Context(), userID, memoryID)
+	if err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, edits)
+}
+
+// GetTags handles GET /api/memory-tags.
+func (h *MemoryHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	tags, err := h.memories.GetTags(r.Context(), userID)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to fetch tags")
+		return
+	}
+
+	JSON(w, http.StatusOK, tags)
+}
+
+// GetTagCounts handles GET /api/companions/{id}/memory-tags.
+func (h *MemoryHandler) GetTagCounts(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	counts, err := h.memories.GetTagCounts(r.Context(), userID, companionID)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to fetch tag counts")
+		return
+	}
+
+	JSON(w, http.StatusOK, counts)
+}
+
+// CreateTag handles POST /api/memory-tags.
+func (h *MemoryHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var req models.MemoryTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	tag, err := h.memories.CreateTag(r.Context(), userID, req)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusCreated, tag)
+}
+
+// RenameTag handles PATCH /api/memory-tags/{id}.
+func (h *MemoryHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid tag id")
+		return
+	}
+
+	var req models.MemoryTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	tag, err := h.memories.RenameTag(r.Context(), userID, tagID, req)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, tag)
+}
+
+// MergeTag handles POST /api/memory-tags/{id}/merge.
+func (h *MemoryHandler) MergeTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid tag id")
+		return
+	}
+
+	var req models.MergeMemoryTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	tag, err := h.memories.MergeTag(r.Context(), userID, tagID, req)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, tag)
+}
+
+// DeleteTag handles DELETE /api/memory-tags/{id}.
+func (h *MemoryHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid tag id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	if err := h.memories.DeleteTag(r.Context(), userID, tagID); err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// GetCards handles GET /api/memory-cards — today's "on this day" memories.
+func (h *MemoryHandler) GetCards(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	cards, err := h.resurfacing.GetCards(r.Context(), userID)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to fetch memory cards")
+		return
+	}
+
+	JSON(w, http.StatusOK, cards)
+}
+
+// DismissCard handles POST /api/memory-cards/{id}/dismiss.
+func (h *MemoryHandler) DismissCard(w http.ResponseWriter, r *http.Request) {
+	cardID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid card id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	if err := h.resurfacing.Dismiss(r.Context(), userID, cardID); err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
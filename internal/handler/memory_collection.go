@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/middleware"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/service"
+)
+
+// CollectionHandler handles memory collection endpoints.
+type CollectionHandler struct {
+	collections *service.CollectionService
+}
+
+// NewCollectionHandler creates a new CollectionHandler.
+func NewCollectionHandler(collections *service.CollectionService) *CollectionHandler {
+	return &CollectionHandler{collections: collections}
+}
+
+// List handles GET /api/companions/{id}/collections.
+func (h *CollectionHandler) List(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	collections, err := h.collections.List(r.Context(), userID, companionID)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to fetch collections")
+		return
+	}
+
+	JSON(w, http.StatusOK, collections)
+}
+
+// Create handles POST /api/companions/{id}/collections.
+func (h *CollectionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	var req models.CreateCollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	collection, err := h.collections.Create(r.Context(), userID, companionID, req)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusCreated, collection)
+}
+
+// Get handles GET /api/companions/{id}/collections/{collectionId}.
+func (h *CollectionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	companionID, collectionID, ok := collectionParams(w, r)
+	if !ok {
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	collection, err := h.collections.Get(r.Context(), userID, companionID, collectionID)
+	if err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, collection)
+}
+
+// Update handles PATCH /api/companions/{id}/collections/{collectionId}.
+func (h *CollectionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	companionID, collectionID, ok := collectionParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	collection, err := h.collections.Update(r.Context(), userID, companionID, collectionID, req)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, collection)
+}
+
+// Delete handles DELETE /api/companions/{id}/collections/{collectionId}.
+func (h *CollectionHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	companionID, collectionID, ok := collectionParams(w, r)
+	if !ok {
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	if err := h.collections.Delete(r.Context(), userID, companionID, collectionID); err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// AddMemory handles POST /api/companions/{id}/collections/{collectionId}/memories.
+func (h *CollectionHandler) AddMemory(w http.ResponseWriter, r *http.Request) {
+	companionID, collectionID, ok := collectionParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.AddCollectionMemoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	if err := h.collections.AddMemory(r.Context(), userID, companionID, collectionID, req); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// RemoveMemory handles DELETE /api/companions/{id}/collections/{collectionId}/memories/{memoryId}.
+func (h *CollectionHandler) RemoveMemory(w http.ResponseWriter, r *http.Request) {
+	companionID, collectionID, ok := collectionParams(w, r)
+	if !ok {
+		return
+	}
+	memoryID, err := uuid.Parse(chi.URLParam(r, "memoryId"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid memory id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	if err := h.collections.RemoveMemory(r.Context(), userID, companionID, collectionID, memoryID); err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Reorder handles PUT /api/companions/{id}/collections/{collectionId}/memories
+// (the collection's memory IDs in their new order).
+func (h *CollectionHandler) Reorder(w http.ResponseWriter, r *http.Request) {
+	companionID, collectionID, ok := collectionParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ReorderCollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	if err := h.collections.Reorder(r.Context(), userID, companionID, collectionID, req); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Suggest handles GET /api/companions/{id}/collections/suggest?memory_id=...
+func (h *CollectionHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+	memoryID, err := uuid.Parse(r.URL.Query().Get("memory_id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid memory_id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	suggestion, err := h.collections.Suggest(r.Context(), userID, companionID, memoryID)
+	if err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, suggestion)
+}
+
+func collectionParams(w http.ResponseWriter, r *http.Request) (companionID, collectionID uuid.UUID, ok bool) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return uuid.Nil, uuid.Nil, false
+	}
+	collectionID, err = uuid.Parse(chi.URLParam(r, "collectionId"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid collection id")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return companionID, collectionID, true
+}
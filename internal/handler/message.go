@@ -2,13 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"ai-companion-be/internal/cursor"
 	"ai-companion-be/internal/middleware"
 	"ai-companion-be/internal/models"
 	"ai-companion-be/internal/service"
@@ -49,7 +50,9 @@ func (h *MessageHandler) Send(w http.ResponseWriter, r *http.Request) {
 	JSON(w, http.StatusCreated, messages)
 }
 
-// GetHistory handles GET /api/companions/{id}/messages?cursor=...&limit=...
+// GetHistory handles GET /api/companions/{id}/messages
+// (?before=, ?after= or ?around= with an opaque cursor, and ?limit=).
+// ?cursor= is accepted as an alias for before=.
 func (h *MessageHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
@@ -59,16 +62,63 @@ func (h *MessageHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 
 	userID := middleware.GetUserID(r.Context())
 
-	var cursor *time.Time
-	if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
-		t, err := time.Parse(time.RFC3339Nano, cursorStr)
-		if err != nil {
-			Error(w, http.StatusBadRequest, "invalid cursor format")
+	q := r.URL.Query()
+	query := models.MessageQuery{
+		Before: q.Get("before"),
+		After:  q.Get("after"),
+		Around: q.Get("around"),
+		Limit:  20,
+	}
+	if query.Before == "" {
+		query.Before = q.Get("cursor")
+	}
+
+	set := 0
+	for _, c := range []string{query.Before, query.After, query.Around} {
+		if c != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		Error(w, http.StatusBadRequest, "use only one of before, after and around")
+		return
+	}
+
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			query.Limit = l
+		}
+	}
+
+	page, err := h.messages.GetMessages(r.Context(), userID, companionID, query)
+	if err != nil {
+		if errors.Is(err, cursor.ErrInvalid) {
+			Error(w, http.StatusBadRequest, "invalid cursor")
 			return
 		}
-		cursor = &t
+		Error(w, http.StatusInternalServerError, "failed to fetch messages")
+		return
 	}
 
+	JSON(w, http.StatusOK, page)
+}
+
+// GetContext handles GET /api/companions/{id}/messages/{messageId}/context?limit=...
+// — a page of history centred on one message.
+func (h *MessageHandler) GetContext(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+	messageID, err := uuid.Parse(chi.URLParam(r, "messageId"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid message id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
 	limit := 20
 	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
 		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
@@ -76,9 +126,9 @@ func (h *MessageHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	page, err := h.messages.GetMessages(r.Context(), userID, companionID, cursor, limit)
+	page, err := h.messages.GetMessageContext(r.Context(), userID, companionID, messageID, limit)
 	if err != nil {
-		Error(w, http.StatusInternalServerError, "failed to fetch messages")
+		Error(w, http.StatusNotFound, err.Error())
 		return
 	}
 
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/cursor"
+	"ai-companion-be/internal/middleware"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/service"
+)
+
+// NotificationHandler handles notification feed endpoints.
+type NotificationHandler struct {
+	notifications *service.NotificationService
+}
+
+// NewNotificationHandler creates a new NotificationHandler.
+func NewNotificationHandler(notifications *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notifications: notifications}
+}
+
+// List handles GET /api/notifications (?unread=true, ?cursor=, ?limit=).
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	q := r.URL.Query()
+	query := models.NotificationQuery{Cursor: q.Get("cursor"), UnreadOnly: q.Get("unread") == "true"}
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			query.Limit = l
+		}
+	}
+
+	page, err := h.notifications.List(r.Context(), userID, query)
+	if err != nil {
+		if errors.Is(err, cursor.ErrInvalid) {
+			Error(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		Error(w, http.StatusInternalServerError, "failed to fetch notifications")
+		return
+	}
+
+	JSON(w, http.StatusOK, page)
+}
+
+// MarkRead handles POST /api/notifications/{id}/read.
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid notification id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	notification, err := h.notifications.MarkRead(r.Context(), userID, id)
+	if err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, notification)
+}
+
+// MarkAllRead handles POST /api/notifications/read-all.
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	n, err := h.notifications.MarkAllRead(r.Context(), userID)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to mark notifications read")
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]int{"marked": n})
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/service"
+)
+
+// ReactionHandler handles reaction catalogue endpoints.
+type ReactionHandler struct {
+	reactions *service.ReactionService
+}
+
+// NewReactionHandler creates a new ReactionHandler.
+func NewReactionHandler(reactions *service.ReactionService) *ReactionHandler {
+	return &ReactionHandler{reactions: reactions}
+}
+
+// List handles GET /api/reactions — active reaction types in display order.
+func (h *ReactionHandler) List(w http.ResponseWriter, r *http.Request) {
+	types, err := h.reactions.List(r.Context(), false)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to fetch reactions")
+		return
+	}
+
+	JSON(w, http.StatusOK, types)
+}
+
+// ListAll handles GET /api/admin/reactions — all reaction types, including inactive ones.
+func (h *ReactionHandler) ListAll(w http.ResponseWriter, r *http.Request) {
+	types, err := h.reactions.List(r.Context(), true)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to fetch reactions")
+		return
+	}
+
+	JSON(w, http.StatusOK, types)
+}
+
+// Upsert handles PUT /api/admin/reactions/{key}.
+func (h *ReactionHandler) Upsert(w http.ResponseWriter, r *http.Request) {
+	var req models.UpsertReactionTypeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rt, err := h.reactions.Upsert(r.Context(), chi.URLParam(r, "key"), req)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, rt)
+}
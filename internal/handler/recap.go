@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/cursor"
+	"ai-companion-be/internal/middleware"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/service"
+)
+
+// RecapHandler handles weekly recap endpoints.
+type RecapHandler struct {
+	recaps *service.RecapService
+}
+
+// NewRecapHandler creates a new RecapHandler.
+func NewRecapHandler(recaps *service.RecapService) *RecapHandler {
+	return &RecapHandler{recaps: recaps}
+}
+
+// List handles GET /api/companions/{id}/recaps (?cursor=, ?limit=).
+func (h *RecapHandler) List(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	q := r.URL.Query()
+	query := models.RecapQuery{Cursor: q.Get("cursor")}
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			query.Limit = l
+		}
+	}
+
+	page, err := h.recaps.List(r.Context(), userID, companionID, query)
+	if err != nil {
+		if errors.Is(err, cursor.ErrInvalid) {
+			Error(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		Error(w, http.StatusInternalServerError, "failed to fetch recaps")
+		return
+	}
+
+	JSON(w, http.StatusOK, page)
+}
+
+// Get handles GET /api/recaps/{id}.
+func (h *RecapHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid recap id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	recap, err := h.recaps.Get(r.Context(), userID, id)
+	if err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, recap)
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/cursor"
+	"ai-companion-be/internal/middleware"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/service"
+)
+
+// SearchHandler handles search endpoints.
+type SearchHandler struct {
+	search    *service.SearchService
+	retrieval *service.RetrievalService
+}
+
+// NewSearchHandler creates a new SearchHandler.
+func NewSearchHandler(search *service.SearchService, retrieval *service.RetrievalService) *SearchHandler {
+	return &SearchHandler{search: search, retrieval: retrieval}
+}
+
+// Search handles GET /api/search?q=... (?type=message|memory,
+// ?companion_id=, ?from=&to= as YYYY-MM-DD, both inclusive, ?cursor=, ?limit=).
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	q := r.URL.Query()
+	query := models.SearchQuery{Cursor: q.Get("cursor"), Limit: 20}
+
+	query.Query = strings.TrimSpace(q.Get("q"))
+	if query.Query == "" {
+		Error(w, http.StatusBadRequest, "q is required")
+		return
+	}
+	if len(query.Query) > service.MaxSearchQuery {
+		Error(w, http.StatusBadRequest, fmt.Sprintf("q must be at most %d characters", service.MaxSearchQuery))
+		return
+	}
+
+	switch t := q.Get("type"); t {
+	case "", "all":
+	case models.SearchTypeMessage, models.SearchTypeMemory:
+		query.Type = t
+	default:
+		Error(w, http.StatusBadRequest, "type must be message, memory or all")
+		return
+	}
+
+	if v := q.Get("companion_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "invalid companion id")
+			return
+		}
+		query.CompanionID = &id
+	}
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "from must be a date (YYYY-MM-DD)")
+			return
+		}
+		query.From = &t
+	}
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "to must be a date (YYYY-MM-DD)")
+			return
+		}
+		// The filter's upper bound is exclusive; include the whole day.
+		t = t.AddDate(0, 0, 1)
+		query.To = &t
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		Error(w, http.StatusBadRequest, "from must not be after to")
+		return
+	}
+
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			query.Limit = l
+		}
+	}
+
+	page, err := h.search.Search(r.Context(), userID, query)
+	if err != nil {
+		if errors.Is(err, cursor.ErrInvalid) {
+			Error(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		Error(w, http.StatusInternalServerError, "failed to search")
+		return
+	}
+
+	JSON(w, http.StatusOK, page)
+}
+
+// Recall handles GET /api/companions/{id}/recall?q=...&limit=... — past
+// exchanges and memories semantically related to q, most relevant first.
+func (h *SearchHandler) Recall(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		Error(w, http.StatusBadRequest, "q is required")
+		return
+	}
+	if len(q) > service.MaxSearchQuery {
+		Error(w, http.StatusBadRequest, fmt.Sprintf("q must be at most %d characters", service.MaxSearchQuery))
+		return
+	}
+
+	limit := 5
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	recalled, err := h.retrieval.Recall(r.Context(), userID, companionID, q, limit, nil)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, "failed to recall")
+		return
+	}
+
+	JSON(w, http.StatusOK, recalled)
+}
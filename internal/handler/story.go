@@ -38,6 +38,7 @@ func (h *StoryHandler) GetActiveStories(w http.ResponseWriter, r *http.Request)
 }
 
 // GetByCompanion handles GET /api/companions/{id}/stories.
+// ?include=highlights also returns expired stories kept in a highlight.
 func (h *StoryHandler) GetByCompanion(w http.ResponseWriter, r *http.Request) {
 	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
@@ -45,7 +46,12 @@ func (h *StoryHandler) GetByCompanion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stories, err := h.stories.GetByCompanionID(r.Context(), companionID)
+	mode := models.StoryListActive
+	if r.URL.Query().Get("include") == "highlights" {
+		mode = models.StoryListWithHighlights
+	}
+
+	stories, err := h.stories.GetByCompanionID(r.Context(), companionID, mode)
 	if err != nil {
 		Error(w, http.StatusInternalServerError, "failed to fetch stories")
 		return
@@ -77,3 +83,176 @@ func (h *StoryHandler) React(w http.ResponseWriter, r *http.Request) {
 
 	JSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
+
+// Unreact handles DELETE /api/stories/{id}/react?media_id=.
+func (h *StoryHandler) Unreact(w http.ResponseWriter, r *http.Request) {
+	storyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid story id")
+		return
+	}
+	mediaID, err := uuid.Parse(r.URL.Query().Get("media_id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid media_id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	if err := h.stories.RemoveReaction(r.Context(), userID, storyID, mediaID); err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// GetHighlights handles GET /api/companions/{id}/highlights.
+func (h *StoryHandler) GetHighlights(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	highlights, err := h.stories.GetHighlights(r.Context(), companionID)
+	if err != nil {
+		slog.Error("GetHighlights failed", "error", err)
+		Error(w, http.StatusInternalServerError, "failed to fetch highlights")
+		return
+	}
+
+	JSON(w, http.StatusOK, highlights)
+}
+
+// Save handles POST /api/stories/{id}/save.
+func (h *StoryHandler) Save(w http.ResponseWriter, r *http.Request) {
+	storyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid story id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	if err := h.stories.SaveStory(r.Context(), userID, storyID); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Unsave handles DELETE /api/stories/{id}/save.
+func (h *StoryHandler) Unsave(w http.ResponseWriter, r *http.Request) {
+	storyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid story id")
+		return
+	}
+
+	userID := middleware.GetUserID(r.Context())
+
+	if err := h.stories.UnsaveStory(r.Context(), userID, storyID); err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// GetSaved handles GET /api/saved-stories.
+func (h *StoryHandler) GetSaved(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	stories, err := h.stories.GetSavedStories(r.Context(), userID)
+	if err != nil {
+		slog.Error("GetSavedStories failed", "error", err)
+		Error(w, http.StatusInternalServerError, "failed to fetch saved stories")
+		return
+	}
+
+	JSON(w, http.StatusOK, stories)
+}
+
+// CreateHighlight handles POST /api/admin/companions/{id}/highlights.
+func (h *StoryHandler) CreateHighlight(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	var req models.CreateHighlightRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	highlight, err := h.stories.CreateHighlight(r.Context(), companionID, req)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusCreated, highlight)
+}
+
+// DeleteHighlight handles DELETE /api/admin/highlights/{id}.
+func (h *StoryHandler) DeleteHighlight(w http.ResponseWriter, r *http.Request) {
+	highlightID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid highlight id")
+		return
+	}
+
+	if err := h.stories.DeleteHighlight(r.Context(), highlightID); err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// AddToHighlight handles POST /api/admin/highlights/{id}/stories.
+func (h *StoryHandler) AddToHighlight(w http.ResponseWriter, r *http.Request) {
+	highlightID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid highlight id")
+		return
+	}
+
+	var req models.AddHighlightStoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.stories.AddToHighlight(r.Context(), highlightID, req.StoryID); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// RemoveFromHighlight handles DELETE /api/admin/highlights/{id}/stories/{storyId}.
+func (h *StoryHandler) RemoveFromHighlight(w http.ResponseWriter, r *http.Request) {
+	highlightID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid highlight id")
+		return
+	}
+	storyID, err := uuid.Parse(chi.URLParam(r, "storyId"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid story id")
+		return
+	}
+
+	if err := h.stories.RemoveFromHighlight(r.Context(), highlightID, storyID); err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/service"
+)
+
+// StoryDraftHandler handles review of AI-generated stories.
+type StoryDraftHandler struct {
+	generator *service.StoryGenerator
+}
+
+// NewStoryDraftHandler creates a new StoryDraftHandler.
+func NewStoryDraftHandler(generator *service.StoryGenerator) *StoryDraftHandler {
+	return &StoryDraftHandler{generator: generator}
+}
+
+// List handles GET /api/admin/story-drafts?status=pending&limit=...
+func (h *StoryDraftHandler) List(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = models.DraftPending
+	case models.DraftPending, models.DraftPublished, models.DraftRejected:
+	default:
+		Error(w, http.StatusBadRequest, "invalid status")
+		return
+	}
+
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	drafts, err := h.generator.ListDrafts(r.Context(), status, limit)
+	if err != nil {
+		slog.Error("ListDrafts failed", "error", err)
+		Error(w, http.StatusInternalServerError, "failed to fetch story drafts")
+		return
+	}
+
+	JSON(w, http.StatusOK, drafts)
+}
+
+// Generate handles POST /api/admin/companions/{id}/story-drafts — generates a story now.
+func (h *StoryDraftHandler) Generate(w http.ResponseWriter, r *http.Request) {
+	companionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid companion id")
+		return
+	}
+
+	draft, err := h.generator.GenerateFor(r.Context(), companionID)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusCreated, draft)
+}
+
+// Approve handles POST /api/admin/story-drafts/{id}/approve.
+func (h *StoryDraftHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	draftID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid draft id")
+		return
+	}
+
+	draft, err := h.generator.Approve(r.Context(), draftID)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, draft)
+}
+
+// Reject handles POST /api/admin/story-drafts/{id}/reject.
+func (h *StoryDraftHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	draftID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, "invalid draft id")
+		return
+	}
+
+	if err := h.generator.Reject(r.Context(), draftID); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	JSON(w, http.StatusOK, map[string]string{"status": "rejected"})
+}
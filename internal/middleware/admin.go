@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/config"
+	"ai-companion-be/internal/response"
+)
+
+// RequireAdmin returns middleware that only admits users listed in
+// ADMIN_USER_IDS. It must run after Auth.
+func RequireAdmin(adminCfg config.AdminConfig) func(http.Handler) http.Handler {
+	admins := make(map[uuid.UUID]bool, len(adminCfg.UserIDs))
+	for _, s := range adminCfg.UserIDs {
+		if id, err := uuid.Parse(s); err == nil {
+			admins[id] = true
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !admins[GetUserID(r.Context())] {
+				response.Error(w, http.StatusForbidden, "admin access required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -14,10 +14,21 @@ import (
 
 type contextKey string
 
-const UserIDKey contextKey = "user_id"
+const (
+	UserIDKey    contextKey = "user_id"
+	SessionIDKey contextKey = "session_id"
+)
+
+// SessionValidator reports whether a token's login session is still
+// active.
+type SessionValidator interface {
+	SessionActive(ctx context.Context, userID, sessionID uuid.UUID) (bool, error)
+}
 
-// Auth returns middleware that validates JWT tokens and injects user_id into context.
-func Auth(jwtCfg config.JWTConfig) func(http.Handler) http.Handler {
+// Auth returns middleware that validates JWT tokens and injects user_id and
+// session_id into context. Tokens whose session has been revoked or has
+// expired are rejected.
+func Auth(jwtCfg config.JWTConfig, sessions SessionValidator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -63,7 +74,27 @@ func Auth(jwtCfg config.JWTConfig) func(http.Handler) http.Handler {
 				return
 			}
 
+			// Tokens issued before sessions existed carry no sid and are
+			// refused, so every token in use can be revoked.
+			sidStr, _ := claims["sid"].(string)
+			sessionID, err := uuid.Parse(sidStr)
+			if err != nil {
+				response.Error(w, http.StatusUnauthorized, "invalid session in token")
+				return
+			}
+
+			active, err := sessions.SessionActive(r.Context(), userID, sessionID)
+			if err != nil {
+				response.Error(w, http.StatusInternalServerError, "failed to verify session")
+				return
+			}
+			if !active {
+				response.Error(w, http.StatusUnauthorized, "session has been revoked or has expired")
+				return
+			}
+
 			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			ctx = context.WithValue(ctx, SessionIDKey, sessionID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -76,3 +107,12 @@ func GetUserID(ctx context.Context) uuid.UUID {
 	}
 	return uuid.Nil
 }
+
+// GetSessionID extracts the authenticated session ID from the request
+// context.
+func GetSessionID(ctx context.Context) uuid.UUID {
+	if id, ok := ctx.Value(SessionIDKey).(uuid.UUID); ok {
+		return id
+	}
+	return uuid.Nil
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/config"
+)
+
+const testSecret = "test-secret"
+
+// fakeSessions reports the sessions in active as active and records the
+// sessions it was asked about.
+type fakeSessions struct {
+	active map[uuid.UUID]bool
+	err    error
+	asked  []uuid.UUID
+}
+
+func (f *fakeSessions) SessionActive(_ context.Context, _ uuid.UUID, sessionID uuid.UUID) (bool, error) {
+	f.asked = append(f.asked, sessionID)
+	return f.active[sessionID], f.err
+}
+
+func claimsFor(userID uuid.UUID, sid any) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"user_id": userID.String(),
+		"exp":     time.Now().Add(time.Minute).Unix(),
+		"iat":     time.Now().Unix(),
+	}
+	if sid != nil {
+		claims["sid"] = sid
+	}
+	return claims
+}
+
+func signHS256(t *testing.T, claims jwt.MapClaims, secret string) string {
+	t.Helper()
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestAuth(t *testing.T) {
+	userID, sessionID, revokedID := uuid.New(), uuid.New(), uuid.New()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs256, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claimsFor(userID, sessionID.String())).SignedString(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, claimsFor(userID, sessionID.String())).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired := claimsFor(userID, sessionID.String())
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+
+	tests := []struct {
+		name       string
+		header     string
+		sessionErr error
+		wantStatus int
+		wantAsked  bool
+	}{
+		{"active session", "Bearer " + signHS256(t, claimsFor(userID, sessionID.String()), testSecret), nil, http.StatusOK, true},
+		{"revoked session", "Bearer " + signHS256(t, claimsFor(userID, revokedID.String()), testSecret), nil, http.StatusUnauthorized, true},
+		{"session check fails", "Bearer " + signHS256(t, claimsFor(userID, sessionID.String()), testSecret), errors.New("db down"), http.StatusInternalServerError, true},
+		{"missing sid", "Bearer " + signHS256(t, claimsFor(userID, nil), testSecret), nil, http.StatusUnauthorized, false},
+		{"malformed sid", "Bearer " + signHS256(t, claimsFor(userID, "not-a-uuid"), testSecret), nil, http.StatusUnauthorized, false},
+		{"non-string sid", "Bearer " + signHS256(t, claimsFor(userID, 42), testSecret), nil, http.StatusUnauthorized, false},
+		{"wrong secret", "Bearer " + signHS256(t, claimsFor(userID, sessionID.String()), "other-secret"), nil, http.StatusUnauthorized, false},
+		{"RS256 signed", "Bearer " + rs256, nil, http.StatusUnauthorized, false},
+		{"unsigned", "Bearer " + none, nil, http.StatusUnauthorized, false},
+		{"expired", "Bearer " + signHS256(t, expired, testSecret), nil, http.StatusUnauthorized, false},
+		{"missing header", "", nil, http.StatusUnauthorized, false},
+		{"not bearer", "Basic dXNlcjpwYXNz", nil, http.StatusUnauthorized, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &fakeSessions{active: map[uuid.UUID]bool{sessionID: true}, err: tt.sessionErr}
+			var gotUser, gotSession uuid.UUID
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUser, gotSession = GetUserID(r.Context()), GetSessionID(r.Context())
+			})
+			handler := Auth(config.JWTConfig{Secret: testSecret}, sessions)(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/users/me", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if asked := len(sessions.asked) > 0; asked != tt.wantAsked {
+				t.Errorf("session checked = %v, want %v", asked, tt.wantAsked)
+			}
+			if tt.wantStatus == http.StatusOK {
+				if gotUser != userID || gotSession != sessionID {
+					t.Errorf("context has user %s session %s, want %s %s", gotUser, gotSession, userID, sessionID)
+				}
+			} else if gotUser != uuid.Nil {
+				t.Error("next handler was called")
+			}
+		})
+	}
+}
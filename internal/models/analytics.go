@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecordStoryViewRequest is the payload for reporting that a slide was shown.
+type RecordStoryViewRequest struct {
+	MediaID   uuid.UUID `json:"media_id"`
+	Completed bool      `json:"completed"`  // the slide played to the end
+	WatchedMS int       `json:"watched_ms"` // how long it was on screen
+}
+
+// AnalyticsFilter narrows story analytics to a date range (UTC days,
+// inclusive) and optionally one companion.
+type AnalyticsFilter struct {
+	From        time.Time
+	To          time.Time
+	CompanionID *uuid.UUID
+}
+
+// StoryMetrics are the performance figures reported for a story or slide.
+//
+// UniqueViewers and Completions count distinct users over the whole range;
+// for a story, a user counts once however many of its slides they viewed,
+// and as a completion only if they played every one of its slides to the
+// end within the range. Deltas are the mood/relationship change produced
+// by reactions.
+type StoryMetrics struct {
+	Views             int            `json:"views"`
+	UniqueViewers     int            `json:"unique_viewers"`
+	Completions       int            `json:"completions"`
+	CompletionRate    float64        `json:"completion_rate"`
+	Reactions         map[string]int `json:"reactions"`
+	Replies           int            `json:"replies"`
+	MoodDelta         float64        `json:"mood_delta"`
+	RelationshipDelta float64        `json:"relationship_delta"`
+}
+
+// StoryStats reports one story's performance.
+type StoryStats struct {
+	StoryID     uuid.UUID `json:"story_id"`
+	CompanionID uuid.UUID `json:"companion_id"`
+	Slides      int       `json:"slides"`
+	StoryMetrics
+}
+
+// StoryMediaStats reports one slide's performance. Slide details are nil
+// once the story has been cleaned up.
+type StoryMediaStats struct {
+	MediaID   uuid.UUID `json:"media_id"`
+	StoryID   uuid.UUID `json:"story_id"`
+	MediaType *string   `json:"media_type,omitempty"`
+	Caption   *string   `json:"caption,omitempty"`
+	SortOrder *int      `json:"sort_order,omitempty"`
+	StoryMetrics
+}
@@ -14,4 +14,6 @@ type Companion struct {
 	AvatarURL   string    `json:"avatar_url"`
 	Personality string    `json:"personality"`
 	CreatedAt   time.Time `json:"created_at"`
+
+	AvatarThumbnailURL *string `json:"avatar_thumbnail_url,omitempty"`
 }
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Conversation tones, from the mean sentiment of the user's messages.
+const (
+	TonePositive = "positive"
+	ToneNeutral  = "neutral"
+	ToneNegative = "negative"
+)
+
+// ConversationSnapshot is a periodic analysis of a relationship's recent
+// conversation, covering WindowStart to WindowEnd. WindowStart is the
+// oldest message analysed when the message cap cut the window short.
+// SnapshotDate is the day it was taken, in the user's time zone.
+type ConversationSnapshot struct {
+	ID           uuid.UUID `json:"id"`
+	UserID       uuid.UUID `json:"-"`
+	CompanionID  uuid.UUID `json:"companion_id"`
+	SnapshotDate string    `json:"snapshot_date"`
+	WindowStart  time.Time `json:"window_start"`
+	WindowEnd    time.Time `json:"window_end"`
+
+	UserMessages       int     `json:"user_messages"`
+	CompanionMessages  int     `json:"companion_messages"`
+	AvgUserLength      float64 `json:"avg_user_length"`
+	AvgCompanionLength float64 `json:"avg_companion_length"`
+
+	// Replies counts the user's answers to companion messages within a
+	// session; the percentiles are over how long those took.
+	Replies            int      `json:"replies"`
+	ReplyMedianSeconds *float64 `json:"reply_median_seconds"`
+	ReplyP90Seconds    *float64 `json:"reply_p90_seconds"`
+
+	// ActiveHours counts the user's messages by local hour of day;
+	// PeakHours are the busiest, busiest first.
+	ActiveHours []int `json:"active_hours"`
+	PeakHours   []int `json:"peak_hours"`
+
+	// Sentiment is the mean tone of the user's messages, -1 to 1, or nil
+	// when none carried any.
+	Sentiment      *float64         `json:"sentiment"`
+	Tone           string           `json:"tone"`
+	SentimentTrend []SentimentPoint `json:"sentiment_trend"`
+
+	Topics []ConversationTopic `json:"topics"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SentimentPoint is the mean sentiment of the user's messages on one day.
+type SentimentPoint struct {
+	Date      string  `json:"date"`
+	Sentiment float64 `json:"sentiment"`
+	Messages  int     `json:"messages"`
+}
+
+// ConversationTopic is a cluster of the user's messages about the same
+// thing. Share is its fraction of the clustered messages.
+type ConversationTopic struct {
+	Label    string   `json:"label"`
+	Keywords []string `json:"keywords"`
+	Messages int      `json:"messages"`
+	Share    float64  `json:"share"`
+}
+
+// ConversationPair is a relationship due a conversation snapshot.
+type ConversationPair struct {
+	UserID      uuid.UUID
+	CompanionID uuid.UUID
+	Timezone    string
+}
+
+// PeakHours returns up to n hours with activity, busiest first, earlier
+// hours first on ties.
+func PeakHours(counts []int, n int) []int {
+	hours := []int{}
+	for h, c := range counts {
+		if c > 0 {
+			hours = append(hours, h)
+		}
+	}
+	sort.SliceStable(hours, func(i, j int) bool { return counts[hours[i]] > counts[hours[j]] })
+	if len(hours) > n {
+		hours = hours[:n]
+	}
+	return hours
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Embedding source types.
+const (
+	EmbeddingSourceMessage = "message"
+	EmbeddingSourceMemory  = "memory"
+)
+
+// EmbeddingSource is a message or memory waiting to be embedded.
+type EmbeddingSource struct {
+	Type        string
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	CompanionID uuid.UUID
+	Text        string
+
+	// ContentHash identifies the text that was embedded.
+	ContentHash string
+}
+
+// EmbeddingMatch is a message or memory close to a query vector.
+type EmbeddingMatch struct {
+	Type       string
+	ID         uuid.UUID
+	Similarity float64
+}
+
+// RecalledExchange is a past moment relevant to what is being said now:
+// a user message with the companion's reply (or the reverse), or a memory.
+type RecalledExchange struct {
+	Type       string    `json:"type"` // EmbeddingSourceMessage or EmbeddingSourceMemory
+	Similarity float64   `json:"similarity"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// Messages is the exchange in chronological order (message matches).
+	Messages []Message `json:"messages,omitempty"`
+
+	// Memory is the matching memory (memory matches).
+	Memory *Memory `json:"memory,omitempty"`
+}
@@ -1,13 +1,56 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // CompanionInsights aggregates relationship analytics for a user-companion pair.
 type CompanionInsights struct {
-	MoodHistory []MoodSnapshot `json:"mood_history"`
-	Streak      StreakInfo     `json:"streak"`
-	Milestones  []Milestone    `json:"milestones"`
-	Stats       InsightStats   `json:"stats"`
+	Range       InsightsRange    `json:"range"`
+	MoodHistory []MoodSnapshot   `json:"mood_history"`
+	Series      []InsightsBucket `json:"series"`
+	Streak      StreakInfo       `json:"streak"`
+	Milestones  []Milestone      `json:"milestones"`
+	Stats       InsightStats     `json:"stats"`
+
+	// Conversation is the latest conversation analytics snapshot, nil
+	// until the first is taken.
+	Conversation *ConversationSnapshot `json:"conversation"`
+}
+
+// Insights granularities.
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// InsightsRange is the window and bucket size of an insights series. From
+// and To are dates (YYYY-MM-DD) in the user's time zone, both inclusive.
+// Weeks start on Monday.
+type InsightsRange struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Granularity string `json:"granularity"`
+	Timezone    string `json:"timezone"`
+}
+
+// InsightsBucket aggregates one day, week or month of a relationship. The
+// first and last buckets are clipped to the range. Buckets without mood
+// snapshots carry the last known mood forward (MoodCarried); before any
+// snapshot the mood fields are null.
+type InsightsBucket struct {
+	Start       string   `json:"start"`
+	End         string   `json:"end"`
+	MoodMin     *float64 `json:"mood_min"`
+	MoodAvg     *float64 `json:"mood_avg"`
+	MoodMax     *float64 `json:"mood_max"`
+	MoodLabel   *string  `json:"mood_label"`
+	MoodCarried bool     `json:"mood_carried"`
+	Messages    int      `json:"messages"`
+	Reactions   int      `json:"reactions"`
 }
 
 // MoodSnapshot represents a single day's mood score.
@@ -23,13 +66,25 @@ type StreakInfo struct {
 	Longest int `json:"longest"`
 }
 
-// Milestone represents an achieved relationship milestone.
+// StreakState is the rolled-up record of a messaging streak: the latest
+// local day with a user message, the run of consecutive days ending there
+// and the longest run. ActiveDays counts every day with a user message.
+type StreakState struct {
+	LastActiveDate *time.Time
+	CurrentRun     int
+	LongestRun     int
+	ActiveDays     int
+}
+
+// Milestone is a relationship milestone and whether the pair has achieved
+// it. MessageID is the companion's celebratory message, if one was sent.
 type Milestone struct {
 	Key         string     `json:"key"`
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	AchievedAt  *time.Time `json:"achieved_at,omitempty"`
 	Achieved    bool       `json:"achieved"`
+	MessageID   *uuid.UUID `json:"message_id,omitempty"`
 }
 
 // InsightStats holds aggregate counts for the relationship.
@@ -41,6 +96,7 @@ type InsightStats struct {
 }
 
 // ReactionSummary aggregates story reaction data for a user-companion pair.
+// DominantEmotion is picked by DominantReaction.
 type ReactionSummary struct {
 	Total           int              `json:"total"`
 	Counts          map[string]int   `json:"counts"`
@@ -53,3 +109,151 @@ type RecentReaction struct {
 	Reaction  string    `json:"reaction"`
 	ReactedAt time.Time `json:"reacted_at"`
 }
+
+// ReactionUse counts the uses of one reaction type, with the catalogue
+// display order and sentiment weight it is ranked and scored by.
+type ReactionUse struct {
+	Reaction string
+	Count    int
+	Order    int
+	Weight   float64
+}
+
+// DominantReaction returns the most used reaction in uses, ties going to
+// the one earlier in display order and then by key, or nil when nothing
+// was used.
+func DominantReaction(uses []ReactionUse) *string {
+	var best *ReactionUse
+	for i := range uses {
+		u := &uses[i]
+		if u.Count == 0 {
+			continue
+		}
+		if best == nil || u.Count > best.Count ||
+			u.Count == best.Count && (u.Order < best.Order || u.Order == best.Order && u.Reaction < best.Reaction) {
+			best = u
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	key := best.Reaction
+	return &key
+}
+
+// ReactionDay is a user's reactions to a companion's stories on one local
+// day (YYYY-MM-DD), in catalogue order.
+type ReactionDay struct {
+	Date string
+	Uses []ReactionUse
+}
+
+// ReactionTrends shows how a user's reactions to a companion's stories
+// change over a range: bucketed counts, how they line up with the
+// relationship's mood, and a breakdown of the stories reacted to.
+type ReactionTrends struct {
+	Range   InsightsRange           `json:"range"`
+	Total   int                     `json:"total"`
+	Buckets []ReactionBucket        `json:"buckets"`
+	Mood    ReactionMoodCorrelation `json:"mood"`
+	Stories []StoryReactions        `json:"stories"`
+}
+
+// ReactionBucket aggregates the reactions of one day, week or month of a
+// range, with the same bounds as the insights series. Sentiment is the
+// mean sentiment weight of the reactions, null when there were none;
+// MoodAvg is the bucket's mood as in the series.
+type ReactionBucket struct {
+	Start     string         `json:"start"`
+	End       string         `json:"end"`
+	Total     int            `json:"total"`
+	Counts    map[string]int `json:"counts"`
+	Dominant  *string        `json:"dominant"`
+	Sentiment *float64       `json:"sentiment"`
+	MoodAvg   *float64       `json:"mood_avg"`
+}
+
+// ReactionMoodCorrelation relates reactions to mood over the days of a
+// range that have both reactions and a mood snapshot. Coefficient is the
+// Pearson correlation between a day's mean reaction sentiment and its
+// mood score, null with too few days or no variation to measure.
+type ReactionMoodCorrelation struct {
+	Days        int            `json:"days"`
+	Coefficient *float64       `json:"coefficient"`
+	ByReaction  []ReactionMood `json:"by_reaction"`
+}
+
+// ReactionMood is the average mood score on the days a reaction was used.
+type ReactionMood struct {
+	Reaction string  `json:"reaction"`
+	Days     int     `json:"days"`
+	MoodAvg  float64 `json:"mood_avg"`
+}
+
+// StoryReactions breaks down a user's reactions to one story's slides.
+type StoryReactions struct {
+	StoryID       uuid.UUID      `json:"story_id"`
+	CreatedAt     time.Time      `json:"created_at"`
+	LastReactedAt time.Time      `json:"last_reacted_at"`
+	Total         int            `json:"total"`
+	Counts        map[string]int `json:"counts"`
+	Dominant      *string        `json:"dominant"`
+	Sentiment     float64        `json:"sentiment"`
+	Uses          []ReactionUse  `json:"-"`
+}
+
+// InsightsOverview summarises a user's activity across all companions.
+type InsightsOverview struct {
+	Totals           OverviewTotals       `json:"totals"`
+	Companions       []CompanionActivity  `json:"companions"`
+	StrongestBond    *CompanionActivity   `json:"strongest_bond"`
+	Streak           StreakInfo           `json:"streak"`
+	RecentMilestones []CompanionMilestone `json:"recent_milestones"`
+	Heatmap          ActivityHeatmap      `json:"heatmap"`
+}
+
+// OverviewTotals is a user's activity summed over all companions. ActiveDays
+// counts the days, in the user's time zone, on which they sent a message.
+type OverviewTotals struct {
+	Companions  int     `json:"companions"`
+	Messages    int     `json:"messages"`
+	Memories    int     `json:"memories"`
+	Reactions   int     `json:"reactions"`
+	ActiveDays  int     `json:"active_days"`
+	ChatMinutes float64 `json:"chat_minutes"`
+}
+
+// CompanionActivity is a user's activity with one companion. ChatMinutes
+// estimates time spent chatting from the gaps between messages within a
+// session; MessageShare is the fraction of the user's messages.
+type CompanionActivity struct {
+	CompanionID       uuid.UUID `json:"companion_id"`
+	Name              string    `json:"name"`
+	AvatarURL         string    `json:"avatar_url"`
+	Messages          int       `json:"messages"`
+	Memories          int       `json:"memories"`
+	Reactions         int       `json:"reactions"`
+	ChatMinutes       float64   `json:"chat_minutes"`
+	MessageShare      float64   `json:"message_share"`
+	MoodScore         float64   `json:"mood_score"`
+	MoodLabel         string    `json:"mood_label"`
+	RelationshipScore float64   `json:"relationship_score"`
+	LastInteraction   time.Time `json:"last_interaction"`
+}
+
+// CompanionMilestone is a milestone achieved with a particular companion.
+type CompanionMilestone struct {
+	Milestone
+	CompanionID   uuid.UUID `json:"companion_id"`
+	CompanionName string    `json:"companion_name"`
+}
+
+// ActivityHeatmap counts the user's messages by weekday and hour in their
+// time zone over the last Weeks weeks, from From (YYYY-MM-DD). Counts[0] is
+// Monday and Counts[d][h] the hour starting at h:00.
+type ActivityHeatmap struct {
+	Weeks    int        `json:"weeks"`
+	From     string     `json:"from"`
+	Timezone string     `json:"timezone"`
+	Counts   [7][24]int `json:"counts"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Scheduled job run statuses.
+const (
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// JobRun is one recorded execution of a scheduled job.
+type JobRun struct {
+	ID           int64      `json:"id"`
+	JobName      string     `json:"job_name"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	Instance     string     `json:"instance"`
+	Status       string     `json:"status"`
+	Error        *string    `json:"error,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+// JobStatus describes a registered job and this instance's metrics for it.
+// Metrics are in-memory and reset on restart; run history is in job_runs.
+type JobStatus struct {
+	Name     string    `json:"name"`
+	Schedule string    `json:"schedule"`
+	NextRun  time.Time `json:"next_run"`
+	Running  bool      `json:"running"`
+
+	// Slots this instance ran, and how many of those failed.
+	Runs     int64 `json:"runs"`
+	Failures int64 `json:"failures"`
+	// Slots skipped because another replica held the lock or had already
+	// run them, or a previous run was still in progress.
+	Skipped int64 `json:"skipped"`
+
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	LastDurationMS int64      `json:"last_duration_ms"`
+	LastError      *string    `json:"last_error,omitempty"`
+}
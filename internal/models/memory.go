@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,18 +17,151 @@ type Memory struct {
 	Tag         *string    `json:"tag,omitempty"`
 	Pinned      bool       `json:"pinned"`
 	CreatedAt   time.Time  `json:"created_at"`
+	EditedAt    *time.Time `json:"edited_at,omitempty"`
+
+	// StoryMediaID is the story slide the memory was saved from, if any.
+	StoryMediaID *uuid.UUID `json:"story_media_id,omitempty"`
+
+	// Importance (0..1) is rated when the memory is saved and boosted each
+	// time it is recalled in chat, resurfaced or rescued. LastRecalledAt is
+	// the latest of those.
+	Importance     float64    `json:"importance"`
+	LastRecalledAt *time.Time `json:"last_recalled_at,omitempty"`
+	RecallCount    int        `json:"recall_count"`
+}
+
+// ReinforcedAt is when the memory's forgetting curve last restarted.
+func (m Memory) ReinforcedAt() time.Time {
+	if m.LastRecalledAt != nil {
+		return *m.LastRecalledAt
+	}
+	return m.CreatedAt
+}
+
+// Strength is the memory's importance decayed by halfLife since it was
+// last reinforced; pinned memories stay at 1. Mirrors the memory_strength
+// SQL function.
+func (m Memory) Strength(at time.Time, halfLife time.Duration) float64 {
+	if m.Pinned {
+		return 1
+	}
+	age := max(at.Sub(m.ReinforcedAt()), 0)
+	return m.Importance * math.Pow(0.5, float64(age)/float64(halfLife))
+}
+
+// FadingMemory is a memory about to drop out of, or already out of, the
+// companion's active recall.
+type FadingMemory struct {
+	Memory
+	Strength float64   `json:"strength"`
+	FadesAt  time.Time `json:"fades_at"`
+	Faded    bool      `json:"faded"`
 }
 
-// MemoryPage represents a paginated page of memories.
+// MemoryPage represents a cursor-paginated page of memories, pinned first
+// and then newest first. Pass NextCursor back as cursor= for the next page.
 type MemoryPage struct {
 	Memories   []Memory `json:"memories"`
 	NextCursor string   `json:"next_cursor,omitempty"`
 	HasMore    bool     `json:"has_more"`
 }
 
-// CreateMemoryRequest is the payload for creating a new memory.
+// MemoryKey is a memory's position in the (pinned DESC, created_at DESC,
+// id DESC) keyset order.
+type MemoryKey struct {
+	Pinned    bool      `json:"p"`
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// MemoryFilter narrows a memory listing. From is inclusive and To exclusive.
+type MemoryFilter struct {
+	Tag        *string
+	PinnedOnly bool
+	From       *time.Time
+	To         *time.Time
+}
+
+// MemoryQuery selects a page of a user's memories with a companion.
+type MemoryQuery struct {
+	MemoryFilter
+	Cursor string
+	Limit  int
+}
+
+// CreateMemoryRequest is the payload for creating a new memory. MessageID
+// and StoryMediaID optionally record where it was saved from.
 type CreateMemoryRequest struct {
-	MessageID *uuid.UUID `json:"message_id,omitempty"`
-	Content   string     `json:"content"`
-	Tag       *string    `json:"tag,omitempty"`
+	MessageID    *uuid.UUID `json:"message_id,omitempty"`
+	StoryMediaID *uuid.UUID `json:"story_media_id,omitempty"`
+	Content      string     `json:"content"`
+	Tag          *string    `json:"tag,omitempty"`
+}
+
+// UpdateMemoryRequest is the payload for editing a memory. Omitted fields
+// are left unchanged; an empty tag removes it.
+type UpdateMemoryRequest struct {
+	Content *string `json:"content,omitempty"`
+	Tag     *string `json:"tag,omitempty"`
+}
+
+// MemoryEdit is one entry in a memory's edit history.
+type MemoryEdit struct {
+	ID              uuid.UUID `json:"id"`
+	MemoryID        uuid.UUID `json:"memory_id"`
+	PreviousContent string    `json:"previous_content"`
+	PreviousTag     *string   `json:"previous_tag,omitempty"`
+	Content         string    `json:"content"`
+	Tag             *string   `json:"tag,omitempty"`
+	EditedAt        time.Time `json:"edited_at"`
+}
+
+// MemoryTag is an entry in a user's tag vocabulary. MemoryCount is the
+// number of the user's memories carrying it, across all companions.
+type MemoryTag struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Name        string    `json:"name"`
+	MemoryCount int       `json:"memory_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// MemoryTagCount is how many of a user's memories with one companion carry a tag.
+type MemoryTagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// MemoryTagRequest is the payload for creating or renaming a tag.
+type MemoryTagRequest struct {
+	Name string `json:"name"`
+}
+
+// MergeMemoryTagRequest is the payload for merging one tag into another.
+type MergeMemoryTagRequest struct {
+	Into uuid.UUID `json:"into"`
+}
+
+// Reasons a memory was resurfaced.
+const (
+	// SurfacedOnThisDay: saved on the same day of the month in an earlier
+	// month or year.
+	SurfacedOnThisDay = "on_this_day"
+
+	// SurfacedRevisit: a pinned memory that hasn't been shown in a while.
+	SurfacedRevisit = "revisit"
+)
+
+// MemorySurfacing is a memory picked by the daily resurfacing job, shown
+// as an "on this day" feed card.
+type MemorySurfacing struct {
+	ID            uuid.UUID  `json:"id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	CompanionID   uuid.UUID  `json:"companion_id"`
+	CompanionName string     `json:"companion_name,omitempty"`
+	Reason        string     `json:"reason"`
+	SurfacedOn    time.Time  `json:"surfaced_on"`
+	MessageID     *uuid.UUID `json:"message_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	Memory        Memory     `json:"memory"`
 }
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryCollection is a named, ordered album of memories for one
+// user-companion pair.
+type MemoryCollection struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	CompanionID uuid.UUID `json:"companion_id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description,omitempty"`
+	SortOrder   int       `json:"sort_order"`
+	MemoryCount int       `json:"memory_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// CoverURL is the explicit cover (ExplicitCoverURL), or else the first
+	// story slide one of the collection's memories was saved from.
+	CoverURL         *string `json:"cover_url,omitempty"`
+	ExplicitCoverURL *string `json:"-"`
+
+	// Memories is set when a single collection is fetched.
+	Memories []Memory `json:"memories,omitempty"`
+}
+
+// CreateCollectionRequest is the payload for creating a collection.
+type CreateCollectionRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+	CoverURL    *string `json:"cover_url,omitempty"`
+	SortOrder   int     `json:"sort_order"`
+}
+
+// UpdateCollectionRequest is the payload for editing a collection. Omitted
+// fields are left unchanged; an empty description or cover_url clears it.
+type UpdateCollectionRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	CoverURL    *string `json:"cover_url,omitempty"`
+	SortOrder   *int    `json:"sort_order,omitempty"`
+}
+
+// AddCollectionMemoryRequest is the payload for adding a memory to a collection.
+type AddCollectionMemoryRequest struct {
+	MemoryID uuid.UUID `json:"memory_id"`
+}
+
+// ReorderCollectionRequest lists every memory in a collection in its new order.
+type ReorderCollectionRequest struct {
+	MemoryIDs []uuid.UUID `json:"memory_ids"`
+}
+
+// CollectionSuggestion is the companion's pick of a collection for a
+// memory: an existing one (CollectionID set) or a name for a new one.
+type CollectionSuggestion struct {
+	CollectionID *uuid.UUID `json:"collection_id,omitempty"`
+	Name         string     `json:"name"`
+	New          bool       `json:"new"`
+}
@@ -15,16 +15,46 @@ type Message struct {
 	Role        string    `json:"role"` // "user" or "companion"
 	CreatedAt   time.Time `json:"created_at"`
 	IsMemorized bool      `json:"is_memorized"`
+
+	// StoryMediaID is the story slide this message replies to, if any.
+	StoryMediaID *uuid.UUID `json:"story_media_id,omitempty"`
 }
 
 // SendMessageRequest is the payload for sending a chat message.
 type SendMessageRequest struct {
 	Content string `json:"content"`
+
+	// StoryMediaID marks the message as a reply to a story slide.
+	StoryMediaID *uuid.UUID `json:"story_media_id,omitempty"`
 }
 
-// MessagePage represents a cursor-paginated page of messages.
+// MessagePage represents a cursor-paginated page of messages, newest first.
+//
+// NextCursor/HasMore page towards older messages (pass it as before=);
+// PrevCursor/HasNewer page towards newer ones (pass it as after=).
 type MessagePage struct {
-	Messages []Message `json:"messages"`
-	NextCursor string  `json:"next_cursor,omitempty"`
-	HasMore    bool    `json:"has_more"`
+	Messages   []Message `json:"messages"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	HasMore    bool      `json:"has_more"`
+	PrevCursor string    `json:"prev_cursor,omitempty"`
+	HasNewer   bool      `json:"has_newer"`
+
+	// AnchorID is the message the page is centred on, for around= and
+	// jump-to-message requests.
+	AnchorID *uuid.UUID `json:"anchor_id,omitempty"`
+}
+
+// MessageKey is a message's position in its conversation's keyset order.
+type MessageKey struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// MessageQuery selects a page of a conversation. At most one of Before,
+// After and Around is set; with none the newest messages are returned.
+type MessageQuery struct {
+	Before string
+	After  string
+	Around string
+	Limit  int
 }
@@ -0,0 +1,31 @@
+package models
+
+import "github.com/google/uuid"
+
+// Milestone metrics: what a definition's threshold is measured against.
+const (
+	MilestoneMetricMessages     = "messages"
+	MilestoneMetricMemories     = "memories"
+	MilestoneMetricDaysTogether = "days_together"
+	MilestoneMetricMood         = "mood"
+	MilestoneMetricRelationship = "relationship"
+)
+
+// MilestoneDefinition is a milestone from the catalogue. It is achieved
+// once Metric reaches Threshold.
+type MilestoneDefinition struct {
+	Key          string  `json:"key"`
+	Title        string  `json:"title"`
+	Description  string  `json:"description"`
+	Metric       string  `json:"metric"`
+	Threshold    float64 `json:"threshold"`
+	DisplayOrder int     `json:"display_order"`
+	Active       bool    `json:"active"`
+}
+
+// MilestoneAchievedEvent is the data of a milestone notification.
+type MilestoneAchievedEvent struct {
+	Key         string     `json:"key"`
+	CompanionID uuid.UUID  `json:"companion_id"`
+	MessageID   *uuid.UUID `json:"message_id,omitempty"`
+}
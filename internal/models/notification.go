@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification kinds.
+const (
+	NotificationMilestoneAchieved = "milestone_achieved"
+	NotificationWeeklyRecap       = "weekly_recap"
+)
+
+// Notification is an event in the user's notification feed. Data holds
+// kind-specific details, e.g. a MilestoneAchievedEvent or WeeklyRecapEvent.
+type Notification struct {
+	ID          uuid.UUID       `json:"id"`
+	UserID      uuid.UUID       `json:"-"`
+	CompanionID *uuid.UUID      `json:"companion_id,omitempty"`
+	Kind        string          `json:"kind"`
+	Title       string          `json:"title"`
+	Body        string          `json:"body"`
+	Data        json.RawMessage `json:"data"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ReadAt      *time.Time      `json:"read_at,omitempty"`
+}
+
+// NotificationQuery selects a page of the user's notifications.
+type NotificationQuery struct {
+	UnreadOnly bool
+	Cursor     string
+	Limit      int
+}
+
+// NotificationKey is a notification's position in the newest-first feed.
+type NotificationKey struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// NotificationPage is a page of notifications, newest first, with the
+// user's unread count.
+type NotificationPage struct {
+	Notifications []Notification `json:"notifications"`
+	NextCursor    string         `json:"next_cursor,omitempty"`
+	HasMore       bool           `json:"has_more"`
+	Unread        int            `json:"unread"`
+}
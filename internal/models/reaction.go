@@ -0,0 +1,21 @@
+package models
+
+// ReactionType is an entry in the story reaction catalogue.
+type ReactionType struct {
+	Key             string  `json:"key"`
+	Emoji           string  `json:"emoji"`
+	Label           string  `json:"label"`
+	DisplayOrder    int     `json:"display_order"`
+	SentimentWeight float64 `json:"sentiment_weight"`
+	Active          bool    `json:"active"`
+}
+
+// UpsertReactionTypeRequest is the payload for creating or updating a
+// reaction type. Omitted fields keep their current value on update.
+type UpsertReactionTypeRequest struct {
+	Emoji           *string  `json:"emoji,omitempty"`
+	Label           *string  `json:"label,omitempty"`
+	DisplayOrder    *int     `json:"display_order,omitempty"`
+	SentimentWeight *float64 `json:"sentiment_weight,omitempty"`
+	Active          *bool    `json:"active,omitempty"`
+}
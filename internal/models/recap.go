@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Mood trends over a recap's week.
+const (
+	MoodTrendRising  = "rising"
+	MoodTrendFalling = "falling"
+	MoodTrendSteady  = "steady"
+)
+
+// WeeklyRecap is the companion's look back at one week of a relationship,
+// Monday to Sunday in the user's time zone.
+type WeeklyRecap struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"-"`
+	CompanionID uuid.UUID `json:"companion_id"`
+	WeekStart   string    `json:"week_start"`
+	WeekEnd     string    `json:"week_end"`
+
+	// Messages counts both sides of the week's conversation.
+	Messages   int              `json:"messages"`
+	Highlights []string         `json:"highlights"`
+	Memories   []RecapMemory    `json:"memories"`
+	Mood       RecapMood        `json:"mood"`
+	Milestones []RecapMilestone `json:"milestones"`
+
+	// Note is the companion's message to the user about the week.
+	Note string `json:"note"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RecapMemory is a memory saved during a recap's week, as it read then.
+type RecapMemory struct {
+	ID      uuid.UUID `json:"id"`
+	Content string    `json:"content"`
+	Tag     *string   `json:"tag,omitempty"`
+}
+
+// RecapMood is the mood trajectory over a recap's week. Without mood
+// snapshots that week, only the empty history is set.
+type RecapMood struct {
+	Start   *float64       `json:"start,omitempty"`
+	End     *float64       `json:"end,omitempty"`
+	Low     *float64       `json:"low,omitempty"`
+	High    *float64       `json:"high,omitempty"`
+	Trend   string         `json:"trend,omitempty"`
+	History []MoodSnapshot `json:"history"`
+}
+
+// RecapMilestone is a milestone reached during a recap's week.
+type RecapMilestone struct {
+	Key         string    `json:"key"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	AchievedAt  time.Time `json:"achieved_at"`
+}
+
+// RecapPair is a relationship due a weekly recap for the week starting
+// WeekStart, with the number of messages exchanged that week.
+type RecapPair struct {
+	UserID      uuid.UUID
+	CompanionID uuid.UUID
+	Timezone    string
+	WeekStart   string
+	Messages    int
+}
+
+// RecapQuery selects a page of a relationship's recaps.
+type RecapQuery struct {
+	Cursor string
+	Limit  int
+}
+
+// RecapKey is a recap's position in a relationship's newest-first archive.
+type RecapKey struct {
+	WeekStart string `json:"w"`
+}
+
+// RecapPage is a page of recaps, newest week first.
+type RecapPage struct {
+	Recaps     []WeeklyRecap `json:"recaps"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	HasMore    bool          `json:"has_more"`
+}
+
+// WeeklyRecapEvent is the data of a weekly_recap notification.
+type WeeklyRecapEvent struct {
+	RecapID     uuid.UUID `json:"recap_id"`
+	CompanionID uuid.UUID `json:"companion_id"`
+	WeekStart   string    `json:"week_start"`
+}
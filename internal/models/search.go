@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Search result types.
+const (
+	SearchTypeMessage = "message"
+	SearchTypeMemory  = "memory"
+)
+
+// SearchFilter narrows a full-text search over the user's messages and
+// memories. Query uses web search syntax: quoted phrases, OR and -word.
+type SearchFilter struct {
+	Query       string
+	Type        string // "", SearchTypeMessage or SearchTypeMemory
+	CompanionID *uuid.UUID
+	From        *time.Time // inclusive
+	To          *time.Time // exclusive
+}
+
+// SearchQuery is a SearchFilter plus paging.
+type SearchQuery struct {
+	SearchFilter
+	Cursor string
+	Limit  int
+}
+
+// SearchKey is a result's position in the newest-first search order.
+type SearchKey struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// SearchResult is one matching message or memory.
+type SearchResult struct {
+	Type          string    `json:"type"`
+	ID            uuid.UUID `json:"id"`
+	CompanionID   uuid.UUID `json:"companion_id"`
+	CompanionName string    `json:"companion_name"`
+	Role          *string   `json:"role,omitempty"` // messages only
+	CreatedAt     time.Time `json:"created_at"`
+	Rank          float64   `json:"rank"`
+
+	// Snippet is an HTML-escaped excerpt with matches wrapped in <mark>.
+	Snippet string `json:"snippet"`
+
+	// MessageID is the message to jump to: the message itself, or the
+	// message a memory was saved from. Around is a cursor for it, to pass
+	// as around= to GET /api/companions/{id}/messages.
+	MessageID *uuid.UUID `json:"message_id,omitempty"`
+	Around    string     `json:"around,omitempty"`
+
+	// MessageCreatedAt positions MessageID in its conversation.
+	MessageCreatedAt *time.Time `json:"-"`
+}
+
+// SearchPage is a page of search results, newest first.
+type SearchPage struct {
+	Results    []SearchResult `json:"results"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	HasMore    bool           `json:"has_more"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session revocation reasons.
+const (
+	SessionRevokedLogout = "logout"  // the user logged out of it
+	SessionRevokedByUser = "revoked" // revoked from another session
+	SessionRevokedReuse  = "reuse"   // a rotated refresh token was presented again
+)
+
+// Session is a login on one device: the chain of refresh tokens issued
+// since, and the device it was last used from. Current marks the session
+// the request was made with.
+type Session struct {
+	ID            uuid.UUID  `json:"id"`
+	UserID        uuid.UUID  `json:"-"`
+	UserAgent     string     `json:"user_agent"`
+	IP            string     `json:"ip"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastUsedAt    time.Time  `json:"last_used_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RevokedAt     *time.Time `json:"-"`
+	RevokedReason *string    `json:"-"`
+	Current       bool       `json:"current"`
+}
+
+// DeviceInfo describes the client a session is used from.
+type DeviceInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// RefreshRequest is the payload for exchanging a refresh token.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
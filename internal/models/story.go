@@ -13,17 +13,101 @@ type Story struct {
 	CreatedAt   time.Time    `json:"created_at"`
 	ExpiresAt   time.Time    `json:"expires_at"`
 	Media       []StoryMedia `json:"media,omitempty"`
+
+	// Highlighted is set when the story belongs to at least one highlight.
+	Highlighted bool `json:"highlighted,omitempty"`
+
+	// SavedAt is set when listing the user's saved stories.
+	SavedAt *time.Time `json:"saved_at,omitempty"`
+}
+
+// StoryListMode selects which of a companion's stories are listed.
+type StoryListMode int
+
+const (
+	// StoryListActive lists only unexpired stories.
+	StoryListActive StoryListMode = iota
+	// StoryListWithHighlights also lists expired stories kept in a highlight.
+	StoryListWithHighlights
+)
+
+// StoryHighlight is a companion-level collection of stories that stays
+// visible after the stories expire.
+type StoryHighlight struct {
+	ID          uuid.UUID `json:"id"`
+	CompanionID uuid.UUID `json:"companion_id"`
+	Title       string    `json:"title"`
+	CoverURL    *string   `json:"cover_url,omitempty"`
+	SortOrder   int       `json:"sort_order"`
+	CreatedAt   time.Time `json:"created_at"`
+	Stories     []Story   `json:"stories"`
+}
+
+// CreateHighlightRequest is the payload for creating a highlight.
+type CreateHighlightRequest struct {
+	Title     string  `json:"title"`
+	CoverURL  *string `json:"cover_url,omitempty"`
+	SortOrder int     `json:"sort_order"`
 }
 
+// AddHighlightStoryRequest is the payload for adding a story to a highlight.
+type AddHighlightStoryRequest struct {
+	StoryID uuid.UUID `json:"story_id"`
+}
+
+// Story slide media types.
+const (
+	MediaTypeImage = "image"
+	MediaTypeVideo = "video"
+	MediaTypeText  = "text"
+)
+
 // StoryMedia represents a single slide within a story.
 type StoryMedia struct {
 	ID        uuid.UUID `json:"id"`
 	StoryID   uuid.UUID `json:"story_id"`
-	MediaURL  string    `json:"media_url"`
-	MediaType string    `json:"media_type"` // "image" or "video"
+	MediaURL  string    `json:"media_url"`  // empty for text slides
+	MediaType string    `json:"media_type"` // "image", "video" or "text"
 	Duration  int       `json:"duration"`   // display duration in seconds
 	SortOrder int       `json:"sort_order"`
 	CreatedAt time.Time `json:"created_at"`
+
+	ThumbnailURL *string `json:"thumbnail_url,omitempty"`
+	Caption      *string `json:"caption,omitempty"` // body text for text slides
+	LinkURL      *string `json:"link_url,omitempty"`
+	LinkLabel    *string `json:"link_label,omitempty"`
+
+	Background *StoryBackground `json:"background,omitempty"` // text slides only
+}
+
+// StoryBackground styles a text slide.
+type StoryBackground struct {
+	Color     string   `json:"color,omitempty"`    // solid fill, e.g. "#1e1b4b"
+	Gradient  []string `json:"gradient,omitempty"` // two or more colour stops; overrides Color
+	TextColor string   `json:"text_color,omitempty"`
+	Font      string   `json:"font,omitempty"` // font family hint for clients
+}
+
+// StoryInteraction is a user's reply or reaction to a story slide, as shown
+// to the chat model so the companion knows what the user is responding to.
+type StoryInteraction struct {
+	MediaType string    `json:"media_type"`
+	Caption   *string   `json:"caption,omitempty"`
+	Reaction  string    `json:"reaction,omitempty"` // empty for replies
+	At        time.Time `json:"at"`
+
+	// From the reaction catalogue; the label falls back to the key.
+	ReactionLabel string  `json:"reaction_label,omitempty"`
+	ReactionEmoji *string `json:"reaction_emoji,omitempty"`
+}
+
+// CreateTextSlideRequest is the payload for adding a text-only slide to a story.
+type CreateTextSlideRequest struct {
+	Caption    string           `json:"caption"`
+	Background *StoryBackground `json:"background,omitempty"`
+	LinkURL    *string          `json:"link_url,omitempty"`
+	LinkLabel  *string          `json:"link_label,omitempty"`
+	Duration   int              `json:"duration"`
 }
 
 // StoryReaction represents a user's emoji reaction to a story slide.
@@ -32,8 +116,13 @@ type StoryReaction struct {
 	UserID    uuid.UUID `json:"user_id"`
 	StoryID   uuid.UUID `json:"story_id"`
 	MediaID   uuid.UUID `json:"media_id"`
-	Reaction  string    `json:"reaction"` // "love", "sad", "heart_eyes", "angry"
+	Reaction  string    `json:"reaction"` // a reaction_types key
 	CreatedAt time.Time `json:"created_at"`
+
+	// Effect applied to the relationship state, reversed when the reaction
+	// is removed or replaced.
+	MoodDelta         float64 `json:"mood_delta"`
+	RelationshipDelta float64 `json:"relationship_delta"`
 }
 
 // CompanionStoryGroup groups all active stories for a single companion.
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Story draft statuses.
+const (
+	DraftPending   = "pending"
+	DraftPublished = "published"
+	DraftRejected  = "rejected"
+)
+
+// CompanionAsset is a media file in a companion's library that generated
+// stories can use.
+type CompanionAsset struct {
+	ID           uuid.UUID  `json:"id"`
+	CompanionID  uuid.UUID  `json:"companion_id"`
+	MediaURL     string     `json:"media_url"`
+	MediaType    string     `json:"media_type"` // "image" or "video"
+	ThumbnailURL *string    `json:"thumbnail_url,omitempty"`
+	Description  string     `json:"description"`
+	Tags         []string   `json:"tags"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// StoryDraft is an AI-generated story awaiting (or past) publication.
+type StoryDraft struct {
+	ID          uuid.UUID        `json:"id"`
+	CompanionID uuid.UUID        `json:"companion_id"`
+	Caption     string           `json:"caption"`
+	AssetIDs    []uuid.UUID      `json:"asset_ids"`
+	Assets      []CompanionAsset `json:"assets,omitempty"`
+	Status      string           `json:"status"`
+	StoryID     *uuid.UUID       `json:"story_id,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ReviewedAt  *time.Time       `json:"reviewed_at,omitempty"`
+}
@@ -12,15 +12,37 @@ type User struct {
 	Email     string    `json:"email"`
 	Password  string    `json:"-"`
 	Name      string    `json:"name"`
+	Timezone  string    `json:"timezone"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-// SignupRequest is the payload for user registration.
+// Location returns the user's time zone, or UTC if it is unset or unknown.
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// SignupRequest is the payload for user registration. Timezone is an IANA
+// name such as "Asia/Ho_Chi_Minh" and defaults to UTC.
 type SignupRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 	Name     string `json:"name"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// UpdateUserRequest is the payload for updating the current user's profile.
+// Omitted fields are left unchanged.
+type UpdateUserRequest struct {
+	Name     *string `json:"name,omitempty"`
+	Timezone *string `json:"timezone,omitempty"`
 }
 
 // LoginRequest is the payload for user login.
@@ -29,8 +51,13 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
-// AuthResponse is returned after successful authentication.
+// AuthResponse is returned after successful authentication and refresh.
+// Token is the access token, valid for ExpiresIn seconds; RefreshToken is
+// single use and is exchanged for the next pair at /api/auth/refresh.
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string    `json:"token"`
+	ExpiresIn    int       `json:"expires_in"`
+	RefreshToken string    `json:"refresh_token"`
+	SessionID    uuid.UUID `json:"session_id"`
+	User         User      `json:"user"`
 }
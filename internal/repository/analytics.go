@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// StoryAnalyticsRepository defines data access operations for story view
+// events and the analytics rollups built from them.
+type StoryAnalyticsRepository interface {
+	RecordView(ctx context.Context, userID, storyID uuid.UUID, req models.RecordStoryViewRequest) error
+
+	// Rollup rebuilds the rollups for every UTC day from since onwards.
+	Rollup(ctx context.Context, since time.Time) error
+	DeleteViewsBefore(ctx context.Context, before time.Time) (int64, error)
+
+	GetStoryStats(ctx context.Context, filter models.AnalyticsFilter) ([]models.StoryStats, error)
+	GetMediaStats(ctx context.Context, storyID uuid.UUID, filter models.AnalyticsFilter) ([]models.StoryMediaStats, error)
+}
+
+type storyAnalyticsRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewStoryAnalyticsRepository creates a new StoryAnalyticsRepository backed by PostgreSQL.
+func NewStoryAnalyticsRepository(pool *pgxpool.Pool) StoryAnalyticsRepository {
+	return &storyAnalyticsRepo{pool: pool}
+}
+
+// maxStoryStatsRows caps the per-story report.
+const maxStoryStatsRows = 500
+
+func (r *storyAnalyticsRepo) RecordView(ctx context.Context, userID, storyID uuid.UUID, req models.RecordStoryViewRequest) error {
+	query := `
+		INSERT INTO story_views (user_id, story_id, media_id, completed, watched_ms, viewed_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())`
+
+	if _, err := r.pool.Exec(ctx, query, userID, storyID, req.MediaID, req.Completed, req.WatchedMS); err != nil {
+		return fmt.Errorf("recording story view: %w", err)
+	}
+	return nil
+}
+
+// Rollup recomputes the rollups for recent days inside one transaction.
+// Rows for slides that no longer exist (the story was cleaned up) are left
+// as they are, since their raw events and reactions have been deleted.
+func (r *storyAnalyticsRepo) Rollup(ctx context.Context, since time.Time) error {
+	since = since.UTC().Truncate(24 * time.Hour)
+	day := since.Format(time.DateOnly)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning rollup: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	viewers := `
+		INSERT INTO story_media_viewers (media_id, user_id, day, story_id, companion_id, views, completed)
+		SELECT v.media_id, v.user_id, (v.viewed_at AT TIME ZONE 'UTC')::date, v.story_id, s.companion_id,
+		       COUNT(*), bool_or(v.completed)
+		FROM story_views v
+		JOIN stories s ON s.id = v.story_id
+		WHERE v.viewed_at >= $1
+		GROUP BY 1, 2, 3, 4, 5
+		ON CONFLICT (media_id, day, user_id) DO UPDATE
+		SET views = EXCLUDED.views, completed = EXCLUDED.completed`
+	if _, err := tx.Exec(ctx, viewers, since); err != nil {
+		return fmt.Errorf("rolling up story viewers: %w", err)
+	}
+
+	clearReactions := `
+		DELETE FROM story_media_reaction_stats rs
+		WHERE rs.day >= $1::date
+		  AND EXISTS (SELECT 1 FROM story_media sm WHERE sm.id = rs.media_id)`
+	if _, err := tx.Exec(ctx, clearReactions, day); err != nil {
+		return fmt.Errorf("clearing reaction stats: %w", err)
+	}
+
+	reactions := `
+		INSERT INTO story_media_reaction_stats (media_id, day, reaction, story_id, companion_id, count)
+		SELECT sr.media_id, (sr.created_at AT TIME ZONE 'UTC')::date, sr.reaction, sr.story_id, s.companion_id, COUNT(*)
+		FROM story_reactions sr
+		JOIN stories s ON s.id = sr.story_id
+		WHERE sr.created_at >= $1
+		GROUP BY 1, 2, 3, 4, 5`
+	if _, err := tx.Exec(ctx, reactions, since); err != nil {
+		return fmt.Errorf("rolling up reaction stats: %w", err)
+	}
+
+	clearDaily := `
+		DELETE FROM story_media_daily_stats ds
+		WHERE ds.day >= $1::date
+		  AND EXISTS (SELECT 1 FROM story_media sm WHERE sm.id = ds.media_id)`
+	if _, err := tx.Exec(ctx, clearDaily, day); err != nil {
+		return fmt.Errorf("clearing daily stats: %w", err)
+	}
+
+	daily := `
+		WITH v AS (
+			SELECT media_id, day, SUM(views) AS views, COUNT(*) AS viewers,
+			       COUNT(*) FILTER (WHERE completed) AS completions
+			FROM story_media_viewers
+			WHERE day >= $2::date
+			GROUP BY media_id, day
+		), rx AS (
+			SELECT media_id, (created_at AT TIME ZONE 'UTC')::date AS day, COUNT(*) AS reactions,
+			       SUM(mood_delta) AS mood_delta, SUM(relationship_delta) AS relationship_delta
+			FROM story_reactions
+			WHERE created_at >= $1
+			GROUP BY 1, 2
+		), rp AS (
+			SELECT story_media_id AS media_id, (created_at AT TIME ZONE 'UTC')::date AS day, COUNT(*) AS replies
+			FROM messages
+			WHERE story_media_id IS NOT NULL AND role = 'user' AND created_at >= $1
+			GROUP BY 1, 2
+		), k AS (
+			SELECT media_id, day FROM v
+			UNION SELECT media_id, day FROM rx
+			UNION SELECT media_id, day FROM rp
+		)
+		INSERT INTO story_media_daily_stats
+			(media_id, day, story_id, companion_id, views, viewers, completions,
+			 reactions, replies, mood_delta, relationship_delta)
+		SELECT k.media_id, k.day, sm.story_id, s.companion_id,
+		       COALESCE(v.views, 0), COALESCE(v.viewers, 0), COALESCE(v.completions, 0),
+		       COALESCE(rx.reactions, 0), COALESCE(rp.replies, 0),
+		       COALESCE(rx.mood_delta, 0), COALESCE(rx.relationship_delta, 0)
+		FROM k
+		JOIN story_media sm ON sm.id = k.media_id
+		JOIN stories s ON s.id = sm.story_id
+		LEFT JOIN v ON v.media_id = k.media_id AND v.day = k.day
+		LEFT JOIN rx ON rx.media_id = k.media_id AND rx.day = k.day
+		LEFT JOIN rp ON rp.media_id = k.media_id AND rp.day = k.day
+		ON CONFLICT (media_id, day) DO NOTHING`
+	if _, err := tx.Exec(ctx, daily, since, day); err != nil {
+		return fmt.Errorf("rolling up daily stats: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing rollup: %w", err)
+	}
+	return nil
+}
+
+func (r *storyAnalyticsRepo) DeleteViewsBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM story_views WHERE viewed_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("pruning story views: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// GetStoryStats returns per-story figures for the filter, most viewed first.
+func (r *storyAnalyticsRepo) GetStoryStats(ctx context.Context, filter models.AnalyticsFilter) ([]models.StoryStats, error) {
+	from, to, companion := filterArgs(filter)
+
+	query := `
+		WITH d AS (
+			SELECT story_id, companion_id, COUNT(DISTINCT media_id) AS slides,
+			       SUM(views) AS views, SUM(replies) AS replies,
+			       SUM(mood_delta) AS mood_delta, SUM(relationship_delta) AS relationship_delta
+			FROM story_media_daily_stats
+			WHERE day BETWEEN $1::date AND $2::date AND ($3::uuid IS NULL OR companion_id = $3::uuid)
+			GROUP BY story_id, companion_id
+		), v AS (
+			SELECT story_id, user_id, COUNT(DISTINCT media_id) FILTER (WHERE completed) AS completed_slides
+			FROM story_media_viewers
+			WHERE day BETWEEN $1::date AND $2::date AND ($3::uuid IS NULL OR companion_id = $3::uuid)
+			GROUP BY story_id, user_id
+		), n AS (
+			-- A story's slides, or the ones ever viewed once it has expired.
+			SELECT d.story_id, COALESCE(
+			           NULLIF((SELECT COUNT(*) FROM story_media sm WHERE sm.story_id = d.story_id), 0),
+			           (SELECT COUNT(DISTINCT media_id) FROM story_media_viewers mv WHERE mv.story_id = d.story_id)
+			       ) AS slides
+			FROM d
+		), u AS (
+			SELECT v.story_id, COUNT(*) AS viewers,
+			       COUNT(*) FILTER (WHERE v.completed_slides >= n.slides) AS completions
+			FROM v
+			JOIN n ON n.story_id = v.story_id
+			GROUP BY v.story_id
+		)
+		SELECT d.story_id, d.companion_id, d.slides, d.views,
+		       COALESCE(u.viewers, 0), COALESCE(u.completions, 0),
+		       d.replies, d.mood_delta, d.relationship_delta
+		FROM d
+		LEFT JOIN u ON u.story_id = d.story_id
+		ORDER BY d.views DESC, d.story_id
+		LIMIT $4`
+
+	rows, err := r.pool.Query(ctx, query, from, to, companion, maxStoryStatsRows)
+	if err != nil {
+		return nil, fmt.Errorf("querying story stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.StoryStats{}
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var s models.StoryStats
+		if err := rows.Scan(&s.StoryID, &s.CompanionID, &s.Slides, &s.Views,
+			&s.UniqueViewers, &s.Completions, &s.Replies, &s.MoodDelta, &s.RelationshipDelta); err != nil {
+			return nil, fmt.Errorf("scanning story stats: %w", err)
+		}
+		s.CompletionRate = rate(s.Completions, s.UniqueViewers)
+		s.Reactions = map[string]int{}
+		index[s.StoryID] = len(stats)
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	reactionsQuery := `
+		SELECT story_id, reaction, SUM(count)
+		FROM story_media_reaction_stats
+		WHERE day BETWEEN $1::date AND $2::date AND ($3::uuid IS NULL OR companion_id = $3::uuid)
+		GROUP BY story_id, reaction`
+
+	err = r.scanReactions(ctx, reactionsQuery, func(id uuid.UUID, reaction string, n int) {
+		if i, ok := index[id]; ok {
+			stats[i].Reactions[reaction] = n
+		}
+	}, from, to, companion)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetMediaStats returns per-slide figures for one story in slide order.
+func (r *storyAnalyticsRepo) GetMediaStats(ctx context.Context, storyID uuid.UUID, filter models.AnalyticsFilter) ([]models.StoryMediaStats, error) {
+	from, to, _ := filterArgs(filter)
+
+	query := `
+		WITH d AS (
+			SELECT media_id, SUM(views) AS views, SUM(replies) AS replies,
+			       SUM(mood_delta) AS mood_delta, SUM(relationship_delta) AS relationship_delta
+			FROM story_media_daily_stats
+			WHERE story_id = $3 AND day BETWEEN $1::date AND $2::date
+			GROUP BY media_id
+		), u AS (
+			SELECT media_id, COUNT(DISTINCT user_id) AS viewers,
+			       COUNT(DISTINCT user_id) FILTER (WHERE completed) AS completions
+			FROM story_media_viewers
+			WHERE story_id = $3 AND day BETWEEN $1::date AND $2::date
+			GROUP BY media_id
+		)
+		SELECT d.media_id, sm.media_type, sm.caption, sm.sort_order, d.views,
+		       COALESCE(u.viewers, 0), COALESCE(u.completions, 0),
+		       d.replies, d.mood_delta, d.relationship_delta
+		FROM d
+		LEFT JOIN u ON u.media_id = d.media_id
+		LEFT JOIN story_media sm ON sm.id = d.media_id
+		ORDER BY sm.sort_order NULLS LAST, d.media_id`
+
+	rows, err := r.pool.Query(ctx, query, from, to, storyID)
+	if err != nil {
+		return nil, fmt.Errorf("querying story media stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.StoryMediaStats{}
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		s := models.StoryMediaStats{StoryID: storyID}
+		if err := rows.Scan(&s.MediaID, &s.MediaType, &s.Caption, &s.SortOrder, &s.Views,
+			&s.UniqueViewers, &s.Completions, &s.Replies, &s.MoodDelta, &s.RelationshipDelta); err != nil {
+			return nil, fmt.Errorf("scanning story media stats: %w", err)
+		}
+		s.CompletionRate = rate(s.Completions, s.UniqueViewers)
+		s.Reactions = map[string]int{}
+		index[s.MediaID] = len(stats)
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	reactionsQuery := `
+		SELECT media_id, reaction, SUM(count)
+		FROM story_media_reaction_stats
+		WHERE story_id = $3 AND day BETWEEN $1::date AND $2::date
+		GROUP BY media_id, reaction`
+
+	err = r.scanReactions(ctx, reactionsQuery, func(id uuid.UUID, reaction string, n int) {
+		if i, ok := index[id]; ok {
+			stats[i].Reactions[reaction] = n
+		}
+	}, from, to, storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// scanReactions runs a (id, reaction, count) query and passes each row to add.
+func (r *storyAnalyticsRepo) scanReactions(ctx context.Context, query string, add func(uuid.UUID, string, int), args ...any) error {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("querying reaction stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var reaction string
+		var n int
+		if err := rows.Scan(&id, &reaction, &n); err != nil {
+			return fmt.Errorf("scanning reaction stats: %w", err)
+		}
+		add(id, reaction, n)
+	}
+	return rows.Err()
+}
+
+// filterArgs converts a filter to query arguments: UTC dates as strings and
+// the companion as a nullable string, both safe under the simple protocol.
+func filterArgs(f models.AnalyticsFilter) (from, to string, companion *string) {
+	from = f.From.UTC().Format(time.DateOnly)
+	to = f.To.UTC().Format(time.DateOnly)
+	if f.CompanionID != nil {
+		s := f.CompanionID.String()
+		companion = &s
+	}
+	return from, to, companion
+}
+
+func rate(n, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return float64(n) / float64(of)
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// AssetRepository defines data access operations for companion asset libraries.
+type AssetRepository interface {
+	Create(ctx context.Context, asset *models.CompanionAsset) error
+	GetByCompanion(ctx context.Context, companionID uuid.UUID) ([]models.CompanionAsset, error)
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]models.CompanionAsset, error)
+	MarkUsed(ctx context.Context, ids []uuid.UUID) error
+}
+
+type assetRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewAssetRepository creates a new AssetRepository backed by PostgreSQL.
+func NewAssetRepository(pool *pgxpool.Pool) AssetRepository {
+	return &assetRepo{pool: pool}
+}
+
+func (r *assetRepo) Create(ctx context.Context, asset *models.CompanionAsset) error {
+	query := `
+		INSERT INTO companion_assets (id, companion_id, media_url, media_type, thumbnail_url, description, tags, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at`
+
+	if asset.Tags == nil {
+		asset.Tags = []string{}
+	}
+
+	return r.pool.QueryRow(ctx, query,
+		asset.ID, asset.CompanionID, asset.MediaURL, asset.MediaType, asset.ThumbnailURL, asset.Description, asset.Tags,
+	).Scan(&asset.CreatedAt)
+}
+
+// GetByCompanion returns a companion's assets, least recently used first.
+func (r *assetRepo) GetByCompanion(ctx context.Context, companionID uuid.UUID) ([]models.CompanionAsset, error) {
+	query := `
+		SELECT id, companion_id, media_url, media_type, thumbnail_url, description, tags, last_used_at, created_at
+		FROM companion_assets
+		WHERE companion_id = $1
+		ORDER BY last_used_at ASC NULLS FIRST, created_at ASC`
+
+	return r.query(ctx, query, companionID)
+}
+
+func (r *assetRepo) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]models.CompanionAsset, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, companion_id, media_url, media_type, thumbnail_url, description, tags, last_used_at, created_at
+		FROM companion_assets
+		WHERE id = ANY($1::uuid[])`
+
+	assets, err := r.query(ctx, query, uuidStrings(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	// Preserve the caller's order (slide order).
+	byID := make(map[uuid.UUID]models.CompanionAsset, len(assets))
+	for _, a := range assets {
+		byID[a.ID] = a
+	}
+	ordered := make([]models.CompanionAsset, 0, len(assets))
+	for _, id := range ids {
+		if a, ok := byID[id]; ok {
+			ordered = append(ordered, a)
+		}
+	}
+	return ordered, nil
+}
+
+func (r *assetRepo) MarkUsed(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `UPDATE companion_assets SET last_used_at = NOW() WHERE id = ANY($1::uuid[])`
+
+	if _, err := r.pool.Exec(ctx, query, uuidStrings(ids)); err != nil {
+		return fmt.Errorf("marking assets used: %w", err)
+	}
+	return nil
+}
+
+func (r *assetRepo) query(ctx context.Context, query string, args ...any) ([]models.CompanionAsset, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying assets: %w", err)
+	}
+	defer rows.Close()
+
+	var assets []models.CompanionAsset
+	for rows.Next() {
+		var a models.CompanionAsset
+		if err := rows.Scan(&a.ID, &a.CompanionID, &a.MediaURL, &a.MediaType, &a.ThumbnailURL,
+			&a.Description, &a.Tags, &a.LastUsedAt, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning asset: %w", err)
+		}
+		assets = append(assets, a)
+	}
+
+	return assets, rows.Err()
+}
+
+// uuidStrings converts ids to strings for `$n::uuid[]` parameters, which
+// keeps array arguments compatible with PgBouncer's simple protocol.
+func uuidStrings(ids []uuid.UUID) []string {
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = id.String()
+	}
+	return out
+}
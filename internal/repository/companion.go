@@ -15,6 +15,7 @@ import (
 type CompanionRepository interface {
 	GetAll(ctx context.Context) ([]models.Companion, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Companion, error)
+	UpdateAvatar(ctx context.Context, id uuid.UUID, avatarURL string, thumbnailURL *string) (*models.Companion, error)
 }
 
 type companionRepo struct {
@@ -27,7 +28,7 @@ func NewCompanionRepository(pool *pgxpool.Pool) CompanionRepository {
 }
 
 func (r *companionRepo) GetAll(ctx context.Context) ([]models.Companion, error) {
-	query := `SELECT id, name, description, avatar_url, personality, created_at FROM companions ORDER BY name`
+	query := `SELECT id, name, description, avatar_url, personality, created_at, avatar_thumbnail_url FROM companions ORDER BY name`
 
 	rows, err := r.pool.Query(ctx, query)
 	if err != nil {
@@ -38,7 +39,7 @@ func (r *companionRepo) GetAll(ctx context.Context) ([]models.Companion, error)
 	var companions []models.Companion
 	for rows.Next() {
 		var c models.Companion
-		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.AvatarURL, &c.Personality, &c.CreatedAt); err != nil {
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.AvatarURL, &c.Personality, &c.CreatedAt, &c.AvatarThumbnailURL); err != nil {
 			return nil, fmt.Errorf("scanning companion: %w", err)
 		}
 		companions = append(companions, c)
@@ -48,11 +49,11 @@ func (r *companionRepo) GetAll(ctx context.Context) ([]models.Companion, error)
 }
 
 func (r *companionRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Companion, error) {
-	query := `SELECT id, name, description, avatar_url, personality, created_at FROM companions WHERE id = $1`
+	query := `SELECT id, name, description, avatar_url, personality, created_at, avatar_thumbnail_url FROM companions WHERE id = $1`
 
 	var c models.Companion
 	err := r.pool.QueryRow(ctx, query, id).
-		Scan(&c.ID, &c.Name, &c.Description, &c.AvatarURL, &c.Personality, &c.CreatedAt)
+		Scan(&c.ID, &c.Name, &c.Description, &c.AvatarURL, &c.Personality, &c.CreatedAt, &c.AvatarThumbnailURL)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("companion not found")
@@ -61,3 +62,21 @@ func (r *companionRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Comp
 	}
 	return &c, nil
 }
+
+func (r *companionRepo) UpdateAvatar(ctx context.Context, id uuid.UUID, avatarURL string, thumbnailURL *string) (*models.Companion, error) {
+	query := `
+		UPDATE companions SET avatar_url = $2, avatar_thumbnail_url = $3
+		WHERE id = $1
+		RETURNING id, name, description, avatar_url, personality, created_at, avatar_thumbnail_url`
+
+	var c models.Companion
+	err := r.pool.QueryRow(ctx, query, id, avatarURL, thumbnailURL).
+		Scan(&c.ID, &c.Name, &c.Description, &c.AvatarURL, &c.Personality, &c.CreatedAt, &c.AvatarThumbnailURL)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("companion not found")
+		}
+		return nil, fmt.Errorf("updating companion avatar: %w", err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// ConversationRepository defines data access operations for conversation
+// analytics snapshots.
+type ConversationRepository interface {
+	GetActivePairs(ctx context.Context, since time.Time) ([]models.ConversationPair, error)
+	SaveSnapshot(ctx context.Context, snap *models.ConversationSnapshot) error
+	GetSnapshots(ctx context.Context, userID, companionID uuid.UUID, limit int) ([]models.ConversationSnapshot, error)
+}
+
+type conversationRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewConversationRepository creates a new ConversationRepository backed by PostgreSQL.
+func NewConversationRepository(pool *pgxpool.Pool) ConversationRepository {
+	return &conversationRepo{pool: pool}
+}
+
+// GetActivePairs returns the relationships in which the user has sent a
+// message since since, with the user's time zone.
+func (r *conversationRepo) GetActivePairs(ctx context.Context, since time.Time) ([]models.ConversationPair, error) {
+	query := `
+		SELECT rs.user_id, rs.companion_id, u.timezone
+		FROM relationship_states rs
+		JOIN users u ON u.id = rs.user_id
+		WHERE EXISTS (
+			SELECT 1 FROM messages m
+			WHERE m.user_id = rs.user_id AND m.companion_id = rs.companion_id
+			  AND m.role = 'user' AND m.created_at >= $1
+		)
+		ORDER BY rs.user_id, rs.companion_id`
+
+	rows, err := r.pool.Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying active conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []models.ConversationPair
+	for rows.Next() {
+		var p models.ConversationPair
+		if err := rows.Scan(&p.UserID, &p.CompanionID, &p.Timezone); err != nil {
+			return nil, fmt.Errorf("scanning active conversation: %w", err)
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, rows.Err()
+}
+
+// SaveSnapshot stores a snapshot, replacing the pair's snapshot for the
+// same date.
+func (r *conversationRepo) SaveSnapshot(ctx context.Context, snap *models.ConversationSnapshot) error {
+	// The jsonb columns are sent as text; simple-protocol mode can't infer
+	// jsonb from a slice.
+	hours, err := json.Marshal(snap.ActiveHours)
+	if err != nil {
+		return fmt.Errorf("encoding active hours: %w", err)
+	}
+	trend, err := json.Marshal(snap.SentimentTrend)
+	if err != nil {
+		return fmt.Errorf("encoding sentiment trend: %w", err)
+	}
+	topics, err := json.Marshal(snap.Topics)
+	if err != nil {
+		return fmt.Errorf("encoding topics: %w", err)
+	}
+
+	query := `
+		INSERT INTO conversation_snapshots (
+			id, user_id, companion_id, snapshot_date, window_start, window_end,
+			user_messages, companion_messages, avg_user_length, avg_companion_length,
+			replies, reply_median_seconds, reply_p90_seconds, active_hours,
+			sentiment, tone, sentiment_trend, topics, created_at
+		)
+		VALUES ($1, $2, $3, $4::date, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14::jsonb, $15, $16, $17::jsonb, $18::jsonb, NOW())
+		ON CONFLICT (user_id, companion_id, snapshot_date) DO UPDATE SET
+			window_start = EXCLUDED.window_start, window_end = EXCLUDED.window_end,
+			user_messages = EXCLUDED.user_messages, companion_messages = EXCLUDED.companion_messages,
+			avg_user_length = EXCLUDED.avg_user_length, avg_companion_length = EXCLUDED.avg_companion_length,
+			replies = EXCLUDED.replies, reply_median_seconds = EXCLUDED.reply_median_seconds,
+			reply_p90_seconds = EXCLUDED.reply_p90_seconds, active_hours = EXCLUDED.active_hours,
+			sentiment = EXCLUDED.sentiment, tone = EXCLUDED.tone,
+			sentiment_trend = EXCLUDED.sentiment_trend, topics = EXCLUDED.topics, created_at = NOW()
+		RETURNING id, created_at`
+
+	err = r.pool.QueryRow(ctx, query,
+		snap.ID, snap.UserID, snap.CompanionID, snap.SnapshotDate, snap.WindowStart, snap.WindowEnd,
+		snap.UserMessages, snap.CompanionMessages, snap.AvgUserLength, snap.AvgCompanionLength,
+		snap.Replies, snap.ReplyMedianSeconds, snap.ReplyP90Seconds, string(hours),
+		snap.Sentiment, snap.Tone, string(trend), string(topics),
+	).Scan(&snap.ID, &snap.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("saving conversation snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetSnapshots returns up to limit of the pair's snapshots, newest first.
+func (r *conversationRepo) GetSnapshots(ctx context.Context, userID, companionID uuid.UUID, limit int) ([]models.ConversationSnapshot, error) {
+	query := `
+		SELECT id, user_id, companion_id, snapshot_date, window_start, window_end,
+		       user_messages, companion_messages, avg_user_length::float8, avg_companion_length::float8,
+		       replies, reply_median_seconds::float8, reply_p90_seconds::float8, active_hours,
+		       sentiment::float8, tone, sentiment_trend, topics, created_at
+		FROM conversation_snapshots
+		WHERE user_id = $1 AND companion_id = $2
+		ORDER BY snapshot_date DESC
+		LIMIT $3`
+
+	rows, err := r.pool.Query(ctx, query, userID, companionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying conversation snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []models.ConversationSnapshot{}
+	for rows.Next() {
+		snap, err := scanConversationSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, *snap)
+	}
+	return snapshots, rows.Err()
+}
+
+func scanConversationSnapshot(row pgx.Row) (*models.ConversationSnapshot, error) {
+	var s models.ConversationSnapshot
+	var date time.Time
+	var hours, trend, topics []byte
+	err := row.Scan(&s.ID, &s.UserID, &s.CompanionID, &date, &s.WindowStart, &s.WindowEnd,
+		&s.UserMessages, &s.CompanionMessages, &s.AvgUserLength, &s.AvgCompanionLength,
+		&s.Replies, &s.ReplyMedianSeconds, &s.ReplyP90Seconds, &hours,
+		&s.Sentiment, &s.Tone, &trend, &topics, &s.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("scanning conversation snapshot: %w", err)
+	}
+	s.SnapshotDate = date.Format(time.DateOnly)
+
+	if err := json.Unmarshal(hours, &s.ActiveHours); err != nil {
+		return nil, fmt.Errorf("decoding active hours: %w", err)
+	}
+	if err := json.Unmarshal(trend, &s.SentimentTrend); err != nil {
+		return nil, fmt.Errorf("decoding sentiment trend: %w", err)
+	}
+	if err := json.Unmarshal(topics, &s.Topics); err != nil {
+		return nil, fmt.Errorf("decoding topics: %w", err)
+	}
+	s.PeakHours = models.PeakHours(s.ActiveHours, 3)
+	return &s, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/config"
+	"ai-companion-be/internal/database"
+)
+
+// Database tests run against the PostgreSQL database at TEST_DATABASE_URL,
+// which is migrated on first use, and are skipped without it. Each test
+// creates its own users and companions and deletes them when it finishes.
+
+var migrateOnce struct {
+	sync.Once
+	err error
+}
+
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	pool, err := database.NewPostgresPool(ctx, config.DatabaseConfig{URL: url})
+	if err != nil {
+		t.Fatalf("connecting to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	migrateOnce.Do(func() {
+		migrateOnce.err = database.RunMigrations(ctx, pool, "../../migrations")
+	})
+	if migrateOnce.err != nil {
+		t.Fatalf("migrating test database: %v", migrateOnce.err)
+	}
+	return pool
+}
+
+func createTestUser(t *testing.T, pool *pgxpool.Pool, timezone string) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+	id := uuid.New()
+	_, err := pool.Exec(ctx, `
+		INSERT INTO users (id, email, password, name, timezone)
+		VALUES ($1, $2, 'x', 'Test User', $3)`, id, id.String()+"@example.test", timezone)
+	if err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), `DELETE FROM users WHERE id = $1`, id)
+	})
+	return id
+}
+
+func createTestCompanion(t *testing.T, pool *pgxpool.Pool) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+	id := uuid.New()
+	if _, err := pool.Exec(ctx, `INSERT INTO companions (id, name) VALUES ($1, 'Test Companion')`, id); err != nil {
+		t.Fatalf("creating companion: %v", err)
+	}
+	t.Cleanup(func() {
+		pool.Exec(context.Background(), `DELETE FROM companions WHERE id = $1`, id)
+	})
+	return id
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// EmbeddingRepository defines data access operations for embeddings.
+type EmbeddingRepository interface {
+	GetPending(ctx context.Context, model string, limit int) ([]models.EmbeddingSource, error)
+	Save(ctx context.Context, model string, sources []models.EmbeddingSource, vectors [][]float32) error
+	Nearest(ctx context.Context, userID, companionID uuid.UUID, model string, vector []float32, before *time.Time, limit int) ([]models.EmbeddingMatch, error)
+	GetMessageVectors(ctx context.Context, model string, messageIDs []uuid.UUID) (map[uuid.UUID][]float32, error)
+}
+
+type embeddingRepo struct {
+	pool *pgxpool.Pool
+
+	// pgvector is detected on first use; see usePgvector.
+	detectMu sync.Mutex
+	detected bool
+	pgvector bool
+}
+
+// pgvectorDetectTimeout bounds the check for the pgvector extension.
+const pgvectorDetectTimeout = 5 * time.Second
+
+// NewEmbeddingRepository creates a new EmbeddingRepository backed by PostgreSQL.
+func NewEmbeddingRepository(pool *pgxpool.Pool) EmbeddingRepository {
+	return &embeddingRepo{pool: pool}
+}
+
+// GetPending returns up to limit messages and memories, newest first, that
+// have no embedding for model or whose memory text changed since it was
+// embedded. A memory's text is its content followed by its tag.
+func (r *embeddingRepo) GetPending(ctx context.Context, model string, limit int) ([]models.EmbeddingSource, error) {
+	query := `
+		(
+			SELECT 'message', m.id, m.user_id, m.companion_id, m.content, md5(m.content), m.created_at
+			FROM messages m
+			WHERE NOT EXISTS (SELECT 1 FROM embeddings e WHERE e.message_id = m.id AND e.model = $1)
+			ORDER BY m.created_at DESC
+			LIMIT $2
+		)
+		UNION ALL
+		(
+			SELECT 'memory', mem.id, mem.user_id, mem.companion_id, t.text, md5(t.text), mem.created_at
+			FROM memories mem
+			CROSS JOIN LATERAL (SELECT mem.content || coalesce(' #' || mem.tag, '') AS text) t
+			WHERE NOT EXISTS (
+				SELECT 1 FROM embeddings e
+				WHERE e.memory_id = mem.id AND e.model = $1 AND e.content_hash = md5(t.text)
+			)
+			ORDER BY mem.created_at DESC
+			LIMIT $2
+		)
+		ORDER BY 7 DESC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, model, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying pending embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []models.EmbeddingSource
+	for rows.Next() {
+		var s models.EmbeddingSource
+		var createdAt time.Time
+		if err := rows.Scan(&s.Type, &s.ID, &s.UserID, &s.CompanionID, &s.Text, &s.ContentHash, &createdAt); err != nil {
+			return nil, fmt.Errorf("scanning pending embedding: %w", err)
+		}
+		sources = append(sources, s)
+	}
+	return sources, rows.Err()
+}
+
+// Save upserts one embedding per source. vectors[i] belongs to sources[i].
+// Sources deleted since GetPending are skipped.
+func (r *embeddingRepo) Save(ctx context.Context, model string, sources []models.EmbeddingSource, vectors [][]float32) error {
+	if len(sources) != len(vectors) {
+		return fmt.Errorf("saving embeddings: %d sources but %d vectors", len(sources), len(vectors))
+	}
+
+	// Inserting from a SELECT on the source makes a concurrent delete a
+	// no-op rather than a foreign key violation.
+	saveMessage := `
+		INSERT INTO embeddings (user_id, companion_id, message_id, model, embedding, content_hash)
+		SELECT user_id, companion_id, id, $2, $3, $4 FROM messages WHERE id = $1
+		ON CONFLICT (message_id, model) DO UPDATE
+		SET embedding = EXCLUDED.embedding, content_hash = EXCLUDED.content_hash, created_at = NOW()`
+	saveMemory := `
+		INSERT INTO embeddings (user_id, companion_id, memory_id, model, embedding, content_hash)
+		SELECT user_id, companion_id, id, $2, $3, $4 FROM memories WHERE id = $1
+		ON CONFLICT (memory_id, model) DO UPDATE
+		SET embedding = EXCLUDED.embedding, content_hash = EXCLUDED.content_hash, created_at = NOW()`
+
+	batch := &pgx.Batch{}
+	for i, s := range sources {
+		query := saveMessage
+		if s.Type == models.EmbeddingSourceMemory {
+			query = saveMemory
+		}
+		batch.Queue(query, s.ID, model, vectors[i], s.ContentHash)
+	}
+	if err := r.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("saving embeddings: %w", err)
+	}
+	return nil
+}
+
+// Nearest returns up to limit of the pair's messages and memories embedded
+// with model, most similar to vector first. With before, only messages sent
+// before it are considered; memories always are.
+func (r *embeddingRepo) Nearest(ctx context.Context, userID, companionID uuid.UUID, model string, vector []float32, before *time.Time, limit int) ([]models.EmbeddingMatch, error) {
+	pgvector := r.usePgvector()
+
+	from := `
+		FROM embeddings e
+		LEFT JOIN messages m ON m.id = e.message_id
+		WHERE e.user_id = $1 AND e.companion_id = $2 AND e.model = $3
+		  AND (e.message_id IS NULL OR $4::timestamptz IS NULL OR m.created_at < $4::timestamptz)`
+
+	if pgvector {
+		query := `
+			SELECT CASE WHEN e.message_id IS NULL THEN 'memory' ELSE 'message' END,
+			       coalesce(e.message_id, e.memory_id),
+			       1 - (e.embedding::vector <=> $5::real[]::vector) AS similarity
+			` + from + `
+			ORDER BY e.embedding::vector <=> $5::real[]::vector
+			LIMIT $6`
+
+		rows, err := r.pool.Query(ctx, query, userID, companionID, model, before, vector, limit)
+		if err != nil {
+			return nil, fmt.Errorf("querying nearest embeddings: %w", err)
+		}
+		defer rows.Close()
+
+		var matches []models.EmbeddingMatch
+		for rows.Next() {
+			var m models.EmbeddingMatch
+			if err := rows.Scan(&m.Type, &m.ID, &m.Similarity); err != nil {
+				return nil, fmt.Errorf("scanning nearest embedding: %w", err)
+			}
+			if !math.IsNaN(m.Similarity) {
+				matches = append(matches, m)
+			}
+		}
+		return matches, rows.Err()
+	}
+
+	// Without pgvector, load the pair's vectors and rank them here. One
+	// pair's history is small enough for this to stay cheap.
+	query := `
+		SELECT CASE WHEN e.message_id IS NULL THEN 'memory' ELSE 'message' END,
+		       coalesce(e.message_id, e.memory_id), e.embedding
+		` + from
+
+	rows, err := r.pool.Query(ctx, query, userID, companionID, model, before)
+	if err != nil {
+		return nil, fmt.Errorf("querying embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.EmbeddingMatch
+	for rows.Next() {
+		var m models.EmbeddingMatch
+		var v []float32
+		if err := rows.Scan(&m.Type, &m.ID, &v); err != nil {
+			return nil, fmt.Errorf("scanning embedding: %w", err)
+		}
+		m.Similarity = cosine(vector, v)
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(matches, func(a, b models.EmbeddingMatch) int {
+		switch {
+		case a.Similarity > b.Similarity:
+			return -1
+		case a.Similarity < b.Similarity:
+			return 1
+		default:
+			return 0
+		}
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// GetMessageVectors returns the stored model embeddings of the given
+// messages, keyed by message. Messages not yet embedded are missing.
+func (r *embeddingRepo) GetMessageVectors(ctx context.Context, model string, messageIDs []uuid.UUID) (map[uuid.UUID][]float32, error) {
+	vectors := make(map[uuid.UUID][]float32, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return vectors, nil
+	}
+
+	query := `SELECT message_id, embedding FROM embeddings WHERE model = $1 AND message_id = ANY($2::uuid[])`
+
+	rows, err := r.pool.Query(ctx, query, model, uuidStrings(messageIDs))
+	if err != nil {
+		return nil, fmt.Errorf("querying message embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var v []float32
+		if err := rows.Scan(&id, &v); err != nil {
+			return nil, fmt.Errorf("scanning message embedding: %w", err)
+		}
+		vectors[id] = v
+	}
+	return vectors, rows.Err()
+}
+
+// usePgvector reports whether the pgvector extension is installed. It is
+// checked once, outside any request's context so a cancelled request can't
+// decide it; if the check fails, this call falls back to in-process
+// similarity and the next one checks again.
+func (r *embeddingRepo) usePgvector() bool {
+	r.detectMu.Lock()
+	defer r.detectMu.Unlock()
+
+	if r.detected {
+		return r.pgvector
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pgvectorDetectTimeout)
+	defer cancel()
+	var installed bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'vector')`).Scan(&installed)
+	if err != nil {
+		slog.Warn("detecting pgvector failed, using in-process similarity", "error", err)
+		return false
+	}
+	r.pgvector, r.detected = installed, true
+	return installed
+}
+
+// cosine returns the cosine similarity of a and b, or 0 when either is all
+// zeros or their lengths differ.
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
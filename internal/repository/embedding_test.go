@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{"identical", []float32{1, 2, 3}, []float32{1, 2, 3}, 1},
+		{"scaled", []float32{1, 2, 3}, []float32{2, 4, 6}, 1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"opposite", []float32{1, -1}, []float32{-1, 1}, -1},
+		{"partial", []float32{1, 0}, []float32{1, 1}, 1 / math.Sqrt2},
+		{"zero vector", []float32{0, 0}, []float32{1, 1}, 0},
+		{"length mismatch", []float32{1, 2}, []float32{1, 2, 3}, 0},
+		{"empty", nil, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cosine(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("cosine = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"ai-companion-be/internal/models"
@@ -14,10 +15,16 @@ import (
 // InsightsRepository defines data access operations for relationship insights.
 type InsightsRepository interface {
 	RecordMoodSnapshot(ctx context.Context, userID, companionID uuid.UUID, moodScore float64) error
-	GetMoodHistory(ctx context.Context, userID, companionID uuid.UUID, days int) ([]models.MoodSnapshot, error)
-	GetMessageDates(ctx context.Context, userID, companionID uuid.UUID) ([]time.Time, error)
+	GetMoodHistory(ctx context.Context, userID, companionID uuid.UUID, rng models.InsightsRange) ([]models.MoodSnapshot, error)
+	GetSeries(ctx context.Context, userID, companionID uuid.UUID, rng models.InsightsRange) ([]models.InsightsBucket, error)
+	GetStreak(ctx context.Context, userID uuid.UUID, companionID *uuid.UUID) (*models.StreakState, error)
 	GetStats(ctx context.Context, userID, companionID uuid.UUID) (*models.InsightStats, error)
 	GetReactionSummary(ctx context.Context, userID, companionID uuid.UUID) (*models.ReactionSummary, error)
+	GetReactionDays(ctx context.Context, userID, companionID uuid.UUID, rng models.InsightsRange) ([]models.ReactionDay, error)
+	GetStoryReactions(ctx context.Context, userID, companionID uuid.UUID, rng models.InsightsRange, limit int) ([]models.StoryReactions, error)
+	GetCompanionActivity(ctx context.Context, userID uuid.UUID) ([]models.CompanionActivity, error)
+	GetActivityHeatmap(ctx context.Context, userID uuid.UUID, since time.Time) ([7][24]int, error)
+	RebuildRollups(ctx context.Context, userID *uuid.UUID) error
 }
 
 type insightsRepo struct {
@@ -29,10 +36,13 @@ func NewInsightsRepository(pool *pgxpool.Pool) InsightsRepository {
 	return &insightsRepo{pool: pool}
 }
 
+// RecordMoodSnapshot upserts the mood for the current day in the user's
+// time zone.
 func (r *insightsRepo) RecordMoodSnapshot(ctx context.Context, userID, companionID uuid.UUID, moodScore float64) error {
 	query := `
 		INSERT INTO mood_history (id, user_id, companion_id, recorded_date, mood_score)
-		VALUES (gen_random_uuid(), $1, $2, CURRENT_DATE, $3)
+		SELECT gen_random_uuid(), u.id, $2, (NOW() AT TIME ZONE u.timezone)::date, $3
+		FROM users u WHERE u.id = $1
 		ON CONFLICT (user_id, companion_id, recorded_date)
 		DO UPDATE SET mood_score = $3`
 
@@ -43,15 +53,16 @@ func (r *insightsRepo) RecordMoodSnapshot(ctx context.Context, userID, companion
 	return nil
 }
 
-func (r *insightsRepo) GetMoodHistory(ctx context.Context, userID, companionID uuid.UUID, days int) ([]models.MoodSnapshot, error) {
+// GetMoodHistory returns the daily mood snapshots within rng, oldest first.
+func (r *insightsRepo) GetMoodHistory(ctx context.Context, userID, companionID uuid.UUID, rng models.InsightsRange) ([]models.MoodSnapshot, error) {
 	query := `
 		SELECT recorded_date, mood_score
 		FROM mood_history
 		WHERE user_id = $1 AND companion_id = $2
-		  AND recorded_date >= CURRENT_DATE - $3::int
+		  AND recorded_date BETWEEN $3::date AND $4::date
 		ORDER BY recorded_date ASC`
 
-	rows, err := r.pool.Query(ctx, query, userID, companionID, days)
+	rows, err := r.pool.Query(ctx, query, userID, companionID, rng.From, rng.To)
 	if err != nil {
 		return nil, fmt.Errorf("querying mood history: %w", err)
 	}
@@ -74,64 +85,137 @@ func (r *insightsRepo) GetMoodHistory(ctx context.Context, userID, companionID u
 	return history, rows.Err()
 }
 
-func (r *insightsRepo) GetMessageDates(ctx context.Context, userID, companionID uuid.UUID) ([]time.Time, error) {
+// GetSeries aggregates mood, messages and story reactions per bucket of
+// rng. Every bucket in the range is returned, empty ones included. Message
+// and reaction counts come from the daily rollups, whose days are the
+// user's local days; dates are truncated as timestamps without time zone
+// so buckets don't shift with the session's.
+func (r *insightsRepo) GetSeries(ctx context.Context, userID, companionID uuid.UUID, rng models.InsightsRange) ([]models.InsightsBucket, error) {
 	query := `
-		SELECT DISTINCT created_at::date AS msg_date
-		FROM messages
-		WHERE user_id = $1 AND companion_id = $2 AND role = 'user'
-		ORDER BY msg_date DESC`
+		WITH buckets AS (
+			SELECT greatest(b::date, $3::date) AS start,
+			       least((b + ('1 ' || $5::text)::interval)::date - 1, $4::date) AS "end"
+			FROM generate_series(date_trunc($5::text, $3::date::timestamp), $4::date::timestamp, ('1 ' || $5::text)::interval) b
+		),
+		mood AS (
+			SELECT greatest(date_trunc($5::text, recorded_date::timestamp)::date, $3::date) AS start,
+			       min(mood_score)::float8 AS mood_min, avg(mood_score)::float8 AS mood_avg, max(mood_score)::float8 AS mood_max
+			FROM mood_history
+			WHERE user_id = $1 AND companion_id = $2 AND recorded_date BETWEEN $3::date AND $4::date
+			GROUP BY 1
+		),
+		activity AS (
+			SELECT greatest(date_trunc($5::text, activity_date::timestamp)::date, $3::date) AS start,
+			       sum(user_messages + companion_messages) AS messages, sum(reactions) AS reactions
+			FROM pair_daily_activity
+			WHERE user_id = $1 AND companion_id = $2 AND activity_date BETWEEN $3::date AND $4::date
+			GROUP BY 1
+		)
+		SELECT b.start, b."end",
+		       coalesce(mo.mood_min, carry.mood_score),
+		       coalesce(mo.mood_avg, carry.mood_score),
+		       coalesce(mo.mood_max, carry.mood_score),
+		       mo.start IS NULL AND carry.mood_score IS NOT NULL,
+		       coalesce(a.messages, 0), coalesce(a.reactions, 0)
+		FROM buckets b
+		LEFT JOIN mood mo ON mo.start = b.start
+		LEFT JOIN activity a ON a.start = b.start
+		LEFT JOIN LATERAL (
+			SELECT mood_score::float8 AS mood_score FROM mood_history
+			WHERE user_id = $1 AND companion_id = $2 AND recorded_date < b.start
+			ORDER BY recorded_date DESC
+			LIMIT 1
+		) carry ON mo.start IS NULL
+		ORDER BY b.start`
 
-	rows, err := r.pool.Query(ctx, query, userID, companionID)
+	rows, err := r.pool.Query(ctx, query, userID, companionID, rng.From, rng.To, rng.Granularity)
 	if err != nil {
-		return nil, fmt.Errorf("querying message dates: %w", err)
+		return nil, fmt.Errorf("querying insights series: %w", err)
 	}
 	defer rows.Close()
 
-	var dates []time.Time
+	buckets := []models.InsightsBucket{}
 	for rows.Next() {
-		var d time.Time
-		if err := rows.Scan(&d); err != nil {
-			return nil, fmt.Errorf("scanning message date: %w", err)
+		var b models.InsightsBucket
+		var start, end time.Time
+		if err := rows.Scan(&start, &end, &b.MoodMin, &b.MoodAvg, &b.MoodMax, &b.MoodCarried, &b.Messages, &b.Reactions); err != nil {
+			return nil, fmt.Errorf("scanning insights bucket: %w", err)
 		}
-		dates = append(dates, d)
+		b.Start, b.End = start.Format(time.DateOnly), end.Format(time.DateOnly)
+		if b.MoodAvg != nil {
+			label := models.GetMoodLabel(*b.MoodAvg)
+			b.MoodLabel = &label
+		}
+		buckets = append(buckets, b)
 	}
+	return buckets, rows.Err()
+}
 
-	return dates, rows.Err()
+// GetStreak returns the user's rolled-up messaging streak with the
+// companion, or with any companion if companionID is nil. It is zero before
+// their first message.
+func (r *insightsRepo) GetStreak(ctx context.Context, userID uuid.UUID, companionID *uuid.UUID) (*models.StreakState, error) {
+	var state models.StreakState
+	var err error
+	if companionID == nil {
+		query := `
+			SELECT last_active_date, current_run, longest_run, active_days
+			FROM user_activity_stats WHERE user_id = $1`
+		err = r.pool.QueryRow(ctx, query, userID).
+			Scan(&state.LastActiveDate, &state.CurrentRun, &state.LongestRun, &state.ActiveDays)
+	} else {
+		query := `
+			SELECT last_active_date, current_run, longest_run, active_days
+			FROM pair_stats WHERE user_id = $1 AND companion_id = $2`
+		err = r.pool.QueryRow(ctx, query, userID, *companionID).
+			Scan(&state.LastActiveDate, &state.CurrentRun, &state.LongestRun, &state.ActiveDays)
+	}
+	if err == pgx.ErrNoRows {
+		return &state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying streak: %w", err)
+	}
+	return &state, nil
 }
 
+// GetStats reads the pair's totals from the rollups. Days together are
+// calendar days in the user's time zone, the first and today included.
 func (r *insightsRepo) GetStats(ctx context.Context, userID, companionID uuid.UUID) (*models.InsightStats, error) {
 	query := `
-		SELECT
-			(SELECT count(*) FROM messages WHERE user_id = $1 AND companion_id = $2) AS total_messages,
-			(SELECT count(*) FROM memories WHERE user_id = $1 AND companion_id = $2) AS total_memories,
-			(SELECT min(created_at) FROM messages WHERE user_id = $1 AND companion_id = $2) AS first_message`
+		SELECT coalesce(ps.total_messages, 0), coalesce(ps.total_memories, 0), ps.first_message_at,
+		       coalesce((NOW() AT TIME ZONE u.timezone)::date - (ps.first_message_at AT TIME ZONE u.timezone)::date + 1, 0)
+		FROM users u
+		LEFT JOIN pair_stats ps ON ps.user_id = u.id AND ps.companion_id = $2
+		WHERE u.id = $1`
 
 	var stats models.InsightStats
-	var firstMsg *time.Time
 
 	err := r.pool.QueryRow(ctx, query, userID, companionID).
-		Scan(&stats.TotalMessages, &stats.TotalMemories, &firstMsg)
+		Scan(&stats.TotalMessages, &stats.TotalMemories, &stats.FirstMessage, &stats.DaysTogether)
 	if err != nil {
 		return nil, fmt.Errorf("querying insight stats: %w", err)
 	}
 
-	stats.FirstMessage = firstMsg
-	if firstMsg != nil {
-		stats.DaysTogether = int(time.Since(*firstMsg).Hours()/24) + 1
-	}
-
 	return &stats, nil
 }
 
 func (r *insightsRepo) GetReactionSummary(ctx context.Context, userID, companionID uuid.UUID) (*models.ReactionSummary, error) {
-	// Counts by reaction type.
+	// Counts by reaction type, in catalogue order. Every active type is
+	// listed, plus inactive ones the user has used.
 	countsQuery := `
-		SELECT sr.reaction, COUNT(*) AS count
-		FROM story_reactions sr
-		JOIN story_media sm ON sr.media_id = sm.id
-		JOIN stories s ON sm.story_id = s.id
-		WHERE sr.user_id = $1 AND s.companion_id = $2
-		GROUP BY sr.reaction`
+		SELECT rt.key, COUNT(x.reaction) AS count, rt.display_order
+		FROM reaction_types rt
+		LEFT JOIN (
+			SELECT sr.reaction
+			FROM story_reactions sr
+			JOIN story_media sm ON sr.media_id = sm.id
+			JOIN stories s ON sm.story_id = s.id
+			WHERE sr.user_id = $1 AND s.companion_id = $2
+		) x ON x.reaction = rt.key
+		GROUP BY rt.key, rt.display_order, rt.active
+		HAVING rt.active OR COUNT(x.reaction) > 0
+		ORDER BY rt.display_order, rt.key`
 
 	rows, err := r.pool.Query(ctx, countsQuery, userID, companionID)
 	if err != nil {
@@ -139,38 +223,23 @@ func (r *insightsRepo) GetReactionSummary(ctx context.Context, userID, companion
 	}
 	defer rows.Close()
 
-	counts := map[string]int{
-		"love":       0,
-		"sad":        0,
-		"heart_eyes": 0,
-		"angry":      0,
-	}
+	counts := make(map[string]int)
 	total := 0
+	var uses []models.ReactionUse
 
 	for rows.Next() {
-		var reaction string
-		var count int
-		if err := rows.Scan(&reaction, &count); err != nil {
+		var u models.ReactionUse
+		if err := rows.Scan(&u.Reaction, &u.Count, &u.Order); err != nil {
 			return nil, fmt.Errorf("scanning reaction count: %w", err)
 		}
-		counts[reaction] = count
-		total += count
+		counts[u.Reaction] = u.Count
+		total += u.Count
+		uses = append(uses, u)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterating reaction counts: %w", err)
 	}
 
-	// Dominant emotion.
-	var dominant *string
-	maxCount := 0
-	for reaction, count := range counts {
-		if count > maxCount {
-			maxCount = count
-			r := reaction
-			dominant = &r
-		}
-	}
-
 	// Recent reactions.
 	recentQuery := `
 		SELECT sr.reaction, sr.created_at
@@ -203,6 +272,173 @@ func (r *insightsRepo) GetReactionSummary(ctx context.Context, userID, companion
 		Total:           total,
 		Counts:          counts,
 		Recent:          recent,
-		DominantEmotion: dominant,
+		DominantEmotion: models.DominantReaction(uses),
 	}, nil
 }
+
+// reactionInRange restricts story_reactions sr to those last changed on the
+// local dates $3 to $4 in time zone $5. Trends date a reaction by when it
+// was last changed, so a switch counts on the day it was made.
+const reactionInRange = `sr.updated_at >= $3::date::timestamp AT TIME ZONE $5::text
+		  AND sr.updated_at < ($4::date + 1)::timestamp AT TIME ZONE $5::text`
+
+// GetReactionDays returns the user's reactions to the companion's stories
+// per local day of rng, oldest first. Days without reactions are omitted.
+func (r *insightsRepo) GetReactionDays(ctx context.Context, userID, companionID uuid.UUID, rng models.InsightsRange) ([]models.ReactionDay, error) {
+	query := `
+		SELECT (sr.updated_at AT TIME ZONE $5::text)::date, sr.reaction, count(*),
+		       rt.display_order, rt.sentiment_weight::float8
+		FROM story_reactions sr
+		JOIN stories s ON s.id = sr.story_id
+		JOIN reaction_types rt ON rt.key = sr.reaction
+		WHERE sr.user_id = $1 AND s.companion_id = $2
+		  AND ` + reactionInRange + `
+		GROUP BY 1, sr.reaction, rt.display_order, rt.sentiment_weight
+		ORDER BY 1, rt.display_order, sr.reaction`
+
+	rows, err := r.pool.Query(ctx, query, userID, companionID, rng.From, rng.To, rng.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("querying reaction days: %w", err)
+	}
+	defer rows.Close()
+
+	var days []models.ReactionDay
+	for rows.Next() {
+		var date time.Time
+		var u models.ReactionUse
+		if err := rows.Scan(&date, &u.Reaction, &u.Count, &u.Order, &u.Weight); err != nil {
+			return nil, fmt.Errorf("scanning reaction day: %w", err)
+		}
+		d := date.Format(time.DateOnly)
+		if len(days) == 0 || days[len(days)-1].Date != d {
+			days = append(days, models.ReactionDay{Date: d})
+		}
+		last := &days[len(days)-1]
+		last.Uses = append(last.Uses, u)
+	}
+	return days, rows.Err()
+}
+
+// GetStoryReactions returns the user's reactions within rng to up to limit
+// of the companion's stories, newest story first. Only the reaction uses
+// are filled in beyond the story's identity and timing.
+func (r *insightsRepo) GetStoryReactions(ctx context.Context, userID, companionID uuid.UUID, rng models.InsightsRange, limit int) ([]models.StoryReactions, error) {
+	query := `
+		WITH reacted AS (
+			SELECT s.id, s.created_at, max(sr.updated_at) AS last_reacted_at
+			FROM story_reactions sr
+			JOIN stories s ON s.id = sr.story_id
+			WHERE sr.user_id = $1 AND s.companion_id = $2
+			  AND ` + reactionInRange + `
+			GROUP BY s.id, s.created_at
+			ORDER BY s.created_at DESC, s.id
+			LIMIT $6
+		)
+		SELECT rd.id, rd.created_at, rd.last_reacted_at, sr.reaction, count(*),
+		       rt.display_order, rt.sentiment_weight::float8
+		FROM reacted rd
+		JOIN story_reactions sr ON sr.story_id = rd.id AND sr.user_id = $1
+		JOIN reaction_types rt ON rt.key = sr.reaction
+		WHERE ` + reactionInRange + `
+		GROUP BY rd.id, rd.created_at, rd.last_reacted_at, sr.reaction, rt.display_order, rt.sentiment_weight
+		ORDER BY rd.created_at DESC, rd.id, rt.display_order, sr.reaction`
+
+	rows, err := r.pool.Query(ctx, query, userID, companionID, rng.From, rng.To, rng.Timezone, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying story reactions: %w", err)
+	}
+	defer rows.Close()
+
+	stories := []models.StoryReactions{}
+	for rows.Next() {
+		var st models.StoryReactions
+		var u models.ReactionUse
+		if err := rows.Scan(&st.StoryID, &st.CreatedAt, &st.LastReactedAt, &u.Reaction, &u.Count, &u.Order, &u.Weight); err != nil {
+			return nil, fmt.Errorf("scanning story reactions: %w", err)
+		}
+		if len(stories) == 0 || stories[len(stories)-1].StoryID != st.StoryID {
+			stories = append(stories, st)
+		}
+		last := &stories[len(stories)-1]
+		last.Uses = append(last.Uses, u)
+	}
+	return stories, rows.Err()
+}
+
+// GetCompanionActivity returns the user's activity with each companion they
+// have a relationship with, most messages first, from the rollups.
+func (r *insightsRepo) GetCompanionActivity(ctx context.Context, userID uuid.UUID) ([]models.CompanionActivity, error) {
+	query := `
+		SELECT rs.companion_id, c.name, c.avatar_url,
+		       coalesce(ps.total_messages, 0), coalesce(ps.total_memories, 0), coalesce(ps.total_reactions, 0),
+		       coalesce(ps.chat_seconds, 0), rs.mood_score::float8, rs.relationship_score::float8, rs.last_interaction
+		FROM relationship_states rs
+		JOIN companions c ON c.id = rs.companion_id
+		LEFT JOIN pair_stats ps ON ps.user_id = rs.user_id AND ps.companion_id = rs.companion_id
+		WHERE rs.user_id = $1
+		ORDER BY coalesce(ps.total_messages, 0) DESC, c.name`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying companion activity: %w", err)
+	}
+	defer rows.Close()
+
+	activity := []models.CompanionActivity{}
+	for rows.Next() {
+		var a models.CompanionActivity
+		var seconds float64
+		if err := rows.Scan(&a.CompanionID, &a.Name, &a.AvatarURL, &a.Messages, &a.Memories, &a.Reactions,
+			&seconds, &a.MoodScore, &a.RelationshipScore, &a.LastInteraction); err != nil {
+			return nil, fmt.Errorf("scanning companion activity: %w", err)
+		}
+		a.ChatMinutes = seconds / 60
+		a.MoodLabel = models.GetMoodLabel(a.MoodScore)
+		activity = append(activity, a)
+	}
+	return activity, rows.Err()
+}
+
+// GetActivityHeatmap counts the user's messages sent since since by ISO
+// weekday (Monday first) and hour, in the user's time zone.
+func (r *insightsRepo) GetActivityHeatmap(ctx context.Context, userID uuid.UUID, since time.Time) ([7][24]int, error) {
+	query := `
+		SELECT extract(isodow FROM l.at)::int, extract(hour FROM l.at)::int, count(*)
+		FROM messages m
+		JOIN users u ON u.id = m.user_id
+		CROSS JOIN LATERAL (SELECT m.created_at AT TIME ZONE u.timezone AS at) l
+		WHERE m.user_id = $1 AND m.role = 'user' AND m.created_at >= $2
+		GROUP BY 1, 2`
+
+	var counts [7][24]int
+	rows, err := r.pool.Query(ctx, query, userID, since)
+	if err != nil {
+		return counts, fmt.Errorf("querying activity heatmap: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dow, hour, n int
+		if err := rows.Scan(&dow, &hour, &n); err != nil {
+			return counts, fmt.Errorf("scanning activity heatmap: %w", err)
+		}
+		if dow >= 1 && dow <= 7 && hour >= 0 && hour < 24 {
+			counts[dow-1][hour] = n
+		}
+	}
+	return counts, rows.Err()
+}
+
+// RebuildRollups recomputes the user's insights rollups, or everyone's if
+// userID is nil, from messages, memories and reactions.
+func (r *insightsRepo) RebuildRollups(ctx context.Context, userID *uuid.UUID) error {
+	var user *string
+	if userID != nil {
+		id := userID.String()
+		user = &id
+	}
+	if _, err := r.pool.Exec(ctx, `SELECT rebuild_insights_rollups($1::uuid)`, user); err != nil {
+		return fmt.Errorf("rebuilding insights rollups: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pairStatsRow is a pair_stats row, comparable with ==.
+type pairStatsRow struct {
+	TotalMessages  int
+	TotalMemories  int
+	TotalReactions int
+	FirstMessageAt time.Time
+	LastMessageAt  time.Time
+	ChatSeconds    float64
+	ActiveDays     int
+	LastActiveDate string
+	CurrentRun     int
+	LongestRun     int
+}
+
+func (r pairStatsRow) totals() [3]int {
+	return [3]int{r.TotalMessages, r.TotalMemories, r.TotalReactions}
+}
+
+func readPairStats(t *testing.T, pool *pgxpool.Pool, userID, companionID uuid.UUID) pairStatsRow {
+	t.Helper()
+	query := `
+		SELECT total_messages, total_memories, total_reactions, first_message_at, last_message_at,
+		       chat_seconds, active_days, coalesce(last_active_date::text, ''), current_run, longest_run
+		FROM pair_stats WHERE user_id = $1 AND companion_id = $2`
+
+	var r pairStatsRow
+	err := pool.QueryRow(context.Background(), query, userID, companionID).Scan(
+		&r.TotalMessages, &r.TotalMemories, &r.TotalReactions, &r.FirstMessageAt, &r.LastMessageAt,
+		&r.ChatSeconds, &r.ActiveDays, &r.LastActiveDate, &r.CurrentRun, &r.LongestRun)
+	if err != nil {
+		t.Fatalf("reading pair stats: %v", err)
+	}
+	r.FirstMessageAt, r.LastMessageAt = r.FirstMessageAt.UTC(), r.LastMessageAt.UTC()
+	return r
+}
+
+// readPairDays returns the pair's daily activity as "date user/companion/memories/reactions".
+func readPairDays(t *testing.T, pool *pgxpool.Pool, userID, companionID uuid.UUID) []string {
+	t.Helper()
+	query := `
+		SELECT activity_date::text, user_messages, companion_messages, memories, reactions
+		FROM pair_daily_activity
+		WHERE user_id = $1 AND companion_id = $2
+		  AND user_messages + companion_messages + memories + reactions > 0
+		ORDER BY activity_date`
+
+	rows, err := pool.Query(context.Background(), query, userID, companionID)
+	if err != nil {
+		t.Fatalf("reading pair days: %v", err)
+	}
+	defer rows.Close()
+
+	var days []string
+	for rows.Next() {
+		var d string
+		var um, cm, mem, re int
+		if err := rows.Scan(&d, &um, &cm, &mem, &re); err != nil {
+			t.Fatalf("scanning pair day: %v", err)
+		}
+		days = append(days, fmt.Sprintf("%s %d/%d/%d/%d", d, um, cm, mem, re))
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("reading pair days: %v", err)
+	}
+	return days
+}
+
+func TestRollupTriggersMatchRebuild(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	repo := NewInsightsRepository(pool)
+	userID := createTestUser(t, pool, "UTC")
+	companionID := createTestCompanion(t, pool)
+
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	at := func(days int, clock string) time.Time {
+		c, err := time.Parse("15:04", clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return day.AddDate(0, 0, days).Add(time.Duration(c.Hour())*time.Hour + time.Duration(c.Minute())*time.Minute)
+	}
+	message := func(role string, createdAt time.Time) uuid.UUID {
+		t.Helper()
+		id := uuid.New()
+		_, err := pool.Exec(ctx, `
+			INSERT INTO messages (id, user_id, companion_id, content, role, created_at)
+			VALUES ($1, $2, $3, 'hi', $4, $5)`, id, userID, companionID, role, createdAt)
+		if err != nil {
+			t.Fatalf("inserting message: %v", err)
+		}
+		return id
+	}
+	exec := func(query string, args ...any) {
+		t.Helper()
+		if _, err := pool.Exec(ctx, query, args...); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	rebuild := func() {
+		t.Helper()
+		if err := repo.RebuildRollups(ctx, &userID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Messages in order, as the app writes them: the triggers must agree
+	// with a rebuild exactly.
+	message("user", at(0, "10:00"))
+	reply := message("companion", at(0, "10:05"))
+	message("user", at(0, "10:20"))
+	exec(`INSERT INTO memories (user_id, companion_id, content, created_at) VALUES ($1, $2, 'likes tea', $3)`,
+		userID, companionID, at(0, "10:21"))
+	message("user", at(1, "20:00"))
+	message("companion", at(1, "20:10"))
+	late := message("user", at(3, "23:30"))
+	message("companion", at(3, "23:45"))
+
+	triggered, triggeredDays := readPairStats(t, pool, userID, companionID), readPairDays(t, pool, userID, companionID)
+	rebuild()
+	rebuilt, rebuiltDays := readPairStats(t, pool, userID, companionID), readPairDays(t, pool, userID, companionID)
+	if triggered != rebuilt {
+		t.Errorf("after inserts: triggers kept %+v, rebuild gave %+v", triggered, rebuilt)
+	}
+	if !slices.Equal(triggeredDays, rebuiltDays) {
+		t.Errorf("after inserts: triggers kept days %v, rebuild gave %v", triggeredDays, rebuiltDays)
+	}
+	want := pairStatsRow{
+		TotalMessages: 7, TotalMemories: 1,
+		FirstMessageAt: at(0, "10:00"), LastMessageAt: at(3, "23:45"),
+		ChatSeconds: 2700, ActiveDays: 3, LastActiveDate: "2026-03-05", CurrentRun: 1, LongestRun: 2,
+	}
+	if rebuilt != want {
+		t.Errorf("after inserts: got %+v, want %+v", rebuilt, want)
+	}
+
+	// Deletes: the triggers keep the totals and days exact, but leave chat
+	// time and streaks for a rebuild to recompute.
+	exec(`DELETE FROM messages WHERE id = ANY($1::uuid[])`, uuidStrings([]uuid.UUID{reply, late}))
+	triggered, triggeredDays = readPairStats(t, pool, userID, companionID), readPairDays(t, pool, userID, companionID)
+	rebuild()
+	rebuilt, rebuiltDays = readPairStats(t, pool, userID, companionID), readPairDays(t, pool, userID, companionID)
+	if triggered.totals() != rebuilt.totals() {
+		t.Errorf("after deletes: triggers kept totals %v, rebuild gave %v", triggered.totals(), rebuilt.totals())
+	}
+	if !slices.Equal(triggeredDays, rebuiltDays) {
+		t.Errorf("after deletes: triggers kept days %v, rebuild gave %v", triggeredDays, rebuiltDays)
+	}
+	want = pairStatsRow{
+		TotalMessages: 5, TotalMemories: 1,
+		FirstMessageAt: at(0, "10:00"), LastMessageAt: at(3, "23:45"),
+		ChatSeconds: 1800, ActiveDays: 2, LastActiveDate: "2026-03-03", CurrentRun: 2, LongestRun: 2,
+	}
+	if rebuilt != want {
+		t.Errorf("after deletes: got %+v, want %+v", rebuilt, want)
+	}
+
+	// A time zone change moves past days only once rebuilt. At UTC+7 the
+	// evening messages fall on the next local day.
+	exec(`UPDATE users SET timezone = 'Asia/Ho_Chi_Minh' WHERE id = $1`, userID)
+	rebuild()
+	rebuilt, rebuiltDays = readPairStats(t, pool, userID, companionID), readPairDays(t, pool, userID, companionID)
+	wantDays := []string{"2026-03-02 2/0/1/0", "2026-03-04 1/1/0/0", "2026-03-06 0/1/0/0"}
+	if !slices.Equal(rebuiltDays, wantDays) {
+		t.Errorf("after time zone change: got days %v, want %v", rebuiltDays, wantDays)
+	}
+	want.ActiveDays, want.LastActiveDate, want.CurrentRun, want.LongestRun = 2, "2026-03-04", 1, 1
+	if rebuilt != want {
+		t.Errorf("after time zone change: got %+v, want %+v", rebuilt, want)
+	}
+
+	// The triggers carry on from the rebuilt state, in the new time zone.
+	message("user", at(5, "20:00"))
+	triggered, triggeredDays = readPairStats(t, pool, userID, companionID), readPairDays(t, pool, userID, companionID)
+	rebuild()
+	rebuilt, rebuiltDays = readPairStats(t, pool, userID, companionID), readPairDays(t, pool, userID, companionID)
+	if triggered != rebuilt {
+		t.Errorf("after rebuild and insert: triggers kept %+v, rebuild gave %+v", triggered, rebuilt)
+	}
+	if !slices.Equal(triggeredDays, rebuiltDays) {
+		t.Errorf("after rebuild and insert: triggers kept days %v, rebuild gave %v", triggeredDays, rebuiltDays)
+	}
+	if rebuilt.LastActiveDate != "2026-03-08" || rebuilt.ActiveDays != 3 || rebuilt.CurrentRun != 1 {
+		t.Errorf("after rebuild and insert: got streak %+v", rebuilt)
+	}
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// JobRunRepository defines data access operations for scheduled job runs.
+type JobRunRepository interface {
+	// Claim tries to become the runner for one scheduled slot of a job. It
+	// returns ok=false when another replica holds the job's lock, already
+	// claimed the slot, or still has a run in progress. Runs started more
+	// than staleAfter ago are assumed dead and marked failed.
+	Claim(ctx context.Context, jobName string, scheduledFor time.Time, instance string, staleAfter time.Duration) (id int64, ok bool, err error)
+	Finish(ctx context.Context, id int64, status string, errMsg *string) error
+	GetRecent(ctx context.Context, jobName string, limit int) ([]models.JobRun, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type jobRunRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewJobRunRepository creates a new JobRunRepository backed by PostgreSQL.
+func NewJobRunRepository(pool *pgxpool.Pool) JobRunRepository {
+	return &jobRunRepo{pool: pool}
+}
+
+func (r *jobRunRepo) Claim(ctx context.Context, jobName string, scheduledFor time.Time, instance string, staleAfter time.Duration) (int64, bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, false, fmt.Errorf("beginning claim: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The lock is released at commit, so it only serialises the claim
+	// itself; the job_runs row is what marks the slot as taken.
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext('job:' || $1))`, jobName).Scan(&locked); err != nil {
+		return 0, false, fmt.Errorf("taking job lock: %w", err)
+	}
+	if !locked {
+		return 0, false, nil
+	}
+
+	staleSecs := int(staleAfter.Seconds())
+
+	abandon := `
+		UPDATE job_runs
+		SET status = 'failed', error = 'abandoned: no result before timeout', finished_at = NOW()
+		WHERE job_name = $1 AND status = 'running'
+		  AND started_at < NOW() - $2::int * INTERVAL '1 second'`
+	if _, err := tx.Exec(ctx, abandon, jobName, staleSecs); err != nil {
+		return 0, false, fmt.Errorf("expiring stale job runs: %w", err)
+	}
+
+	var running bool
+	if err := tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM job_runs WHERE job_name = $1 AND status = 'running')`, jobName,
+	).Scan(&running); err != nil {
+		return 0, false, fmt.Errorf("checking running jobs: %w", err)
+	}
+	if running {
+		return 0, false, tx.Commit(ctx)
+	}
+
+	claim := `
+		INSERT INTO job_runs (job_name, scheduled_for, instance, status, started_at)
+		VALUES ($1, $2, $3, 'running', NOW())
+		ON CONFLICT (job_name, scheduled_for) DO NOTHING
+		RETURNING id`
+
+	var id int64
+	err = tx.QueryRow(ctx, claim, jobName, scheduledFor, instance).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return 0, false, tx.Commit(ctx)
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("claiming job run: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, false, fmt.Errorf("committing claim: %w", err)
+	}
+	return id, true, nil
+}
+
+func (r *jobRunRepo) Finish(ctx context.Context, id int64, status string, errMsg *string) error {
+	query := `
+		UPDATE job_runs
+		SET status = $2, error = $3, finished_at = NOW()
+		WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, status, errMsg); err != nil {
+		return fmt.Errorf("finishing job run: %w", err)
+	}
+	return nil
+}
+
+// GetRecent returns the latest runs, newest first, for one job or for all
+// jobs when jobName is empty.
+func (r *jobRunRepo) GetRecent(ctx context.Context, jobName string, limit int) ([]models.JobRun, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	query := `
+		SELECT id, job_name, scheduled_for, instance, status, error, started_at, finished_at
+		FROM job_runs
+		WHERE $1 = '' OR job_name = $1
+		ORDER BY scheduled_for DESC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, jobName, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying job runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.JobRun{}
+	for rows.Next() {
+		var j models.JobRun
+		if err := rows.Scan(&j.ID, &j.JobName, &j.ScheduledFor, &j.Instance, &j.Status, &j.Error, &j.StartedAt, &j.FinishedAt); err != nil {
+			return nil, fmt.Errorf("scanning job run: %w", err)
+		}
+		runs = append(runs, j)
+	}
+
+	return runs, rows.Err()
+}
+
+func (r *jobRunRepo) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM job_runs WHERE started_at < $1 AND status <> 'running'`, before)
+	if err != nil {
+		return 0, fmt.Errorf("pruning job runs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
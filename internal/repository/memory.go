@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,10 +16,16 @@ import (
 // MemoryRepository defines data access operations for memories.
 type MemoryRepository interface {
 	Create(ctx context.Context, memory *models.Memory) error
-	GetByUserAndCompanion(ctx context.Context, userID, companionID uuid.UUID, limit int) (*models.MemoryPage, error)
+	GetByUserAndCompanion(ctx context.Context, userID, companionID uuid.UUID, filter models.MemoryFilter, after *models.MemoryKey, limit int) ([]models.Memory, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Memory, error)
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Memory, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	TogglePin(ctx context.Context, id uuid.UUID) (*models.Memory, error)
+	Update(ctx context.Context, id uuid.UUID, content string, tag *string) (*models.Memory, error)
+	GetEdits(ctx context.Context, memoryID uuid.UUID) ([]models.MemoryEdit, error)
+	Reinforce(ctx context.Context, ids []uuid.UUID, boost float64) error
+	GetStrongest(ctx context.Context, userID, companionID uuid.UUID, at time.Time, halfLifeDays, minStrength float64, limit int) ([]models.Memory, error)
+	GetFading(ctx context.Context, userID, companionID uuid.UUID, at time.Time, halfLifeDays, threshold float64, until time.Time, limit int) ([]models.FadingMemory, error)
 }
 
 type memoryRepo struct {
@@ -30,78 +37,99 @@ func NewMemoryRepository(pool *pgxpool.Pool) MemoryRepository {
 	return &memoryRepo{pool: pool}
 }
 
+// memoryColumns is the column list read by every memory query, in scanMemory order.
+const memoryColumns = `id, user_id, companion_id, message_id, story_media_id, content, tag, pinned, created_at, edited_at,
+	importance::float8, last_recalled_at, recall_count`
+
+// prefixedMemoryColumns is memoryColumns for queries aliasing memories as m.
+const prefixedMemoryColumns = `m.id, m.user_id, m.companion_id, m.message_id, m.story_media_id, m.content, m.tag, m.pinned, m.created_at, m.edited_at,
+	m.importance::float8, m.last_recalled_at, m.recall_count`
+
+func scanMemory(row pgx.Row, m *models.Memory) error {
+	return row.Scan(&m.ID, &m.UserID, &m.CompanionID, &m.MessageID, &m.StoryMediaID, &m.Content, &m.Tag, &m.Pinned, &m.CreatedAt, &m.EditedAt,
+		&m.Importance, &m.LastRecalledAt, &m.RecallCount)
+}
+
 func (r *memoryRepo) Create(ctx context.Context, memory *models.Memory) error {
 	query := `
-		INSERT INTO memories (id, user_id, companion_id, message_id, content, tag, pinned, created_at)
-		VALUES ($1, $2, $3, $4::uuid, $5, $6, $7, NOW())
+		INSERT INTO memories (id, user_id, companion_id, message_id, story_media_id, content, tag, pinned, importance, created_at)
+		VALUES ($1, $2, $3, $4::uuid, $5::uuid, $6, $7, $8, $9, NOW())
 		RETURNING created_at`
 
 	// Convert *uuid.UUID to *string for PgBouncer simple-protocol compatibility.
-	var messageID *string
+	var messageID, storyMediaID *string
 	if memory.MessageID != nil {
 		s := memory.MessageID.String()
 		messageID = &s
 	}
+	if memory.StoryMediaID != nil {
+		s := memory.StoryMediaID.String()
+		storyMediaID = &s
+	}
 
 	return r.pool.QueryRow(ctx, query,
-		memory.ID, memory.UserID, memory.CompanionID, messageID, memory.Content, memory.Tag, memory.Pinned,
+		memory.ID, memory.UserID, memory.CompanionID, messageID, storyMediaID, memory.Content, memory.Tag, memory.Pinned, memory.Importance,
 	).Scan(&memory.CreatedAt)
 }
 
-func (r *memoryRepo) GetByUserAndCompanion(ctx context.Context, userID, companionID uuid.UUID, limit int) (*models.MemoryPage, error) {
-	if limit <= 0 || limit > 100 {
-		limit = 50
+// GetByUserAndCompanion returns up to limit memories matching filter,
+// pinned first and then newest first, starting after the given position
+// (or from the top when after is nil).
+func (r *memoryRepo) GetByUserAndCompanion(ctx context.Context, userID, companionID uuid.UUID, filter models.MemoryFilter, after *models.MemoryKey, limit int) ([]models.Memory, error) {
+	conds := []string{"user_id = $1", "companion_id = $2"}
+	args := []any{userID, companionID}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
 	}
 
-	fetchLimit := limit + 1
+	if filter.Tag != nil {
+		conds = append(conds, "tag = "+arg(*filter.Tag))
+	}
+	if filter.PinnedOnly {
+		conds = append(conds, "pinned")
+	}
+	if filter.From != nil {
+		conds = append(conds, "created_at >= "+arg(*filter.From))
+	}
+	if filter.To != nil {
+		conds = append(conds, "created_at < "+arg(*filter.To))
+	}
+	if after != nil {
+		// Every sort key is descending, so a row comparison gives the keyset.
+		conds = append(conds, fmt.Sprintf("(pinned, created_at, id) < (%s, %s, %s)",
+			arg(after.Pinned), arg(after.CreatedAt), arg(after.ID)))
+	}
 
 	query := `
-		SELECT id, user_id, companion_id, message_id, content, tag, pinned, created_at
+		SELECT ` + memoryColumns + `
 		FROM memories
-		WHERE user_id = $1 AND companion_id = $2
-		ORDER BY pinned DESC, created_at DESC
-		LIMIT $3`
+		WHERE ` + strings.Join(conds, " AND ") + `
+		ORDER BY pinned DESC, created_at DESC, id DESC
+		LIMIT ` + arg(limit)
 
-	rows, err := r.pool.Query(ctx, query, userID, companionID, fetchLimit)
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying memories: %w", err)
 	}
 	defer rows.Close()
 
-	var memories []models.Memory
+	memories := []models.Memory{}
 	for rows.Next() {
 		var m models.Memory
-		if err := rows.Scan(&m.ID, &m.UserID, &m.CompanionID, &m.MessageID, &m.Content, &m.Tag, &m.Pinned, &m.CreatedAt); err != nil {
+		if err := scanMemory(rows, &m); err != nil {
 			return nil, fmt.Errorf("scanning memory: %w", err)
 		}
 		memories = append(memories, m)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	page := &models.MemoryPage{HasMore: false}
-
-	if len(memories) > limit {
-		page.HasMore = true
-		memories = memories[:limit]
-	}
-
-	page.Memories = memories
-	if len(memories) > 0 {
-		last := memories[len(memories)-1].CreatedAt.Format(time.RFC3339Nano)
-		page.NextCursor = last
-	}
-
-	return page, nil
+	return memories, rows.Err()
 }
 
 func (r *memoryRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Memory, error) {
-	query := `SELECT id, user_id, companion_id, message_id, content, tag, pinned, created_at FROM memories WHERE id = $1`
+	query := `SELECT ` + memoryColumns + ` FROM memories WHERE id = $1`
 
 	var m models.Memory
-	err := r.pool.QueryRow(ctx, query, id).
-		Scan(&m.ID, &m.UserID, &m.CompanionID, &m.MessageID, &m.Content, &m.Tag, &m.Pinned, &m.CreatedAt)
+	err := scanMemory(r.pool.QueryRow(ctx, query, id), &m)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("memory not found")
@@ -111,6 +139,32 @@ func (r *memoryRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Memory,
 	return &m, nil
 }
 
+// GetByIDs returns the memories with the given IDs that still exist, in no
+// particular order.
+func (r *memoryRepo) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Memory, error) {
+	memories := []models.Memory{}
+	if len(ids) == 0 {
+		return memories, nil
+	}
+
+	query := `SELECT ` + memoryColumns + ` FROM memories WHERE id = ANY($1::uuid[])`
+
+	rows, err := r.pool.Query(ctx, query, uuidStrings(ids))
+	if err != nil {
+		return nil, fmt.Errorf("querying memories: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m models.Memory
+		if err := scanMemory(rows, &m); err != nil {
+			return nil, fmt.Errorf("scanning memory: %w", err)
+		}
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
 func (r *memoryRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM memories WHERE id = $1`
 
@@ -128,11 +182,10 @@ func (r *memoryRepo) TogglePin(ctx context.Context, id uuid.UUID) (*models.Memor
 	query := `
 		UPDATE memories SET pinned = NOT pinned
 		WHERE id = $1
-		RETURNING id, user_id, companion_id, message_id, content, tag, pinned, created_at`
+		RETURNING ` + memoryColumns
 
 	var m models.Memory
-	err := r.pool.QueryRow(ctx, query, id).
-		Scan(&m.ID, &m.UserID, &m.CompanionID, &m.MessageID, &m.Content, &m.Tag, &m.Pinned, &m.CreatedAt)
+	err := scanMemory(r.pool.QueryRow(ctx, query, id), &m)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("memory not found")
@@ -141,3 +194,166 @@ func (r *memoryRepo) TogglePin(ctx context.Context, id uuid.UUID) (*models.Memor
 	}
 	return &m, nil
 }
+
+// Update sets a memory's content and tag and records the change in its edit
+// history. Nothing is written when neither changes.
+func (r *memoryRepo) Update(ctx context.Context, id uuid.UUID, content string, tag *string) (*models.Memory, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning memory update: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var old models.Memory
+	if err := scanMemory(tx.QueryRow(ctx, `SELECT `+memoryColumns+` FROM memories WHERE id = $1 FOR UPDATE`, id), &old); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("memory not found")
+		}
+		return nil, fmt.Errorf("getting memory: %w", err)
+	}
+
+	if old.Content == content && equalTags(old.Tag, tag) {
+		return &old, nil
+	}
+
+	update := `
+		UPDATE memories SET content = $2, tag = $3, edited_at = NOW()
+		WHERE id = $1
+		RETURNING ` + memoryColumns
+
+	var m models.Memory
+	if err := scanMemory(tx.QueryRow(ctx, update, id, content, tag), &m); err != nil {
+		return nil, fmt.Errorf("updating memory: %w", err)
+	}
+
+	edit := `
+		INSERT INTO memory_edits (memory_id, user_id, previous_content, previous_tag, content, tag, edited_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	if _, err := tx.Exec(ctx, edit, id, m.UserID, old.Content, old.Tag, m.Content, m.Tag, m.EditedAt); err != nil {
+		return nil, fmt.Errorf("recording memory edit: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("committing memory update: %w", err)
+	}
+	return &m, nil
+}
+
+// GetEdits returns a memory's edit history, newest first.
+func (r *memoryRepo) GetEdits(ctx context.Context, memoryID uuid.UUID) ([]models.MemoryEdit, error) {
+	query := `
+		SELECT id, memory_id, previous_content, previous_tag, content, tag, edited_at
+		FROM memory_edits
+		WHERE memory_id = $1
+		ORDER BY edited_at DESC, id DESC`
+
+	rows, err := r.pool.Query(ctx, query, memoryID)
+	if err != nil {
+		return nil, fmt.Errorf("querying memory edits: %w", err)
+	}
+	defer rows.Close()
+
+	edits := []models.MemoryEdit{}
+	for rows.Next() {
+		var e models.MemoryEdit
+		if err := rows.Scan(&e.ID, &e.MemoryID, &e.PreviousContent, &e.PreviousTag, &e.Content, &e.Tag, &e.EditedAt); err != nil {
+			return nil, fmt.Errorf("scanning memory edit: %w", err)
+		}
+		edits = append(edits, e)
+	}
+	return edits, rows.Err()
+}
+
+// Reinforce restarts the forgetting curve of the given memories and raises
+// their importance by boost, up to 1.
+func (r *memoryRepo) Reinforce(ctx context.Context, ids []uuid.UUID, boost float64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := `
+		UPDATE memories
+		SET importance = least(1, importance + $2), last_recalled_at = NOW(), recall_count = recall_count + 1
+		WHERE id = ANY($1::uuid[])`
+
+	if _, err := r.pool.Exec(ctx, query, uuidStrings(ids), boost); err != nil {
+		return fmt.Errorf("reinforcing memories: %w", err)
+	}
+	return nil
+}
+
+// GetStrongest returns up to limit of the pair's memories with strength at
+// least minStrength at time at, strongest first.
+func (r *memoryRepo) GetStrongest(ctx context.Context, userID, companionID uuid.UUID, at time.Time, halfLifeDays, minStrength float64, limit int) ([]models.Memory, error) {
+	query := `
+		SELECT ` + memoryColumns + `
+		FROM (
+			SELECT *, memory_strength(importance, pinned, coalesce(last_recalled_at, created_at), $3, $4) AS strength
+			FROM memories
+			WHERE user_id = $1 AND companion_id = $2
+		) m
+		WHERE strength >= $5
+		ORDER BY strength DESC, created_at DESC
+		LIMIT $6`
+
+	rows, err := r.pool.Query(ctx, query, userID, companionID, at, halfLifeDays, minStrength, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying strongest memories: %w", err)
+	}
+	defer rows.Close()
+
+	memories := []models.Memory{}
+	for rows.Next() {
+		var m models.Memory
+		if err := scanMemory(rows, &m); err != nil {
+			return nil, fmt.Errorf("scanning memory: %w", err)
+		}
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// GetFading returns up to limit of the pair's unpinned memories whose
+// strength falls below threshold by until (including those already below
+// it), soonest to fade first. A memory fades when its importance has
+// halved log2(importance/threshold) times since it was last reinforced.
+func (r *memoryRepo) GetFading(ctx context.Context, userID, companionID uuid.UUID, at time.Time, halfLifeDays, threshold float64, until time.Time, limit int) ([]models.FadingMemory, error) {
+	query := `
+		SELECT ` + memoryColumns + `, strength, fades_at
+		FROM (
+			SELECT *,
+			       memory_strength(importance, pinned, coalesce(last_recalled_at, created_at), $3, $4) AS strength,
+			       coalesce(last_recalled_at, created_at)
+			           + make_interval(secs => greatest(log(2, importance::numeric / $5::numeric), 0) * $4 * 86400) AS fades_at
+			FROM memories
+			WHERE user_id = $1 AND companion_id = $2 AND NOT pinned AND importance > 0
+		) m
+		WHERE fades_at <= $6
+		ORDER BY fades_at ASC, id ASC
+		LIMIT $7`
+
+	rows, err := r.pool.Query(ctx, query, userID, companionID, at, halfLifeDays, threshold, until, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying fading memories: %w", err)
+	}
+	defer rows.Close()
+
+	fading := []models.FadingMemory{}
+	for rows.Next() {
+		var f models.FadingMemory
+		m := &f.Memory
+		if err := rows.Scan(&m.ID, &m.UserID, &m.CompanionID, &m.MessageID, &m.StoryMediaID, &m.Content, &m.Tag, &m.Pinned, &m.CreatedAt, &m.EditedAt,
+			&m.Importance, &m.LastRecalledAt, &m.RecallCount, &f.Strength, &f.FadesAt); err != nil {
+			return nil, fmt.Errorf("scanning fading memory: %w", err)
+		}
+		f.Faded = f.Strength < threshold
+		fading = append(fading, f)
+	}
+	return fading, rows.Err()
+}
+
+func equalTags(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// MemoryCollectionRepository defines data access operations for memory
+// collections.
+type MemoryCollectionRepository interface {
+	Create(ctx context.Context, c *models.MemoryCollection) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.MemoryCollection, error)
+	GetByUserAndCompanion(ctx context.Context, userID, companionID uuid.UUID) ([]models.MemoryCollection, error)
+	Update(ctx context.Context, c *models.MemoryCollection) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetMemories(ctx context.Context, collectionID uuid.UUID) ([]models.Memory, error)
+	GetMemoryIDs(ctx context.Context, collectionID uuid.UUID) ([]uuid.UUID, error)
+	AddMemory(ctx context.Context, collectionID, memoryID uuid.UUID) error
+	RemoveMemory(ctx context.Context, collectionID, memoryID uuid.UUID) error
+	ReorderMemories(ctx context.Context, collectionID uuid.UUID, memoryIDs []uuid.UUID) error
+}
+
+// ErrCollectionExists is returned when a collection name is already used
+// for the same user and companion.
+var ErrCollectionExists = errors.New("collection already exists")
+
+type memoryCollectionRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewMemoryCollectionRepository creates a new MemoryCollectionRepository backed by PostgreSQL.
+func NewMemoryCollectionRepository(pool *pgxpool.Pool) MemoryCollectionRepository {
+	return &memoryCollectionRepo{pool: pool}
+}
+
+// collectionColumns selects a collection (aliased c) with its memory count
+// and resolved cover.
+const collectionColumns = `c.id, c.user_id, c.companion_id, c.name, c.description, c.sort_order, c.created_at, c.updated_at,
+	c.cover_url,
+	(SELECT count(*) FROM memory_collection_items i WHERE i.collection_id = c.id)::int,
+	COALESCE(c.cover_url, (
+		SELECT COALESCE(sm.thumbnail_url, sm.media_url)
+		FROM memory_collection_items i
+		JOIN memories m ON m.id = i.memory_id
+		JOIN story_media sm ON sm.id = m.story_media_id
+		WHERE i.collection_id = c.id AND sm.media_type <> 'text'
+		ORDER BY i.sort_order, i.added_at
+		LIMIT 1
+	))`
+
+func scanCollection(row pgx.Row, c *models.MemoryCollection) error {
+	return row.Scan(&c.ID, &c.UserID, &c.CompanionID, &c.Name, &c.Description, &c.SortOrder, &c.CreatedAt, &c.UpdatedAt,
+		&c.ExplicitCoverURL, &c.MemoryCount, &c.CoverURL)
+}
+
+func (r *memoryCollectionRepo) Create(ctx context.Context, c *models.MemoryCollection) error {
+	query := `
+		INSERT INTO memory_collections (id, user_id, companion_id, name, description, cover_url, sort_order, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING created_at, updated_at`
+
+	err := r.pool.QueryRow(ctx, query, c.ID, c.UserID, c.CompanionID, c.Name, c.Description, c.ExplicitCoverURL, c.SortOrder).
+		Scan(&c.CreatedAt, &c.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrCollectionExists
+	}
+	return err
+}
+
+func (r *memoryCollectionRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.MemoryCollection, error) {
+	query := `SELECT ` + collectionColumns + ` FROM memory_collections c WHERE c.id = $1`
+
+	var c models.MemoryCollection
+	if err := scanCollection(r.pool.QueryRow(ctx, query, id), &c); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("collection not found")
+		}
+		return nil, fmt.Errorf("getting collection: %w", err)
+	}
+	return &c, nil
+}
+
+// GetByUserAndCompanion returns the pair's collections in display order,
+// without their memories.
+func (r *memoryCollectionRepo) GetByUserAndCompanion(ctx context.Context, userID, companionID uuid.UUID) ([]models.MemoryCollection, error) {
+	query := `
+		SELECT ` + collectionColumns + `
+		FROM memory_collections c
+		WHERE c.user_id = $1 AND c.companion_id = $2
+		ORDER BY c.sort_order, c.created_at`
+
+	rows, err := r.pool.Query(ctx, query, userID, companionID)
+	if err != nil {
+		return nil, fmt.Errorf("querying collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections := []models.MemoryCollection{}
+	for rows.Next() {
+		var c models.MemoryCollection
+		if err := scanCollection(rows, &c); err != nil {
+			return nil, fmt.Errorf("scanning collection: %w", err)
+		}
+		collections = append(collections, c)
+	}
+	return collections, rows.Err()
+}
+
+// Update saves a collection's name, description, explicit cover and order.
+func (r *memoryCollectionRepo) Update(ctx context.Context, c *models.MemoryCollection) error {
+	query := `
+		UPDATE memory_collections
+		SET name = $2, description = $3, cover_url = $4, sort_order = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.pool.QueryRow(ctx, query, c.ID, c.Name, c.Description, c.ExplicitCoverURL, c.SortOrder).Scan(&c.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("collection not found")
+		}
+		if isUniqueViolation(err) {
+			return ErrCollectionExists
+		}
+		return fmt.Errorf("updating collection: %w", err)
+	}
+	return nil
+}
+
+func (r *memoryCollectionRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM memory_collections WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting collection: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("collection not found")
+	}
+	return nil
+}
+
+// GetMemories returns a collection's memories in collection order.
+func (r *memoryCollectionRepo) GetMemories(ctx context.Context, collectionID uuid.UUID) ([]models.Memory, error) {
+	query := `
+		SELECT ` + prefixedMemoryColumns + `
+		FROM memory_collection_items i
+		JOIN memories m ON m.id = i.memory_id
+		WHERE i.collection_id = $1
+		ORDER BY i.sort_order, i.added_at`
+
+	rows, err := r.pool.Query(ctx, query, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("querying collection memories: %w", err)
+	}
+	defer rows.Close()
+
+	memories := []models.Memory{}
+	for rows.Next() {
+		var m models.Memory
+		if err := scanMemory(rows, &m); err != nil {
+			return nil, fmt.Errorf("scanning memory: %w", err)
+		}
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// GetMemoryIDs returns the IDs of a collection's memories in collection order.
+func (r *memoryCollectionRepo) GetMemoryIDs(ctx context.Context, collectionID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT memory_id FROM memory_collection_items
+		WHERE collection_id = $1
+		ORDER BY sort_order, added_at`
+
+	rows, err := r.pool.Query(ctx, query, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("querying collection items: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning collection item: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// AddMemory appends a memory to a collection; adding it twice is a no-op.
+func (r *memoryCollectionRepo) AddMemory(ctx context.Context, collectionID, memoryID uuid.UUID) error {
+	query := `
+		INSERT INTO memory_collection_items (collection_id, memory_id, sort_order, added_at)
+		VALUES ($1, $2,
+		        (SELECT COALESCE(MAX(sort_order) + 1, 0) FROM memory_collection_items WHERE collection_id = $1),
+		        NOW())
+		ON CONFLICT (collection_id, memory_id) DO NOTHING`
+
+	if _, err := r.pool.Exec(ctx, query, collectionID, memoryID); err != nil {
+		return fmt.Errorf("adding memory to collection: %w", err)
+	}
+	return nil
+}
+
+func (r *memoryCollectionRepo) RemoveMemory(ctx context.Context, collectionID, memoryID uuid.UUID) error {
+	query := `DELETE FROM memory_collection_items WHERE collection_id = $1 AND memory_id = $2`
+
+	tag, err := r.pool.Exec(ctx, query, collectionID, memoryID)
+	if err != nil {
+		return fmt.Errorf("removing memory from collection: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("memory is not in this collection")
+	}
+	return nil
+}
+
+// ReorderMemories sets each listed memory's position to its index in
+// memoryIDs.
+func (r *memoryCollectionRepo) ReorderMemories(ctx context.Context, collectionID uuid.UUID, memoryIDs []uuid.UUID) error {
+	query := `
+		UPDATE memory_collection_items i
+		SET sort_order = o.ord - 1
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS o(memory_id, ord)
+		WHERE i.collection_id = $1 AND i.memory_id = o.memory_id`
+
+	if _, err := r.pool.Exec(ctx, query, collectionID, uuidStrings(memoryIDs)); err != nil {
+		return fmt.Errorf("reordering collection: %w", err)
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
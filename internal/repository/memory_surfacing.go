@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// MemorySurfacingRepository defines data access operations for resurfaced
+// memories.
+type MemorySurfacingRepository interface {
+	PickDaily(ctx context.Context, day time.Time, cooldownDays, revisitAfterDays int, revisitImportance float64) ([]models.MemorySurfacing, error)
+	SetMessage(ctx context.Context, id, messageID uuid.UUID) error
+	GetCards(ctx context.Context, userID uuid.UUID, since time.Time) ([]models.MemorySurfacing, error)
+	Dismiss(ctx context.Context, userID, id uuid.UUID) error
+}
+
+type memorySurfacingRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewMemorySurfacingRepository creates a new MemorySurfacingRepository backed by PostgreSQL.
+func NewMemorySurfacingRepository(pool *pgxpool.Pool) MemorySurfacingRepository {
+	return &memorySurfacingRepo{pool: pool}
+}
+
+// PickDaily records at most one surfacing per user-companion pair for day
+// and returns the new ones, each with its memory. Pairs that already have
+// one for day are skipped, so re-running is harmless.
+//
+// Candidates, best first: memories saved on the same date in an earlier
+// year, then on the same day of an earlier month, then pinned memories or
+// those with at least revisitImportance, at least revisitAfterDays old and
+// least recently surfaced first. Ties go to the more important memory, then
+// the older one. Memories surfaced in the last cooldownDays are never picked.
+func (r *memorySurfacingRepo) PickDaily(ctx context.Context, day time.Time, cooldownDays, revisitAfterDays int, revisitImportance float64) ([]models.MemorySurfacing, error) {
+	query := `
+		WITH candidates AS (
+			SELECT m.id, m.user_id, m.companion_id, m.created_at, m.importance,
+			       CASE
+			           WHEN extract(day FROM d.saved) = extract(day FROM $1::date)
+			                AND d.saved < date_trunc('month', $1::date)
+			                AND extract(month FROM d.saved) = extract(month FROM $1::date) THEN 1
+			           WHEN extract(day FROM d.saved) = extract(day FROM $1::date)
+			                AND d.saved < date_trunc('month', $1::date) THEN 2
+			           ELSE 3
+			       END AS rank,
+			       (SELECT max(s.surfaced_on) FROM memory_surfacings s WHERE s.memory_id = m.id) AS last_surfaced
+			FROM memories m
+			CROSS JOIN LATERAL (SELECT (m.created_at AT TIME ZONE 'UTC')::date AS saved) d
+			WHERE (
+			        (extract(day FROM d.saved) = extract(day FROM $1::date) AND d.saved < date_trunc('month', $1::date))
+			        OR ((m.pinned OR m.importance >= $4) AND d.saved <= $1::date - $3::int)
+			      )
+			  AND NOT EXISTS (
+			        SELECT 1 FROM memory_surfacings s
+			        WHERE s.memory_id = m.id AND s.surfaced_on > $1::date - $2::int
+			      )
+		),
+		picked AS (
+			SELECT DISTINCT ON (user_id, companion_id) id, user_id, companion_id, rank
+			FROM candidates
+			ORDER BY user_id, companion_id, rank,
+			         CASE WHEN rank = 3 THEN last_surfaced END ASC NULLS FIRST,
+			         importance DESC, created_at ASC
+		),
+		inserted AS (
+			INSERT INTO memory_surfacings (memory_id, user_id, companion_id, reason, surfaced_on)
+			SELECT id, user_id, companion_id,
+			       CASE WHEN rank = 3 THEN 'revisit' ELSE 'on_this_day' END,
+			       $1::date
+			FROM picked
+			ON CONFLICT (user_id, companion_id, surfaced_on) DO NOTHING
+			RETURNING id, memory_id, user_id, companion_id, reason, surfaced_on, message_id, created_at
+		)
+		SELECT i.id, i.user_id, i.companion_id, c.name, i.reason, i.surfaced_on, i.message_id, i.created_at,
+		       ` + prefixedMemoryColumns + `
+		FROM inserted i
+		JOIN memories m ON m.id = i.memory_id
+		JOIN companions c ON c.id = i.companion_id`
+
+	return r.query(ctx, query, day.UTC().Format(time.DateOnly), cooldownDays, revisitAfterDays, revisitImportance)
+}
+
+// SetMessage links a surfacing to the companion message that brought it up.
+func (r *memorySurfacingRepo) SetMessage(ctx context.Context, id, messageID uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE memory_surfacings SET message_id = $2 WHERE id = $1`, id, messageID); err != nil {
+		return fmt.Errorf("linking surfacing message: %w", err)
+	}
+	return nil
+}
+
+// GetCards returns the user's undismissed surfacings from since onwards,
+// newest first.
+func (r *memorySurfacingRepo) GetCards(ctx context.Context, userID uuid.UUID, since time.Time) ([]models.MemorySurfacing, error) {
+	query := `
+		SELECT s.id, s.user_id, s.companion_id, c.name, s.reason, s.surfaced_on, s.message_id, s.created_at,
+		       ` + prefixedMemoryColumns + `
+		FROM memory_surfacings s
+		JOIN memories m ON m.id = s.memory_id
+		JOIN companions c ON c.id = s.companion_id
+		WHERE s.user_id = $1 AND s.surfaced_on >= $2::date AND s.dismissed_at IS NULL
+		ORDER BY s.surfaced_on DESC, c.name`
+
+	return r.query(ctx, query, userID, since.UTC().Format(time.DateOnly))
+}
+
+// Dismiss hides one of the user's cards from the feed.
+func (r *memorySurfacingRepo) Dismiss(ctx context.Context, userID, id uuid.UUID) error {
+	query := `
+		UPDATE memory_surfacings SET dismissed_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND dismissed_at IS NULL`
+
+	tag, err := r.pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("dismissing memory card: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("memory card not found")
+	}
+	return nil
+}
+
+func (r *memorySurfacingRepo) query(ctx context.Context, query string, args ...any) ([]models.MemorySurfacing, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying memory surfacings: %w", err)
+	}
+	defer rows.Close()
+
+	surfacings := []models.MemorySurfacing{}
+	for rows.Next() {
+		var s models.MemorySurfacing
+		m := &s.Memory
+		if err := rows.Scan(&s.ID, &s.UserID, &s.CompanionID, &s.CompanionName, &s.Reason, &s.SurfacedOn, &s.MessageID, &s.CreatedAt,
+			&m.ID, &m.UserID, &m.CompanionID, &m.MessageID, &m.StoryMediaID, &m.Content, &m.Tag, &m.Pinned, &m.CreatedAt, &m.EditedAt,
+			&m.Importance, &m.LastRecalledAt, &m.RecallCount,
+		); err != nil {
+			return nil, fmt.Errorf("scanning memory surfacing: %w", err)
+		}
+		surfacings = append(surfacings, s)
+	}
+	return surfacings, rows.Err()
+}
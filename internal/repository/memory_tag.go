@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// MemoryTagRepository defines data access operations for users' memory tag
+// vocabularies. Memories store the tag name, so renames and merges rewrite
+// every affected memory in the same transaction.
+type MemoryTagRepository interface {
+	Ensure(ctx context.Context, userID uuid.UUID, name string) error
+	Create(ctx context.Context, userID uuid.UUID, name string) (*models.MemoryTag, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.MemoryTag, error)
+	GetByUser(ctx context.Context, userID uuid.UUID) ([]models.MemoryTag, error)
+	CountByCompanion(ctx context.Context, userID, companionID uuid.UUID) ([]models.MemoryTagCount, error)
+	Rename(ctx context.Context, id uuid.UUID, name string) (*models.MemoryTag, error)
+	Merge(ctx context.Context, sourceID, targetID uuid.UUID) (*models.MemoryTag, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// ErrTagExists is returned when creating or renaming a tag to a name the
+// user already has.
+var ErrTagExists = errors.New("tag already exists")
+
+type memoryTagRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewMemoryTagRepository creates a new MemoryTagRepository backed by PostgreSQL.
+func NewMemoryTagRepository(pool *pgxpool.Pool) MemoryTagRepository {
+	return &memoryTagRepo{pool: pool}
+}
+
+// memoryTagColumns selects a tag with its memory count; the query must alias
+// memory_tags as t.
+const memoryTagColumns = `t.id, t.name, t.created_at,
+	(SELECT count(*) FROM memories m WHERE m.user_id = t.user_id AND m.tag = t.name)::int`
+
+// Ensure adds name to the user's vocabulary if it isn't there yet.
+func (r *memoryTagRepo) Ensure(ctx context.Context, userID uuid.UUID, name string) error {
+	query := `
+		INSERT INTO memory_tags (user_id, name) VALUES ($1, $2)
+		ON CONFLICT (user_id, name) DO NOTHING`
+
+	if _, err := r.pool.Exec(ctx, query, userID, name); err != nil {
+		return fmt.Errorf("adding memory tag: %w", err)
+	}
+	return nil
+}
+
+func (r *memoryTagRepo) Create(ctx context.Context, userID uuid.UUID, name string) (*models.MemoryTag, error) {
+	query := `
+		INSERT INTO memory_tags (user_id, name) VALUES ($1, $2)
+		ON CONFLICT (user_id, name) DO NOTHING
+		RETURNING id, name, created_at`
+
+	t := models.MemoryTag{UserID: userID}
+	if err := r.pool.QueryRow(ctx, query, userID, name).Scan(&t.ID, &t.Name, &t.CreatedAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrTagExists
+		}
+		return nil, fmt.Errorf("creating memory tag: %w", err)
+	}
+	return &t, nil
+}
+
+func (r *memoryTagRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.MemoryTag, error) {
+	query := `SELECT t.user_id, ` + memoryTagColumns + ` FROM memory_tags t WHERE t.id = $1`
+
+	var t models.MemoryTag
+	if err := r.pool.QueryRow(ctx, query, id).Scan(&t.UserID, &t.ID, &t.Name, &t.CreatedAt, &t.MemoryCount); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("tag not found")
+		}
+		return nil, fmt.Errorf("getting memory tag: %w", err)
+	}
+	return &t, nil
+}
+
+// GetByUser returns the user's vocabulary by name, with memory counts.
+func (r *memoryTagRepo) GetByUser(ctx context.Context, userID uuid.UUID) ([]models.MemoryTag, error) {
+	query := `
+		SELECT ` + memoryTagColumns + `
+		FROM memory_tags t
+		WHERE t.user_id = $1
+		ORDER BY t.name`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying memory tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []models.MemoryTag{}
+	for rows.Next() {
+		var t models.MemoryTag
+		if err := rows.Scan(&t.ID, &t.Name, &t.CreatedAt, &t.MemoryCount); err != nil {
+			return nil, fmt.Errorf("scanning memory tag: %w", err)
+		}
+		t.UserID = userID
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// CountByCompanion returns how many of the user's memories with a companion
+// carry each tag, most used first.
+func (r *memoryTagRepo) CountByCompanion(ctx context.Context, userID, companionID uuid.UUID) ([]models.MemoryTagCount, error) {
+	query := `
+		SELECT tag, count(*)::int
+		FROM memories
+		WHERE user_id = $1 AND companion_id = $2 AND tag IS NOT NULL
+		GROUP BY tag
+		ORDER BY count(*) DESC, tag`
+
+	rows, err := r.pool.Query(ctx, query, userID, companionID)
+	if err != nil {
+		return nil, fmt.Errorf("counting memory tags: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []models.MemoryTagCount{}
+	for rows.Next() {
+		var c models.MemoryTagCount
+		if err := rows.Scan(&c.Tag, &c.Count); err != nil {
+			return nil, fmt.Errorf("scanning memory tag count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// Rename changes a tag's name and rewrites it on the user's memories.
+func (r *memoryTagRepo) Rename(ctx context.Context, id uuid.UUID, name string) (*models.MemoryTag, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning tag rename: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID uuid.UUID
+	var old string
+	err = tx.QueryRow(ctx, `SELECT user_id, name FROM memory_tags WHERE id = $1 FOR UPDATE`, id).Scan(&userID, &old)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("tag not found")
+		}
+		return nil, fmt.Errorf("getting memory tag: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE memory_tags SET name = $2 WHERE id = $1`, id, name); err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrTagExists
+		}
+		return nil, fmt.Errorf("renaming memory tag: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE memories SET tag = $3 WHERE user_id = $1 AND tag = $2`, userID, old, name); err != nil {
+		return nil, fmt.Errorf("retagging memories: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("committing tag rename: %w", err)
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Merge moves every memory tagged with the source tag to the target tag and
+// removes the source. Both tags must belong to the same user.
+func (r *memoryTagRepo) Merge(ctx context.Context, sourceID, targetID uuid.UUID) (*models.MemoryTag, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning tag merge: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		DELETE FROM memory_tags s
+		USING memory_tags t
+		WHERE s.id = $1 AND t.id = $2 AND t.user_id = s.user_id
+		RETURNING s.user_id, s.name, t.name`
+
+	var userID uuid.UUID
+	var source, target string
+	if err := tx.QueryRow(ctx, query, sourceID, targetID).Scan(&userID, &source, &target); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("tag not found")
+		}
+		return nil, fmt.Errorf("merging memory tags: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE memories SET tag = $3 WHERE user_id = $1 AND tag = $2`, userID, source, target); err != nil {
+		return nil, fmt.Errorf("retagging memories: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("committing tag merge: %w", err)
+	}
+	return r.GetByID(ctx, targetID)
+}
+
+// Delete removes a tag from the vocabulary and from the user's memories.
+func (r *memoryTagRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning tag delete: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID uuid.UUID
+	var name string
+	if err := tx.QueryRow(ctx, `DELETE FROM memory_tags WHERE id = $1 RETURNING user_id, name`, id).Scan(&userID, &name); err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("tag not found")
+		}
+		return fmt.Errorf("deleting memory tag: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE memories SET tag = NULL WHERE user_id = $1 AND tag = $2`, userID, name); err != nil {
+		return fmt.Errorf("untagging memories: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing tag delete: %w", err)
+	}
+	return nil
+}
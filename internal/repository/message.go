@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"ai-companion-be/internal/models"
@@ -14,7 +15,11 @@ import (
 // MessageRepository defines data access operations for chat messages.
 type MessageRepository interface {
 	Create(ctx context.Context, msg *models.Message) error
-	GetByConversation(ctx context.Context, userID, companionID uuid.UUID, cursor *time.Time, limit int) (*models.MessagePage, error)
+	GetBefore(ctx context.Context, userID, companionID uuid.UUID, before *models.MessageKey, inclusive bool, limit int) ([]models.Message, error)
+	GetAfter(ctx context.Context, userID, companionID uuid.UUID, after models.MessageKey, limit int) ([]models.Message, error)
+	GetKey(ctx context.Context, userID, companionID, messageID uuid.UUID) (*models.MessageKey, error)
+	GetWithNeighbours(ctx context.Context, userID, companionID uuid.UUID, ids []uuid.UUID) ([]models.Message, error)
+	GetBetween(ctx context.Context, userID, companionID uuid.UUID, from, to time.Time, limit int) ([]models.Message, error)
 }
 
 type messageRepo struct {
@@ -28,76 +33,148 @@ func NewMessageRepository(pool *pgxpool.Pool) MessageRepository {
 
 func (r *messageRepo) Create(ctx context.Context, msg *models.Message) error {
 	query := `
-		INSERT INTO messages (id, user_id, companion_id, content, role, created_at)
-		VALUES ($1, $2, $3, $4, $5, NOW())
+		INSERT INTO messages (id, user_id, companion_id, content, role, story_media_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6::uuid, NOW())
 		RETURNING created_at`
 
+	// Convert *uuid.UUID to *string for PgBouncer simple-protocol compatibility.
+	var storyMediaID *string
+	if msg.StoryMediaID != nil {
+		s := msg.StoryMediaID.String()
+		storyMediaID = &s
+	}
+
 	return r.pool.QueryRow(ctx, query,
-		msg.ID, msg.UserID, msg.CompanionID, msg.Content, msg.Role,
+		msg.ID, msg.UserID, msg.CompanionID, msg.Content, msg.Role, storyMediaID,
 	).Scan(&msg.CreatedAt)
 }
 
-func (r *messageRepo) GetByConversation(ctx context.Context, userID, companionID uuid.UUID, cursor *time.Time, limit int) (*models.MessagePage, error) {
-	if limit <= 0 || limit > 50 {
-		limit = 20
+// messageColumns is the column list shared by the conversation queries.
+const messageColumns = `m.id, m.user_id, m.companion_id, m.content, m.role, m.created_at, m.story_media_id,
+	(EXISTS(SELECT 1 FROM memories mem WHERE mem.message_id = m.id)) AS is_memorized`
+
+// GetBefore returns up to limit messages older than before (or the newest
+// messages when before is nil), newest first. With inclusive, the message
+// at before is included.
+func (r *messageRepo) GetBefore(ctx context.Context, userID, companionID uuid.UUID, before *models.MessageKey, inclusive bool, limit int) ([]models.Message, error) {
+	if before == nil {
+		query := `
+			SELECT ` + messageColumns + `
+			FROM messages m
+			WHERE m.user_id = $1 AND m.companion_id = $2
+			ORDER BY m.created_at DESC, m.id DESC
+			LIMIT $3`
+		return r.query(ctx, query, userID, companionID, limit)
 	}
 
-	// Fetch one extra to determine if there are more results.
-	fetchLimit := limit + 1
+	op := "<"
+	if inclusive {
+		op = "<="
+	}
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages m
+		WHERE m.user_id = $1 AND m.companion_id = $2 AND (m.created_at, m.id) ` + op + ` ($3, $4)
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT $5`
+	return r.query(ctx, query, userID, companionID, before.CreatedAt, before.ID, limit)
+}
 
-	var query string
-	var args []any
+// GetAfter returns up to limit messages newer than after, oldest first.
+func (r *messageRepo) GetAfter(ctx context.Context, userID, companionID uuid.UUID, after models.MessageKey, limit int) ([]models.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages m
+		WHERE m.user_id = $1 AND m.companion_id = $2 AND (m.created_at, m.id) > ($3, $4)
+		ORDER BY m.created_at ASC, m.id ASC
+		LIMIT $5`
+	return r.query(ctx, query, userID, companionID, after.CreatedAt, after.ID, limit)
+}
 
-	if cursor != nil {
-		query = `
-			SELECT m.id, m.user_id, m.companion_id, m.content, m.role, m.created_at,
-			       (EXISTS(SELECT 1 FROM memories mem WHERE mem.message_id = m.id)) AS is_memorized
-			FROM messages m
-			WHERE m.user_id = $1 AND m.companion_id = $2 AND m.created_at < $3
-			ORDER BY m.created_at DESC
-			LIMIT $4`
-		args = []any{userID, companionID, *cursor, fetchLimit}
-	} else {
-		query = `
-			SELECT m.id, m.user_id, m.companion_id, m.content, m.role, m.created_at,
-			       (EXISTS(SELECT 1 FROM memories mem WHERE mem.message_id = m.id)) AS is_memorized
-			FROM messages m
-			WHERE m.user_id = $1 AND m.companion_id = $2
-			ORDER BY m.created_at DESC
-			LIMIT $3`
-		args = []any{userID, companionID, fetchLimit}
+// GetBetween returns up to limit messages sent from from up to to, oldest
+// first.
+func (r *messageRepo) GetBetween(ctx context.Context, userID, companionID uuid.UUID, from, to time.Time, limit int) ([]models.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages m
+		WHERE m.user_id = $1 AND m.companion_id = $2 AND m.created_at >= $3 AND m.created_at < $4
+		ORDER BY m.created_at ASC, m.id ASC
+		LIMIT $5`
+	return r.query(ctx, query, userID, companionID, from, to, limit)
+}
+
+// GetKey returns a message's keyset position, checking it belongs to the
+// conversation.
+func (r *messageRepo) GetKey(ctx context.Context, userID, companionID, messageID uuid.UUID) (*models.MessageKey, error) {
+	query := `
+		SELECT created_at, id
+		FROM messages
+		WHERE id = $1 AND user_id = $2 AND companion_id = $3`
+
+	var k models.MessageKey
+	if err := r.pool.QueryRow(ctx, query, messageID, userID, companionID).Scan(&k.CreatedAt, &k.ID); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("message not found")
+		}
+		return nil, fmt.Errorf("getting message: %w", err)
+	}
+	return &k, nil
+}
+
+// GetWithNeighbours returns the conversation's messages with the given IDs
+// together with the message just before and just after each, oldest first.
+// In the result, each requested message's neighbours are the entries
+// either side of it.
+func (r *messageRepo) GetWithNeighbours(ctx context.Context, userID, companionID uuid.UUID, ids []uuid.UUID) ([]models.Message, error) {
+	if len(ids) == 0 {
+		return []models.Message{}, nil
 	}
 
+	query := `
+		WITH hits AS (
+			SELECT created_at, id FROM messages
+			WHERE id = ANY($3::uuid[]) AND user_id = $1 AND companion_id = $2
+		)
+		SELECT ` + messageColumns + `
+		FROM messages m
+		WHERE m.id IN (
+			SELECT id FROM hits
+			UNION
+			SELECT p.id FROM hits h
+			CROSS JOIN LATERAL (
+				SELECT id FROM messages
+				WHERE user_id = $1 AND companion_id = $2 AND (created_at, id) < (h.created_at, h.id)
+				ORDER BY created_at DESC, id DESC
+				LIMIT 1
+			) p
+			UNION
+			SELECT n.id FROM hits h
+			CROSS JOIN LATERAL (
+				SELECT id FROM messages
+				WHERE user_id = $1 AND companion_id = $2 AND (created_at, id) > (h.created_at, h.id)
+				ORDER BY created_at ASC, id ASC
+				LIMIT 1
+			) n
+		)
+		ORDER BY m.created_at ASC, m.id ASC`
+	return r.query(ctx, query, userID, companionID, uuidStrings(ids))
+}
+
+func (r *messageRepo) query(ctx context.Context, query string, args ...any) ([]models.Message, error) {
 	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying messages: %w", err)
 	}
 	defer rows.Close()
 
-	var messages []models.Message
+	messages := []models.Message{}
 	for rows.Next() {
 		var m models.Message
-		if err := rows.Scan(&m.ID, &m.UserID, &m.CompanionID, &m.Content, &m.Role, &m.CreatedAt, &m.IsMemorized); err != nil {
+		if err := rows.Scan(&m.ID, &m.UserID, &m.CompanionID, &m.Content, &m.Role, &m.CreatedAt, &m.StoryMediaID, &m.IsMemorized); err != nil {
 			return nil, fmt.Errorf("scanning message: %w", err)
 		}
 		messages = append(messages, m)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	page := &models.MessagePage{HasMore: false}
-
-	if len(messages) > limit {
-		page.HasMore = true
-		messages = messages[:limit]
-	}
-
-	page.Messages = messages
-	if len(messages) > 0 {
-		last := messages[len(messages)-1].CreatedAt.Format(time.RFC3339Nano)
-		page.NextCursor = last
-	}
 
-	return page, nil
+	return messages, rows.Err()
 }
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// MilestoneRepository defines data access operations for milestone
+// definitions and the milestones pairs have achieved.
+type MilestoneRepository interface {
+	GetDefinitions(ctx context.Context, activeOnly bool) ([]models.MilestoneDefinition, error)
+	GetForPair(ctx context.Context, userID, companionID uuid.UUID) ([]models.Milestone, error)
+	Achieve(ctx context.Context, userID, companionID uuid.UUID, key string, at time.Time) (bool, error)
+	SetMessage(ctx context.Context, userID, companionID uuid.UUID, key string, messageID uuid.UUID) error
+	GetRecent(ctx context.Context, userID uuid.UUID, limit int) ([]models.CompanionMilestone, error)
+}
+
+type milestoneRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewMilestoneRepository creates a new MilestoneRepository backed by PostgreSQL.
+func NewMilestoneRepository(pool *pgxpool.Pool) MilestoneRepository {
+	return &milestoneRepo{pool: pool}
+}
+
+// GetDefinitions returns milestone definitions in display order.
+func (r *milestoneRepo) GetDefinitions(ctx context.Context, activeOnly bool) ([]models.MilestoneDefinition, error) {
+	query := `
+		SELECT key, title, description, metric, threshold::float8, display_order, active
+		FROM milestone_definitions
+		WHERE active OR NOT $1
+		ORDER BY display_order, key`
+
+	rows, err := r.pool.Query(ctx, query, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("querying milestone definitions: %w", err)
+	}
+	defer rows.Close()
+
+	defs := []models.MilestoneDefinition{}
+	for rows.Next() {
+		var d models.MilestoneDefinition
+		if err := rows.Scan(&d.Key, &d.Title, &d.Description, &d.Metric, &d.Threshold, &d.DisplayOrder, &d.Active); err != nil {
+			return nil, fmt.Errorf("scanning milestone definition: %w", err)
+		}
+		defs = append(defs, d)
+	}
+	return defs, rows.Err()
+}
+
+// GetForPair returns the pair's milestones in display order: every active
+// definition, plus inactive ones the pair achieved.
+func (r *milestoneRepo) GetForPair(ctx context.Context, userID, companionID uuid.UUID) ([]models.Milestone, error) {
+	query := `
+		SELECT d.key, d.title, d.description, um.achieved_at, um.message_id
+		FROM milestone_definitions d
+		LEFT JOIN user_milestones um
+		       ON um.milestone_key = d.key AND um.user_id = $1 AND um.companion_id = $2
+		WHERE d.active OR um.id IS NOT NULL
+		ORDER BY d.display_order, d.key`
+
+	rows, err := r.pool.Query(ctx, query, userID, companionID)
+	if err != nil {
+		return nil, fmt.Errorf("querying milestones: %w", err)
+	}
+	defer rows.Close()
+
+	milestones := []models.Milestone{}
+	for rows.Next() {
+		var m models.Milestone
+		if err := rows.Scan(&m.Key, &m.Title, &m.Description, &m.AchievedAt, &m.MessageID); err != nil {
+			return nil, fmt.Errorf("scanning milestone: %w", err)
+		}
+		m.Achieved = m.AchievedAt != nil
+		milestones = append(milestones, m)
+	}
+	return milestones, rows.Err()
+}
+
+// Achieve records that the pair achieved a milestone at at. It reports
+// false if the milestone was already recorded, so concurrent checks
+// celebrate it only once.
+func (r *milestoneRepo) Achieve(ctx context.Context, userID, companionID uuid.UUID, key string, at time.Time) (bool, error) {
+	query := `
+		INSERT INTO user_milestones (user_id, companion_id, milestone_key, achieved_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, companion_id, milestone_key) DO NOTHING
+		RETURNING id`
+
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, query, userID, companionID, key, at).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("recording milestone: %w", err)
+	}
+	return true, nil
+}
+
+// SetMessage links a milestone to the companion's celebratory message.
+func (r *milestoneRepo) SetMessage(ctx context.Context, userID, companionID uuid.UUID, key string, messageID uuid.UUID) error {
+	query := `
+		UPDATE user_milestones SET message_id = $4
+		WHERE user_id = $1 AND companion_id = $2 AND milestone_key = $3`
+
+	if _, err := r.pool.Exec(ctx, query, userID, companionID, key, messageID); err != nil {
+		return fmt.Errorf("linking milestone message: %w", err)
+	}
+	return nil
+}
+
+// GetRecent returns the user's latest achievements across all companions,
+// most recent first.
+func (r *milestoneRepo) GetRecent(ctx context.Context, userID uuid.UUID, limit int) ([]models.CompanionMilestone, error) {
+	query := `
+		SELECT d.key, d.title, d.description, um.achieved_at, um.message_id, um.companion_id, c.name
+		FROM user_milestones um
+		JOIN milestone_definitions d ON d.key = um.milestone_key
+		JOIN companions c ON c.id = um.companion_id
+		WHERE um.user_id = $1
+		ORDER BY um.achieved_at DESC, d.display_order DESC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying recent milestones: %w", err)
+	}
+	defer rows.Close()
+
+	milestones := []models.CompanionMilestone{}
+	for rows.Next() {
+		var m models.CompanionMilestone
+		if err := rows.Scan(&m.Key, &m.Title, &m.Description, &m.AchievedAt, &m.MessageID, &m.CompanionID, &m.CompanionName); err != nil {
+			return nil, fmt.Errorf("scanning recent milestone: %w", err)
+		}
+		m.Achieved = true
+		milestones = append(milestones, m)
+	}
+	return milestones, rows.Err()
+}
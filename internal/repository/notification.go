@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// NotificationRepository defines data access operations for notifications.
+type NotificationRepository interface {
+	Create(ctx context.Context, n *models.Notification) error
+	List(ctx context.Context, userID uuid.UUID, unreadOnly bool, after *models.NotificationKey, limit int) ([]models.Notification, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int, error)
+	MarkRead(ctx context.Context, userID, id uuid.UUID) (*models.Notification, error)
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+type notificationRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewNotificationRepository creates a new NotificationRepository backed by PostgreSQL.
+func NewNotificationRepository(pool *pgxpool.Pool) NotificationRepository {
+	return &notificationRepo{pool: pool}
+}
+
+const notificationColumns = `id, user_id, companion_id, kind, title, body, data, created_at, read_at`
+
+func scanNotification(row pgx.Row, n *models.Notification) error {
+	return row.Scan(&n.ID, &n.UserID, &n.CompanionID, &n.Kind, &n.Title, &n.Body, &n.Data, &n.CreatedAt, &n.ReadAt)
+}
+
+func (r *notificationRepo) Create(ctx context.Context, n *models.Notification) error {
+	query := `
+		INSERT INTO notifications (id, user_id, companion_id, kind, title, body, data, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb, NOW())
+		RETURNING created_at`
+
+	data := n.Data
+	if data == nil {
+		data = []byte("{}")
+	}
+	err := r.pool.QueryRow(ctx, query, n.ID, n.UserID, n.CompanionID, n.Kind, n.Title, n.Body, string(data)).Scan(&n.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("creating notification: %w", err)
+	}
+	n.Data = data
+	return nil
+}
+
+// List returns up to limit of the user's notifications after the given
+// key, newest first.
+func (r *notificationRepo) List(ctx context.Context, userID uuid.UUID, unreadOnly bool, after *models.NotificationKey, limit int) ([]models.Notification, error) {
+	var afterAt *time.Time
+	var afterID *string
+	if after != nil {
+		id := after.ID.String()
+		afterAt, afterID = &after.CreatedAt, &id
+	}
+
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE user_id = $1
+		  AND (read_at IS NULL OR NOT $2)
+		  AND ($3::timestamptz IS NULL OR (created_at, id) < ($3::timestamptz, $4::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $5`
+
+	rows, err := r.pool.Query(ctx, query, userID, unreadOnly, afterAt, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		if err := scanNotification(rows, &n); err != nil {
+			return nil, fmt.Errorf("scanning notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *notificationRepo) CountUnread(ctx context.Context, userID uuid.UUID) (int, error) {
+	var n int
+	err := r.pool.QueryRow(ctx, `SELECT count(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("counting unread notifications: %w", err)
+	}
+	return n, nil
+}
+
+// MarkRead marks one of the user's notifications read. Marking a read
+// notification again keeps its original read time.
+func (r *notificationRepo) MarkRead(ctx context.Context, userID, id uuid.UUID) (*models.Notification, error) {
+	query := `
+		UPDATE notifications SET read_at = coalesce(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + notificationColumns
+
+	var n models.Notification
+	if err := scanNotification(r.pool.QueryRow(ctx, query, id, userID), &n); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("notification not found")
+		}
+		return nil, fmt.Errorf("marking notification read: %w", err)
+	}
+	return &n, nil
+}
+
+// MarkAllRead marks all of the user's notifications read and returns how
+// many were unread.
+func (r *notificationRepo) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("marking notifications read: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
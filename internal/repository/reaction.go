@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// ReactionRepository defines data access operations for the reaction catalogue.
+type ReactionRepository interface {
+	GetAll(ctx context.Context, activeOnly bool) ([]models.ReactionType, error)
+	GetByKey(ctx context.Context, key string) (*models.ReactionType, error)
+	Upsert(ctx context.Context, rt *models.ReactionType) error
+}
+
+type reactionRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewReactionRepository creates a new ReactionRepository backed by PostgreSQL.
+func NewReactionRepository(pool *pgxpool.Pool) ReactionRepository {
+	return &reactionRepo{pool: pool}
+}
+
+// GetAll returns reaction types in display order.
+func (r *reactionRepo) GetAll(ctx context.Context, activeOnly bool) ([]models.ReactionType, error) {
+	query := `
+		SELECT key, emoji, label, display_order, sentiment_weight, active
+		FROM reaction_types
+		WHERE active OR NOT $1
+		ORDER BY display_order, key`
+
+	rows, err := r.pool.Query(ctx, query, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("querying reaction types: %w", err)
+	}
+	defer rows.Close()
+
+	types := []models.ReactionType{}
+	for rows.Next() {
+		var rt models.ReactionType
+		if err := rows.Scan(&rt.Key, &rt.Emoji, &rt.Label, &rt.DisplayOrder, &rt.SentimentWeight, &rt.Active); err != nil {
+			return nil, fmt.Errorf("scanning reaction type: %w", err)
+		}
+		types = append(types, rt)
+	}
+
+	return types, rows.Err()
+}
+
+func (r *reactionRepo) GetByKey(ctx context.Context, key string) (*models.ReactionType, error) {
+	query := `
+		SELECT key, emoji, label, display_order, sentiment_weight, active
+		FROM reaction_types
+		WHERE key = $1`
+
+	var rt models.ReactionType
+	err := r.pool.QueryRow(ctx, query, key).
+		Scan(&rt.Key, &rt.Emoji, &rt.Label, &rt.DisplayOrder, &rt.SentimentWeight, &rt.Active)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("reaction type not found")
+		}
+		return nil, fmt.Errorf("getting reaction type: %w", err)
+	}
+	return &rt, nil
+}
+
+func (r *reactionRepo) Upsert(ctx context.Context, rt *models.ReactionType) error {
+	query := `
+		INSERT INTO reaction_types (key, emoji, label, display_order, sentiment_weight, active, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET emoji = $2, label = $3, display_order = $4, sentiment_weight = $5, active = $6`
+
+	if _, err := r.pool.Exec(ctx, query, rt.Key, rt.Emoji, rt.Label, rt.DisplayOrder, rt.SentimentWeight, rt.Active); err != nil {
+		return fmt.Errorf("saving reaction type: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// RecapRepository defines data access operations for weekly recaps.
+type RecapRepository interface {
+	GetDuePairs(ctx context.Context) ([]models.RecapPair, error)
+	Create(ctx context.Context, recap *models.WeeklyRecap) (bool, error)
+	List(ctx context.Context, userID, companionID uuid.UUID, before *string, limit int) ([]models.WeeklyRecap, error)
+	GetByID(ctx context.Context, userID, id uuid.UUID) (*models.WeeklyRecap, error)
+}
+
+type recapRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewRecapRepository creates a new RecapRepository backed by PostgreSQL.
+func NewRecapRepository(pool *pgxpool.Pool) RecapRepository {
+	return &recapRepo{pool: pool}
+}
+
+const recapColumns = `id, user_id, companion_id, week_start, messages, highlights, memories, mood, milestones, note, created_at`
+
+func scanRecap(row pgx.Row) (*models.WeeklyRecap, error) {
+	var rc models.WeeklyRecap
+	var weekStart time.Time
+	var highlights, memories, mood, milestones []byte
+	err := row.Scan(&rc.ID, &rc.UserID, &rc.CompanionID, &weekStart, &rc.Messages,
+		&highlights, &memories, &mood, &milestones, &rc.Note, &rc.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	rc.WeekStart = weekStart.Format(time.DateOnly)
+	rc.WeekEnd = weekStart.AddDate(0, 0, 6).Format(time.DateOnly)
+
+	for _, f := range []struct {
+		name string
+		data []byte
+		v    any
+	}{
+		{"highlights", highlights, &rc.Highlights},
+		{"memories", memories, &rc.Memories},
+		{"mood", mood, &rc.Mood},
+		{"milestones", milestones, &rc.Milestones},
+	} {
+		if err := json.Unmarshal(f.data, f.v); err != nil {
+			return nil, fmt.Errorf("decoding recap %s: %w", f.name, err)
+		}
+	}
+	return &rc, nil
+}
+
+// GetDuePairs returns the relationships the user wrote in during their
+// last full local week (Monday to Sunday) that have no recap for it yet.
+func (r *recapRepo) GetDuePairs(ctx context.Context) ([]models.RecapPair, error) {
+	query := `
+		SELECT rs.user_id, rs.companion_id, u.timezone, w.week_start::text, c.messages
+		FROM relationship_states rs
+		JOIN users u ON u.id = rs.user_id
+		CROSS JOIN LATERAL (
+			SELECT date_trunc('week', NOW() AT TIME ZONE u.timezone)::date - 7 AS week_start
+		) w
+		CROSS JOIN LATERAL (
+			SELECT count(*) AS messages, count(*) FILTER (WHERE m.role = 'user') AS user_messages
+			FROM messages m
+			WHERE m.user_id = rs.user_id AND m.companion_id = rs.companion_id
+			  AND m.created_at >= w.week_start::timestamp AT TIME ZONE u.timezone
+			  AND m.created_at < (w.week_start + 7)::timestamp AT TIME ZONE u.timezone
+		) c
+		WHERE c.user_messages > 0
+		  AND NOT EXISTS (
+			SELECT 1 FROM weekly_recaps wr
+			WHERE wr.user_id = rs.user_id AND wr.companion_id = rs.companion_id AND wr.week_start = w.week_start
+		  )
+		ORDER BY rs.user_id, rs.companion_id`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("querying pairs due a recap: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []models.RecapPair
+	for rows.Next() {
+		var p models.RecapPair
+		if err := rows.Scan(&p.UserID, &p.CompanionID, &p.Timezone, &p.WeekStart, &p.Messages); err != nil {
+			return nil, fmt.Errorf("scanning pair due a recap: %w", err)
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, rows.Err()
+}
+
+// Create stores a recap unless the pair already has one for the week,
+// reporting whether it was stored.
+func (r *recapRepo) Create(ctx context.Context, recap *models.WeeklyRecap) (bool, error) {
+	// The jsonb columns are sent as text; simple-protocol mode can't infer
+	// jsonb from a struct.
+	encoded := make([]string, 4)
+	for i, v := range []any{recap.Highlights, recap.Memories, recap.Mood, recap.Milestones} {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return false, fmt.Errorf("encoding recap: %w", err)
+		}
+		encoded[i] = string(b)
+	}
+
+	query := `
+		INSERT INTO weekly_recaps (id, user_id, companion_id, week_start, messages,
+		                           highlights, memories, mood, milestones, note, created_at)
+		VALUES ($1, $2, $3, $4::date, $5, $6::jsonb, $7::jsonb, $8::jsonb, $9::jsonb, $10, NOW())
+		ON CONFLICT (user_id, companion_id, week_start) DO NOTHING
+		RETURNING created_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		recap.ID, recap.UserID, recap.CompanionID, recap.WeekStart, recap.Messages,
+		encoded[0], encoded[1], encoded[2], encoded[3], recap.Note,
+	).Scan(&recap.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("creating recap: %w", err)
+	}
+	return true, nil
+}
+
+// List returns up to limit of the pair's recaps for weeks before before
+// (all weeks when nil), newest first.
+func (r *recapRepo) List(ctx context.Context, userID, companionID uuid.UUID, before *string, limit int) ([]models.WeeklyRecap, error) {
+	query := `
+		SELECT ` + recapColumns + `
+		FROM weekly_recaps
+		WHERE user_id = $1 AND companion_id = $2 AND ($3::date IS NULL OR week_start < $3::date)
+		ORDER BY week_start DESC
+		LIMIT $4`
+
+	rows, err := r.pool.Query(ctx, query, userID, companionID, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying recaps: %w", err)
+	}
+	defer rows.Close()
+
+	recaps := []models.WeeklyRecap{}
+	for rows.Next() {
+		rc, err := scanRecap(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning recap: %w", err)
+		}
+		recaps = append(recaps, *rc)
+	}
+	return recaps, rows.Err()
+}
+
+// GetByID returns one of the user's recaps.
+func (r *recapRepo) GetByID(ctx context.Context, userID, id uuid.UUID) (*models.WeeklyRecap, error) {
+	query := `SELECT ` + recapColumns + ` FROM weekly_recaps WHERE id = $1 AND user_id = $2`
+
+	rc, err := scanRecap(r.pool.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("recap not found")
+		}
+		return nil, fmt.Errorf("getting recap: %w", err)
+	}
+	return rc, nil
+}
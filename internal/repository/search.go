@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// SearchRepository defines full-text search over messages and memories.
+type SearchRepository interface {
+	Search(ctx context.Context, userID uuid.UUID, filter models.SearchFilter, after *models.SearchKey, limit int) ([]models.SearchResult, error)
+}
+
+// Snippet match markers. ts_headline output is not HTML-safe, so matches are
+// wrapped in control characters that callers swap for markup after escaping.
+const (
+	SearchMatchStart = "\x01"
+	SearchMatchStop  = "\x02"
+)
+
+const searchHeadlineOptions = "StartSel=" + SearchMatchStart + ", StopSel=" + SearchMatchStop +
+	", MaxWords=30, MinWords=12, MaxFragments=2, FragmentDelimiter=\" … \""
+
+type searchRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewSearchRepository creates a new SearchRepository backed by PostgreSQL.
+func NewSearchRepository(pool *pgxpool.Pool) SearchRepository {
+	return &searchRepo{pool: pool}
+}
+
+// Search returns up to limit of the user's messages and memories matching
+// filter, newest first, starting after the given position (or from the
+// newest when after is nil). Each branch is limited before the union and
+// snippets are only built for the final page.
+func (r *searchRepo) Search(ctx context.Context, userID uuid.UUID, filter models.SearchFilter, after *models.SearchKey, limit int) ([]models.SearchResult, error) {
+	args := []any{userID, filter.Query}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// conds returns the shared conditions for a branch over table alias t.
+	conds := func(t string) string {
+		c := []string{t + ".user_id = $1", t + ".search_vector @@ q.query"}
+		if filter.CompanionID != nil {
+			c = append(c, t+".companion_id = "+arg(*filter.CompanionID))
+		}
+		if filter.From != nil {
+			c = append(c, t+".created_at >= "+arg(*filter.From))
+		}
+		if filter.To != nil {
+			c = append(c, t+".created_at < "+arg(*filter.To))
+		}
+		if after != nil {
+			c = append(c, fmt.Sprintf("(%s.created_at, %s.id) < (%s, %s)", t, t, arg(after.CreatedAt), arg(after.ID)))
+		}
+		return strings.Join(c, " AND ")
+	}
+	limitArg := arg(limit)
+
+	var branches []string
+	if filter.Type == "" || filter.Type == models.SearchTypeMessage {
+		branches = append(branches, `(
+			SELECT 'message' AS type, m.id, m.companion_id, m.role, m.content, m.created_at,
+			       ts_rank(m.search_vector, q.query) AS rank, m.id AS message_id, m.created_at AS message_created_at
+			FROM messages m, q
+			WHERE `+conds("m")+`
+			ORDER BY m.created_at DESC, m.id DESC
+			LIMIT `+limitArg+`
+		)`)
+	}
+	if filter.Type == "" || filter.Type == models.SearchTypeMemory {
+		branches = append(branches, `(
+			SELECT 'memory' AS type, mem.id, mem.companion_id, NULL::text AS role, mem.content, mem.created_at,
+			       ts_rank(mem.search_vector, q.query) AS rank, src.id AS message_id, src.created_at AS message_created_at
+			FROM memories mem
+			CROSS JOIN q
+			LEFT JOIN messages src ON src.id = mem.message_id
+			WHERE `+conds("mem")+`
+			ORDER BY mem.created_at DESC, mem.id DESC
+			LIMIT `+limitArg+`
+		)`)
+	}
+
+	query := `
+		WITH q AS (SELECT websearch_to_tsquery('english', $2) AS query),
+		hits AS (
+			SELECT * FROM (` + strings.Join(branches, " UNION ALL ") + `) u
+			ORDER BY created_at DESC, id DESC
+			LIMIT ` + limitArg + `
+		)
+		SELECT h.type, h.id, h.companion_id, c.name, h.role, h.created_at, h.rank,
+		       ts_headline('english', h.content, q.query, ` + arg(searchHeadlineOptions) + `),
+		       h.message_id, h.message_created_at
+		FROM hits h
+		CROSS JOIN q
+		JOIN companions c ON c.id = h.companion_id
+		ORDER BY h.created_at DESC, h.id DESC`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+	for rows.Next() {
+		var res models.SearchResult
+		var rank float32
+		if err := rows.Scan(&res.Type, &res.ID, &res.CompanionID, &res.CompanionName, &res.Role, &res.CreatedAt, &rank,
+			&res.Snippet, &res.MessageID, &res.MessageCreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning search result: %w", err)
+		}
+		res.Rank = float64(rank)
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
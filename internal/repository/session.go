@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// SessionRepository defines data access operations for login sessions and
+// their refresh tokens, which are identified by hash.
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session, tokenHash string) error
+	Rotate(ctx context.Context, oldHash, newHash string, device models.DeviceInfo, expiresAt time.Time) (*models.Session, error)
+	IsActive(ctx context.Context, userID, id uuid.UUID) (bool, error)
+	ListActive(ctx context.Context, userID uuid.UUID) ([]models.Session, error)
+	Revoke(ctx context.Context, userID, id uuid.UUID, reason string) error
+	RevokeOthers(ctx context.Context, userID, keepID uuid.UUID, reason string) (int64, error)
+	DeleteEndedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ErrRefreshTokenReused is returned when a refresh token that was already
+// rotated is presented again. Its session has been revoked.
+var ErrRefreshTokenReused = errors.New("refresh token reused")
+
+type sessionRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewSessionRepository creates a new SessionRepository backed by PostgreSQL.
+func NewSessionRepository(pool *pgxpool.Pool) SessionRepository {
+	return &sessionRepo{pool: pool}
+}
+
+const sessionColumns = `id, user_id, user_agent, ip, created_at, last_used_at, expires_at, revoked_at, revoked_reason`
+
+func scanSession(row pgx.Row) (*models.Session, error) {
+	var s models.Session
+	err := row.Scan(&s.ID, &s.UserID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastUsedAt,
+		&s.ExpiresAt, &s.RevokedAt, &s.RevokedReason)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Create starts a session with its first refresh token.
+func (r *sessionRepo) Create(ctx context.Context, session *models.Session, tokenHash string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning session create: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO sessions (id, user_id, user_agent, ip, created_at, last_used_at, expires_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW(), $5)
+		RETURNING created_at, last_used_at`
+
+	err = tx.QueryRow(ctx, query, session.ID, session.UserID, session.UserAgent, session.IP, session.ExpiresAt).
+		Scan(&session.CreatedAt, &session.LastUsedAt)
+	if err != nil {
+		return fmt.Errorf("creating session: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO refresh_tokens (token_hash, session_id) VALUES ($1, $2)`, tokenHash, session.ID); err != nil {
+		return fmt.Errorf("creating refresh token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing session create: %w", err)
+	}
+	return nil
+}
+
+// Rotate exchanges the refresh token hashed as oldHash for newHash,
+// recording the device the session is now used from and extending it to
+// expiresAt. The session row is locked, so concurrent refreshes of one
+// session are serialised and only the first succeeds. Presenting an
+// already rotated token revokes the session and returns
+// ErrRefreshTokenReused.
+func (r *sessionRepo) Rotate(ctx context.Context, oldHash, newHash string, device models.DeviceInfo, expiresAt time.Time) (*models.Session, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning token rotation: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		SELECT rt.session_id, rt.rotated_at IS NOT NULL, s.revoked_at IS NULL AND s.expires_at > NOW()
+		FROM refresh_tokens rt
+		JOIN sessions s ON s.id = rt.session_id
+		WHERE rt.token_hash = $1
+		FOR UPDATE OF rt, s`
+
+	var sessionID uuid.UUID
+	var rotated, active bool
+	if err := tx.QueryRow(ctx, query, oldHash).Scan(&sessionID, &rotated, &active); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("refresh token not found")
+		}
+		return nil, fmt.Errorf("getting refresh token: %w", err)
+	}
+
+	if rotated {
+		_, err := tx.Exec(ctx, `
+			UPDATE sessions SET revoked_at = NOW(), revoked_reason = $2
+			WHERE id = $1 AND revoked_at IS NULL`, sessionID, models.SessionRevokedReuse)
+		if err != nil {
+			return nil, fmt.Errorf("revoking session: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("committing session revocation: %w", err)
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if !active {
+		return nil, fmt.Errorf("session expired or revoked")
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET rotated_at = NOW() WHERE token_hash = $1`, oldHash); err != nil {
+		return nil, fmt.Errorf("rotating refresh token: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO refresh_tokens (token_hash, session_id) VALUES ($1, $2)`, newHash, sessionID); err != nil {
+		return nil, fmt.Errorf("creating refresh token: %w", err)
+	}
+
+	update := `
+		UPDATE sessions SET user_agent = $2, ip = $3, last_used_at = NOW(), expires_at = $4
+		WHERE id = $1
+		RETURNING ` + sessionColumns
+
+	session, err := scanSession(tx.QueryRow(ctx, update, sessionID, device.UserAgent, device.IP, expiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("updating session: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("committing token rotation: %w", err)
+	}
+	return session, nil
+}
+
+// IsActive reports whether the user's session exists and is neither
+// revoked nor expired.
+func (r *sessionRepo) IsActive(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM sessions
+			WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+		)`
+
+	var active bool
+	if err := r.pool.QueryRow(ctx, query, id, userID).Scan(&active); err != nil {
+		return false, fmt.Errorf("checking session: %w", err)
+	}
+	return active, nil
+}
+
+// ListActive returns the user's active sessions, most recently used first.
+func (r *sessionRepo) ListActive(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning session: %w", err)
+		}
+		sessions = append(sessions, *s)
+	}
+	return sessions, rows.Err()
+}
+
+// Revoke ends one of the user's active sessions.
+func (r *sessionRepo) Revoke(ctx context.Context, userID, id uuid.UUID, reason string) error {
+	query := `
+		UPDATE sessions SET revoked_at = NOW(), revoked_reason = $3
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()`
+
+	tag, err := r.pool.Exec(ctx, query, id, userID, reason)
+	if err != nil {
+		return fmt.Errorf("revoking session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("session not found")
+	}
+	return nil
+}
+
+// RevokeOthers ends every active session of the user except keepID,
+// returning how many were ended.
+func (r *sessionRepo) RevokeOthers(ctx context.Context, userID, keepID uuid.UUID, reason string) (int64, error) {
+	query := `
+		UPDATE sessions SET revoked_at = NOW(), revoked_reason = $3
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL AND expires_at > NOW()`
+
+	tag, err := r.pool.Exec(ctx, query, userID, keepID, reason)
+	if err != nil {
+		return 0, fmt.Errorf("revoking sessions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteEndedBefore removes sessions, and their refresh tokens, that were
+// revoked or expired before before.
+func (r *sessionRepo) DeleteEndedBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE coalesce(revoked_at, expires_at) < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting ended sessions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
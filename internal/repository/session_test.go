@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+func createTestSession(t *testing.T, repo SessionRepository, userID uuid.UUID, tokenHash string, expiresAt time.Time) *models.Session {
+	t.Helper()
+	session := &models.Session{ID: uuid.New(), UserID: userID, UserAgent: "test", IP: "127.0.0.1", ExpiresAt: expiresAt}
+	if err := repo.Create(context.Background(), session, tokenHash); err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+func sessionRevokedReason(t *testing.T, pool *pgxpool.Pool, id uuid.UUID) *string {
+	t.Helper()
+	var reason *string
+	if err := pool.QueryRow(context.Background(), `SELECT revoked_reason FROM sessions WHERE id = $1`, id).Scan(&reason); err != nil {
+		t.Fatal(err)
+	}
+	return reason
+}
+
+func TestSessionRotateReuseRevokes(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	repo := NewSessionRepository(pool)
+	userID := createTestUser(t, pool, "UTC")
+	hash := func(name string) string { return userID.String() + "/" + name }
+
+	expiresAt := time.Now().Add(time.Hour)
+	session := createTestSession(t, repo, userID, hash("first"), expiresAt)
+
+	device := models.DeviceInfo{UserAgent: "phone", IP: "10.0.0.2"}
+	rotated, err := repo.Rotate(ctx, hash("first"), hash("second"), device, expiresAt.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("first rotation: %v", err)
+	}
+	if rotated.ID != session.ID || rotated.UserAgent != device.UserAgent || rotated.IP != device.IP {
+		t.Errorf("rotated session = %+v, want session %s used from %+v", rotated, session.ID, device)
+	}
+	if !rotated.ExpiresAt.After(expiresAt) {
+		t.Errorf("expires_at = %v, want extended past %v", rotated.ExpiresAt, expiresAt)
+	}
+	if active, err := repo.IsActive(ctx, userID, session.ID); err != nil || !active {
+		t.Fatalf("IsActive after rotation = %v, %v; want true", active, err)
+	}
+
+	// The first token again: it leaked, so the whole session goes.
+	if _, err := repo.Rotate(ctx, hash("first"), hash("stolen"), device, expiresAt); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reusing a rotated token: err = %v, want ErrRefreshTokenReused", err)
+	}
+	if active, err := repo.IsActive(ctx, userID, session.ID); err != nil || active {
+		t.Fatalf("IsActive after reuse = %v, %v; want false", active, err)
+	}
+	if reason := sessionRevokedReason(t, pool, session.ID); reason == nil || *reason != models.SessionRevokedReuse {
+		t.Errorf("revoked_reason = %v, want %q", reason, models.SessionRevokedReuse)
+	}
+
+	// Every token in the chain stops working, including the latest.
+	if _, err := repo.Rotate(ctx, hash("second"), hash("third"), device, expiresAt); err == nil || errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("rotating the latest token of a revoked session: err = %v, want session expired or revoked", err)
+	}
+	if _, err := repo.Rotate(ctx, hash("stolen"), hash("fourth"), device, expiresAt); err == nil {
+		t.Error("the token issued on reuse was stored")
+	}
+	sessions, err := repo.ListActive(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("ListActive = %d sessions, want none", len(sessions))
+	}
+}
+
+func TestSessionRotateInactive(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	repo := NewSessionRepository(pool)
+	userID := createTestUser(t, pool, "UTC")
+	hash := func(name string) string { return userID.String() + "/" + name }
+	device := models.DeviceInfo{UserAgent: "phone"}
+
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T) string
+	}{
+		{"expired", func(t *testing.T) string {
+			createTestSession(t, repo, userID, hash("expired"), time.Now().Add(-time.Minute))
+			return hash("expired")
+		}},
+		{"logged out", func(t *testing.T) string {
+			s := createTestSession(t, repo, userID, hash("logged-out"), time.Now().Add(time.Hour))
+			if err := repo.Revoke(ctx, userID, s.ID, models.SessionRevokedLogout); err != nil {
+				t.Fatal(err)
+			}
+			return hash("logged-out")
+		}},
+		{"revoked elsewhere", func(t *testing.T) string {
+			s := createTestSession(t, repo, userID, hash("revoked"), time.Now().Add(time.Hour))
+			keep := createTestSession(t, repo, userID, hash("kept"), time.Now().Add(time.Hour))
+			if n, err := repo.RevokeOthers(ctx, userID, keep.ID, models.SessionRevokedByUser); err != nil || n == 0 {
+				t.Fatalf("RevokeOthers = %d, %v", n, err)
+			}
+			if active, err := repo.IsActive(ctx, userID, s.ID); err != nil || active {
+				t.Fatalf("IsActive = %v, %v; want false", active, err)
+			}
+			return hash("revoked")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := tt.prepare(t)
+			// Refused both times, and never mistaken for reuse: a refused
+			// rotation leaves the token as it was.
+			for range 2 {
+				_, err := repo.Rotate(ctx, old, old+"/next", device, time.Now().Add(time.Hour))
+				if err == nil || errors.Is(err, ErrRefreshTokenReused) {
+					t.Fatalf("err = %v, want session expired or revoked", err)
+				}
+			}
+		})
+	}
+
+	if _, err := repo.Rotate(ctx, hash("unknown"), hash("next"), device, time.Now().Add(time.Hour)); err == nil {
+		t.Error("rotating an unknown token succeeded")
+	}
+}
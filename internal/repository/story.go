@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -15,10 +16,29 @@ import (
 // StoryRepository defines data access operations for stories.
 type StoryRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Story, error)
-	GetByCompanionID(ctx context.Context, companionID uuid.UUID) ([]models.Story, error)
+	GetByCompanionID(ctx context.Context, companionID uuid.UUID, mode models.StoryListMode) ([]models.Story, error)
 	GetActiveStories(ctx context.Context, cursor *time.Time, limit int) (*models.StoryPage, error)
 	GetActiveStoriesGrouped(ctx context.Context, userID uuid.UUID) (*models.GroupedStoryPage, error)
 	CreateReaction(ctx context.Context, reaction *models.StoryReaction) error
+	GetReaction(ctx context.Context, userID, mediaID uuid.UUID) (*models.StoryReaction, error)
+	DeleteReaction(ctx context.Context, userID, storyID, mediaID uuid.UUID) (*models.StoryReaction, error)
+	Create(ctx context.Context, story *models.Story) error
+	CreateMedia(ctx context.Context, media *models.StoryMedia) error
+	GetMediaByID(ctx context.Context, id uuid.UUID) (*models.StoryMedia, error)
+	GetRecentInteractions(ctx context.Context, userID, companionID uuid.UUID, since time.Time, limit int) ([]models.StoryInteraction, error)
+
+	CreateHighlight(ctx context.Context, highlight *models.StoryHighlight) error
+	GetHighlightByID(ctx context.Context, id uuid.UUID) (*models.StoryHighlight, error)
+	GetHighlights(ctx context.Context, companionID uuid.UUID) ([]models.StoryHighlight, error)
+	DeleteHighlight(ctx context.Context, id uuid.UUID) error
+	AddToHighlight(ctx context.Context, highlightID, storyID uuid.UUID) error
+	RemoveFromHighlight(ctx context.Context, highlightID, storyID uuid.UUID) error
+
+	SaveStory(ctx context.Context, userID, storyID uuid.UUID) error
+	UnsaveStory(ctx context.Context, userID, storyID uuid.UUID) error
+	GetSavedStories(ctx context.Context, userID uuid.UUID) ([]models.Story, error)
+
+	CleanupExpired(ctx context.Context) (int, error)
 }
 
 type storyRepo struct {
@@ -48,13 +68,25 @@ func (r *storyRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Story, e
 	return &s, nil
 }
 
-func (r *storyRepo) GetByCompanionID(ctx context.Context, companionID uuid.UUID) ([]models.Story, error) {
+func (r *storyRepo) GetByCompanionID(ctx context.Context, companionID uuid.UUID, mode models.StoryListMode) ([]models.Story, error) {
 	query := `
-		SELECT s.id, s.companion_id, s.created_at, s.expires_at
+		SELECT s.id, s.companion_id, s.created_at, s.expires_at,
+		       EXISTS(SELECT 1 FROM story_highlight_items hi WHERE hi.story_id = s.id) AS highlighted
 		FROM stories s
 		WHERE s.companion_id = $1 AND s.expires_at > NOW()
 		ORDER BY s.created_at DESC`
 
+	if mode == models.StoryListWithHighlights {
+		query = `
+			SELECT s.id, s.companion_id, s.created_at, s.expires_at,
+			       EXISTS(SELECT 1 FROM story_highlight_items hi WHERE hi.story_id = s.id) AS highlighted
+			FROM stories s
+			WHERE s.companion_id = $1
+			  AND (s.expires_at > NOW()
+			       OR EXISTS(SELECT 1 FROM story_highlight_items hi WHERE hi.story_id = s.id))
+			ORDER BY s.created_at DESC`
+	}
+
 	rows, err := r.pool.Query(ctx, query, companionID)
 	if err != nil {
 		return nil, fmt.Errorf("querying stories: %w", err)
@@ -64,7 +96,7 @@ func (r *storyRepo) GetByCompanionID(ctx context.Context, companionID uuid.UUID)
 	var stories []models.Story
 	for rows.Next() {
 		var s models.Story
-		if err := rows.Scan(&s.ID, &s.CompanionID, &s.CreatedAt, &s.ExpiresAt); err != nil {
+		if err := rows.Scan(&s.ID, &s.CompanionID, &s.CreatedAt, &s.ExpiresAt, &s.Highlighted); err != nil {
 			return nil, fmt.Errorf("scanning story: %w", err)
 		}
 		stories = append(stories, s)
@@ -210,16 +242,393 @@ func (r *storyRepo) GetActiveStoriesGrouped(ctx context.Context, userID uuid.UUI
 	return &models.GroupedStoryPage{Companions: companions}, nil
 }
 
+// CreateReaction records a reaction, replacing the user's previous reaction
+// to the same slide. A replacement keeps created_at, which the activity
+// rollups count by, and moves updated_at, which reaction trends count by,
+// only if the reaction changed.
 func (r *storyRepo) CreateReaction(ctx context.Context, reaction *models.StoryReaction) error {
 	query := `
-		INSERT INTO story_reactions (id, user_id, story_id, media_id, reaction, created_at)
-		VALUES ($1, $2, $3, $4, $5, NOW())
-		ON CONFLICT (user_id, media_id) DO UPDATE SET reaction = $5
-		RETURNING created_at`
+		INSERT INTO story_reactions AS sr (id, user_id, story_id, media_id, reaction, mood_delta, relationship_delta, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		ON CONFLICT (user_id, media_id) DO UPDATE
+		SET reaction = $5, mood_delta = $6, relationship_delta = $7,
+		    updated_at = CASE WHEN sr.reaction = $5 THEN sr.updated_at ELSE NOW() END
+		RETURNING id, created_at`
 
 	return r.pool.QueryRow(ctx, query,
 		reaction.ID, reaction.UserID, reaction.StoryID, reaction.MediaID, reaction.Reaction,
-	).Scan(&reaction.CreatedAt)
+		reaction.MoodDelta, reaction.RelationshipDelta,
+	).Scan(&reaction.ID, &reaction.CreatedAt)
+}
+
+// GetReaction returns the user's reaction to a slide, or nil if there is none.
+func (r *storyRepo) GetReaction(ctx context.Context, userID, mediaID uuid.UUID) (*models.StoryReaction, error) {
+	query := `
+		SELECT id, user_id, story_id, media_id, reaction, mood_delta, relationship_delta, created_at
+		FROM story_reactions
+		WHERE user_id = $1 AND media_id = $2`
+
+	var sr models.StoryReaction
+	err := r.pool.QueryRow(ctx, query, userID, mediaID).Scan(
+		&sr.ID, &sr.UserID, &sr.StoryID, &sr.MediaID, &sr.Reaction, &sr.MoodDelta, &sr.RelationshipDelta, &sr.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting reaction: %w", err)
+	}
+	return &sr, nil
+}
+
+// DeleteReaction removes the user's reaction to a slide of a story and
+// returns it, so its relationship effect can be reversed.
+func (r *storyRepo) DeleteReaction(ctx context.Context, userID, storyID, mediaID uuid.UUID) (*models.StoryReaction, error) {
+	query := `
+		DELETE FROM story_reactions
+		WHERE user_id = $1 AND story_id = $2 AND media_id = $3
+		RETURNING id, user_id, story_id, media_id, reaction, mood_delta, relationship_delta, created_at`
+
+	var sr models.StoryReaction
+	err := r.pool.QueryRow(ctx, query, userID, storyID, mediaID).Scan(
+		&sr.ID, &sr.UserID, &sr.StoryID, &sr.MediaID, &sr.Reaction, &sr.MoodDelta, &sr.RelationshipDelta, &sr.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("reaction not found")
+		}
+		return nil, fmt.Errorf("deleting reaction: %w", err)
+	}
+	return &sr, nil
+}
+
+// Create inserts a story together with its slides, story.Media, in one
+// transaction, so a story is never left without them.
+func (r *storyRepo) Create(ctx context.Context, story *models.Story) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning story create: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := createStory(ctx, tx, story); err != nil {
+		return err
+	}
+	for i := range story.Media {
+		if err := createStoryMedia(ctx, tx, &story.Media[i]); err != nil {
+			return fmt.Errorf("creating story media: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing story create: %w", err)
+	}
+	return nil
+}
+
+// CreateMedia appends a slide to a story; sort_order is assigned after the existing slides.
+func (r *storyRepo) CreateMedia(ctx context.Context, media *models.StoryMedia) error {
+	return createStoryMedia(ctx, r.pool, media)
+}
+
+// rowQuerier is satisfied by both the pool and a transaction.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func createStory(ctx context.Context, q rowQuerier, story *models.Story) error {
+	query := `
+		INSERT INTO stories (id, companion_id, created_at, expires_at)
+		VALUES ($1, $2, NOW(), $3)
+		RETURNING created_at`
+
+	return q.QueryRow(ctx, query, story.ID, story.CompanionID, story.ExpiresAt).Scan(&story.CreatedAt)
+}
+
+func createStoryMedia(ctx context.Context, q rowQuerier, media *models.StoryMedia) error {
+	query := `
+		INSERT INTO story_media (id, story_id, media_url, media_type, duration, sort_order,
+		                         thumbnail_url, caption, link_url, link_label, background, created_at)
+		VALUES ($1, $2, $3, $4, $5,
+		        (SELECT COALESCE(MAX(sort_order) + 1, 0) FROM story_media WHERE story_id = $2),
+		        $6, $7, $8, $9, $10::jsonb, NOW())
+		RETURNING sort_order, created_at`
+
+	// Encode background ourselves; simple-protocol mode can't infer jsonb from a struct.
+	var background *string
+	if media.Background != nil {
+		b, err := json.Marshal(media.Background)
+		if err != nil {
+			return fmt.Errorf("encoding slide background: %w", err)
+		}
+		s := string(b)
+		background = &s
+	}
+
+	return q.QueryRow(ctx, query,
+		media.ID, media.StoryID, media.MediaURL, media.MediaType, media.Duration,
+		media.ThumbnailURL, media.Caption, media.LinkURL, media.LinkLabel, background,
+	).Scan(&media.SortOrder, &media.CreatedAt)
+}
+
+func (r *storyRepo) GetMediaByID(ctx context.Context, id uuid.UUID) (*models.StoryMedia, error) {
+	query := `SELECT ` + storyMediaColumns + ` FROM story_media WHERE id = $1`
+
+	m, err := scanStoryMedia(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("story media not found")
+		}
+		return nil, fmt.Errorf("getting story media: %w", err)
+	}
+	return m, nil
+}
+
+// GetRecentInteractions returns the user's reactions to a companion's story
+// slides since the given time, newest first, with each slide's caption.
+func (r *storyRepo) GetRecentInteractions(ctx context.Context, userID, companionID uuid.UUID, since time.Time, limit int) ([]models.StoryInteraction, error) {
+	query := `
+		SELECT sm.media_type, sm.caption, sr.reaction, COALESCE(rt.label, sr.reaction), rt.emoji, sr.created_at
+		FROM story_reactions sr
+		JOIN story_media sm ON sm.id = sr.media_id
+		JOIN stories s ON s.id = sm.story_id
+		LEFT JOIN reaction_types rt ON rt.key = sr.reaction
+		WHERE sr.user_id = $1 AND s.companion_id = $2 AND sr.created_at >= $3
+		ORDER BY sr.created_at DESC
+		LIMIT $4`
+
+	rows, err := r.pool.Query(ctx, query, userID, companionID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying story interactions: %w", err)
+	}
+	defer rows.Close()
+
+	var interactions []models.StoryInteraction
+	for rows.Next() {
+		var si models.StoryInteraction
+		if err := rows.Scan(&si.MediaType, &si.Caption, &si.Reaction, &si.ReactionLabel, &si.ReactionEmoji, &si.At); err != nil {
+			return nil, fmt.Errorf("scanning story interaction: %w", err)
+		}
+		interactions = append(interactions, si)
+	}
+
+	return interactions, rows.Err()
+}
+
+func (r *storyRepo) CreateHighlight(ctx context.Context, h *models.StoryHighlight) error {
+	query := `
+		INSERT INTO story_highlights (id, companion_id, title, cover_url, sort_order, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING created_at`
+
+	return r.pool.QueryRow(ctx, query, h.ID, h.CompanionID, h.Title, h.CoverURL, h.SortOrder).Scan(&h.CreatedAt)
+}
+
+func (r *storyRepo) GetHighlightByID(ctx context.Context, id uuid.UUID) (*models.StoryHighlight, error) {
+	query := `
+		SELECT id, companion_id, title, cover_url, sort_order, created_at
+		FROM story_highlights
+		WHERE id = $1`
+
+	var h models.StoryHighlight
+	err := r.pool.QueryRow(ctx, query, id).
+		Scan(&h.ID, &h.CompanionID, &h.Title, &h.CoverURL, &h.SortOrder, &h.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("highlight not found")
+		}
+		return nil, fmt.Errorf("getting highlight: %w", err)
+	}
+	return &h, nil
+}
+
+// GetHighlights returns a companion's highlights with their stories (expired
+// or not), batch-loading stories and media in two further queries.
+func (r *storyRepo) GetHighlights(ctx context.Context, companionID uuid.UUID) ([]models.StoryHighlight, error) {
+	query := `
+		SELECT id, companion_id, title, cover_url, sort_order, created_at
+		FROM story_highlights
+		WHERE companion_id = $1
+		ORDER BY sort_order, created_at`
+
+	rows, err := r.pool.Query(ctx, query, companionID)
+	if err != nil {
+		return nil, fmt.Errorf("querying highlights: %w", err)
+	}
+	defer rows.Close()
+
+	var highlights []models.StoryHighlight
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var h models.StoryHighlight
+		if err := rows.Scan(&h.ID, &h.CompanionID, &h.Title, &h.CoverURL, &h.SortOrder, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning highlight: %w", err)
+		}
+		h.Stories = []models.Story{}
+		index[h.ID] = len(highlights)
+		highlights = append(highlights, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(highlights) == 0 {
+		return highlights, nil
+	}
+
+	itemsQuery := `
+		SELECT hi.highlight_id, s.id, s.companion_id, s.created_at, s.expires_at
+		FROM story_highlight_items hi
+		JOIN stories s ON s.id = hi.story_id
+		JOIN story_highlights h ON h.id = hi.highlight_id
+		WHERE h.companion_id = $1
+		ORDER BY hi.sort_order, hi.added_at`
+
+	itemRows, err := r.pool.Query(ctx, itemsQuery, companionID)
+	if err != nil {
+		return nil, fmt.Errorf("querying highlight stories: %w", err)
+	}
+	defer itemRows.Close()
+
+	var stories []models.Story
+	var owners []uuid.UUID
+	for itemRows.Next() {
+		var hid uuid.UUID
+		s := models.Story{Highlighted: true}
+		if err := itemRows.Scan(&hid, &s.ID, &s.CompanionID, &s.CreatedAt, &s.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("scanning highlight story: %w", err)
+		}
+		stories = append(stories, s)
+		owners = append(owners, hid)
+	}
+	if err := itemRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// loadMedia indexes by story ID, so load each story once even if it's in
+	// several highlights, then fan out.
+	unique := make([]models.Story, 0, len(stories))
+	seen := make(map[uuid.UUID]int)
+	for _, s := range stories {
+		if _, ok := seen[s.ID]; !ok {
+			seen[s.ID] = len(unique)
+			unique = append(unique, s)
+		}
+	}
+	unique, err = r.loadMedia(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, s := range stories {
+		h := &highlights[index[owners[i]]]
+		h.Stories = append(h.Stories, unique[seen[s.ID]])
+	}
+
+	return highlights, nil
+}
+
+func (r *storyRepo) DeleteHighlight(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM story_highlights WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting highlight: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("highlight not found")
+	}
+	return nil
+}
+
+// AddToHighlight appends a story to a highlight; adding it twice is a no-op.
+func (r *storyRepo) AddToHighlight(ctx context.Context, highlightID, storyID uuid.UUID) error {
+	query := `
+		INSERT INTO story_highlight_items (highlight_id, story_id, sort_order, added_at)
+		VALUES ($1, $2,
+		        (SELECT COALESCE(MAX(sort_order) + 1, 0) FROM story_highlight_items WHERE highlight_id = $1),
+		        NOW())
+		ON CONFLICT (highlight_id, story_id) DO NOTHING`
+
+	if _, err := r.pool.Exec(ctx, query, highlightID, storyID); err != nil {
+		return fmt.Errorf("adding story to highlight: %w", err)
+	}
+	return nil
+}
+
+func (r *storyRepo) RemoveFromHighlight(ctx context.Context, highlightID, storyID uuid.UUID) error {
+	query := `DELETE FROM story_highlight_items WHERE highlight_id = $1 AND story_id = $2`
+
+	tag, err := r.pool.Exec(ctx, query, highlightID, storyID)
+	if err != nil {
+		return fmt.Errorf("removing story from highlight: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("story is not in this highlight")
+	}
+	return nil
+}
+
+func (r *storyRepo) SaveStory(ctx context.Context, userID, storyID uuid.UUID) error {
+	query := `
+		INSERT INTO saved_stories (user_id, story_id, saved_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id, story_id) DO NOTHING`
+
+	if _, err := r.pool.Exec(ctx, query, userID, storyID); err != nil {
+		return fmt.Errorf("saving story: %w", err)
+	}
+	return nil
+}
+
+func (r *storyRepo) UnsaveStory(ctx context.Context, userID, storyID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM saved_stories WHERE user_id = $1 AND story_id = $2`, userID, storyID)
+	if err != nil {
+		return fmt.Errorf("unsaving story: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("story is not saved")
+	}
+	return nil
+}
+
+// GetSavedStories returns a user's saved stories, most recently saved first,
+// including ones that have expired.
+func (r *storyRepo) GetSavedStories(ctx context.Context, userID uuid.UUID) ([]models.Story, error) {
+	query := `
+		SELECT s.id, s.companion_id, s.created_at, s.expires_at, ss.saved_at,
+		       EXISTS(SELECT 1 FROM story_highlight_items hi WHERE hi.story_id = s.id) AS highlighted
+		FROM saved_stories ss
+		JOIN stories s ON s.id = ss.story_id
+		WHERE ss.user_id = $1
+		ORDER BY ss.saved_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("querying saved stories: %w", err)
+	}
+	defer rows.Close()
+
+	var stories []models.Story
+	for rows.Next() {
+		var s models.Story
+		if err := rows.Scan(&s.ID, &s.CompanionID, &s.CreatedAt, &s.ExpiresAt, &s.SavedAt, &s.Highlighted); err != nil {
+			return nil, fmt.Errorf("scanning saved story: %w", err)
+		}
+		stories = append(stories, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return r.loadMedia(ctx, stories)
+}
+
+// CleanupExpired deletes stories that expired over an hour ago, except
+// highlighted or saved ones, and returns how many were deleted.
+func (r *storyRepo) CleanupExpired(ctx context.Context) (int, error) {
+	var n int
+	if err := r.pool.QueryRow(ctx, `SELECT cleanup_expired_stories()`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("cleaning up expired stories: %w", err)
+	}
+	return n, nil
 }
 
 // loadMedia batch-loads media for a list of stories to avoid N+1 queries.
@@ -236,7 +645,7 @@ func (r *storyRepo) loadMedia(ctx context.Context, stories []models.Story) ([]mo
 	}
 
 	mediaQuery := `
-		SELECT id, story_id, media_url, media_type, duration, sort_order, created_at
+		SELECT ` + storyMediaColumns + `
 		FROM story_media
 		WHERE story_id = ANY($1::uuid[])
 		ORDER BY sort_order`
@@ -248,12 +657,12 @@ func (r *storyRepo) loadMedia(ctx context.Context, stories []models.Story) ([]mo
 	defer mediaRows.Close()
 
 	for mediaRows.Next() {
-		var m models.StoryMedia
-		if err := mediaRows.Scan(&m.ID, &m.StoryID, &m.MediaURL, &m.MediaType, &m.Duration, &m.SortOrder, &m.CreatedAt); err != nil {
+		m, err := scanStoryMedia(mediaRows)
+		if err != nil {
 			return nil, fmt.Errorf("scanning story media: %w", err)
 		}
 		if idx, ok := storyMap[m.StoryID]; ok {
-			stories[idx].Media = append(stories[idx].Media, m)
+			stories[idx].Media = append(stories[idx].Media, *m)
 		}
 	}
 	if err := mediaRows.Err(); err != nil {
@@ -262,3 +671,22 @@ func (r *storyRepo) loadMedia(ctx context.Context, stories []models.Story) ([]mo
 
 	return stories, nil
 }
+
+const storyMediaColumns = `id, story_id, media_url, media_type, duration, sort_order, created_at,
+		       thumbnail_url, caption, link_url, link_label, background`
+
+func scanStoryMedia(row pgx.Row) (*models.StoryMedia, error) {
+	var m models.StoryMedia
+	var background []byte
+	if err := row.Scan(&m.ID, &m.StoryID, &m.MediaURL, &m.MediaType, &m.Duration, &m.SortOrder, &m.CreatedAt,
+		&m.ThumbnailURL, &m.Caption, &m.LinkURL, &m.LinkLabel, &background); err != nil {
+		return nil, err
+	}
+	if len(background) > 0 {
+		m.Background = &models.StoryBackground{}
+		if err := json.Unmarshal(background, m.Background); err != nil {
+			return nil, fmt.Errorf("decoding slide background: %w", err)
+		}
+	}
+	return &m, nil
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ai-companion-be/internal/models"
+)
+
+// StoryDraftRepository defines data access operations for generated story drafts.
+type StoryDraftRepository interface {
+	Create(ctx context.Context, draft *models.StoryDraft) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.StoryDraft, error)
+	GetByStatus(ctx context.Context, status string, limit int) ([]models.StoryDraft, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string, storyID *uuid.UUID) error
+	Publish(ctx context.Context, id uuid.UUID, story *models.Story, media []models.StoryMedia) error
+	GetGenerationStats(ctx context.Context, companionID uuid.UUID, since time.Time) (count int, last *time.Time, err error)
+}
+
+type storyDraftRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewStoryDraftRepository creates a new StoryDraftRepository backed by PostgreSQL.
+func NewStoryDraftRepository(pool *pgxpool.Pool) StoryDraftRepository {
+	return &storyDraftRepo{pool: pool}
+}
+
+func (r *storyDraftRepo) Create(ctx context.Context, draft *models.StoryDraft) error {
+	query := `
+		INSERT INTO story_drafts (id, companion_id, caption, asset_ids, status, created_at)
+		VALUES ($1, $2, $3, $4::uuid[], $5, NOW())
+		RETURNING created_at`
+
+	return r.pool.QueryRow(ctx, query,
+		draft.ID, draft.CompanionID, draft.Caption, uuidStrings(draft.AssetIDs), draft.Status,
+	).Scan(&draft.CreatedAt)
+}
+
+func (r *storyDraftRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.StoryDraft, error) {
+	query := `
+		SELECT id, companion_id, caption, asset_ids::text[], status, story_id, created_at, reviewed_at
+		FROM story_drafts
+		WHERE id = $1`
+
+	d, err := scanDraft(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("story draft not found")
+		}
+		return nil, fmt.Errorf("getting story draft: %w", err)
+	}
+	return d, nil
+}
+
+func (r *storyDraftRepo) GetByStatus(ctx context.Context, status string, limit int) ([]models.StoryDraft, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	query := `
+		SELECT id, companion_id, caption, asset_ids::text[], status, story_id, created_at, reviewed_at
+		FROM story_drafts
+		WHERE status = $1
+		ORDER BY created_at ASC
+		LIMIT $2`
+
+	rows, err := r.pool.Query(ctx, query, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying story drafts: %w", err)
+	}
+	defer rows.Close()
+
+	var drafts []models.StoryDraft
+	for rows.Next() {
+		d, err := scanDraft(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning story draft: %w", err)
+		}
+		drafts = append(drafts, *d)
+	}
+
+	return drafts, rows.Err()
+}
+
+// UpdateStatus moves a pending draft to status. Drafts that were already
+// reviewed are left alone, so a draft published or rejected meanwhile is
+// not overwritten.
+func (r *storyDraftRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status string, storyID *uuid.UUID) error {
+	query := `
+		UPDATE story_drafts
+		SET status = $2, story_id = $3::uuid, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'`
+
+	var sid *string
+	if storyID != nil {
+		s := storyID.String()
+		sid = &s
+	}
+
+	tag, err := r.pool.Exec(ctx, query, id, status, sid)
+	if err != nil {
+		return fmt.Errorf("updating story draft: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("story draft not found or already reviewed")
+	}
+	return nil
+}
+
+// Publish creates the story and its slides for a pending draft and marks
+// the draft published, in one transaction. The draft row is locked first,
+// so of concurrent approvals only one creates a story; the others find the
+// draft already published.
+func (r *storyDraftRepo) Publish(ctx context.Context, id uuid.UUID, story *models.Story, media []models.StoryMedia) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning draft publish: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM story_drafts WHERE id = $1 FOR UPDATE`, id).Scan(&status)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("story draft not found")
+		}
+		return fmt.Errorf("locking story draft: %w", err)
+	}
+	if status != models.DraftPending {
+		return fmt.Errorf("story draft is already %s", status)
+	}
+
+	if err := createStory(ctx, tx, story); err != nil {
+		return fmt.Errorf("creating story: %w", err)
+	}
+	for i := range media {
+		if err := createStoryMedia(ctx, tx, &media[i]); err != nil {
+			return fmt.Errorf("creating story media: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE story_drafts SET status = $2, story_id = $3, reviewed_at = NOW()
+		WHERE id = $1`, id, models.DraftPublished, story.ID)
+	if err != nil {
+		return fmt.Errorf("updating story draft: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing draft publish: %w", err)
+	}
+	return nil
+}
+
+// GetGenerationStats returns how many drafts were generated for a companion
+// since the given time, and when the most recent one was generated.
+func (r *storyDraftRepo) GetGenerationStats(ctx context.Context, companionID uuid.UUID, since time.Time) (int, *time.Time, error) {
+	query := `
+		SELECT
+			(SELECT count(*) FROM story_drafts WHERE companion_id = $1 AND created_at >= $2),
+			(SELECT max(created_at) FROM story_drafts WHERE companion_id = $1)`
+
+	var count int
+	var last *time.Time
+	if err := r.pool.QueryRow(ctx, query, companionID, since).Scan(&count, &last); err != nil {
+		return 0, nil, fmt.Errorf("querying story generation stats: %w", err)
+	}
+	return count, last, nil
+}
+
+func scanDraft(row pgx.Row) (*models.StoryDraft, error) {
+	var d models.StoryDraft
+	var assetIDs []string
+	if err := row.Scan(&d.ID, &d.CompanionID, &d.Caption, &assetIDs, &d.Status, &d.StoryID, &d.CreatedAt, &d.ReviewedAt); err != nil {
+		return nil, err
+	}
+	for _, s := range assetIDs {
+		if id, err := uuid.Parse(s); err == nil {
+			d.AssetIDs = append(d.AssetIDs, id)
+		}
+	}
+	return &d, nil
+}
@@ -16,6 +16,7 @@ type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	Update(ctx context.Context, user *models.User) error
 }
 
 type userRepo struct {
@@ -29,20 +30,20 @@ func NewUserRepository(pool *pgxpool.Pool) UserRepository {
 
 func (r *userRepo) Create(ctx context.Context, user *models.User) error {
 	query := `
-		INSERT INTO users (id, email, password, name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		INSERT INTO users (id, email, password, name, timezone, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
 		RETURNING created_at, updated_at`
 
-	return r.pool.QueryRow(ctx, query, user.ID, user.Email, user.Password, user.Name).
+	return r.pool.QueryRow(ctx, query, user.ID, user.Email, user.Password, user.Name, user.Timezone).
 		Scan(&user.CreatedAt, &user.UpdatedAt)
 }
 
 func (r *userRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
-	query := `SELECT id, email, password, name, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT id, email, password, name, timezone, created_at, updated_at FROM users WHERE id = $1`
 
 	var user models.User
 	err := r.pool.QueryRow(ctx, query, id).
-		Scan(&user.ID, &user.Email, &user.Password, &user.Name, &user.CreatedAt, &user.UpdatedAt)
+		Scan(&user.ID, &user.Email, &user.Password, &user.Name, &user.Timezone, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("user not found")
@@ -53,11 +54,11 @@ func (r *userRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, err
 }
 
 func (r *userRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := `SELECT id, email, password, name, created_at, updated_at FROM users WHERE email = $1`
+	query := `SELECT id, email, password, name, timezone, created_at, updated_at FROM users WHERE email = $1`
 
 	var user models.User
 	err := r.pool.QueryRow(ctx, query, email).
-		Scan(&user.ID, &user.Email, &user.Password, &user.Name, &user.CreatedAt, &user.UpdatedAt)
+		Scan(&user.ID, &user.Email, &user.Password, &user.Name, &user.Timezone, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("user not found")
@@ -66,3 +67,20 @@ func (r *userRepo) GetByEmail(ctx context.Context, email string) (*models.User,
 	}
 	return &user, nil
 }
+
+// Update saves the user's name and time zone.
+func (r *userRepo) Update(ctx context.Context, user *models.User) error {
+	query := `
+		UPDATE users SET name = $2, timezone = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := r.pool.QueryRow(ctx, query, user.ID, user.Name, user.Timezone).Scan(&user.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("user not found")
+		}
+		return fmt.Errorf("updating user: %w", err)
+	}
+	return nil
+}
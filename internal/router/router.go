@@ -17,13 +17,24 @@ import (
 // New creates a fully configured chi router with all routes.
 func New(
 	cfg *config.Config,
+	sessions middleware.SessionValidator,
 	authH *handler.AuthHandler,
 	companionH *handler.CompanionHandler,
 	storyH *handler.StoryHandler,
 	messageH *handler.MessageHandler,
 	relationshipH *handler.RelationshipHandler,
 	memoryH *handler.MemoryHandler,
+	collectionH *handler.CollectionHandler,
+	searchH *handler.SearchHandler,
 	insightsH *handler.InsightsHandler,
+	notificationH *handler.NotificationHandler,
+	recapH *handler.RecapHandler,
+	mediaH *handler.MediaHandler,
+	storyDraftH *handler.StoryDraftHandler,
+	jobH *handler.JobHandler,
+	reactionH *handler.ReactionHandler,
+	analyticsH *handler.AnalyticsHandler,
+	mediaFiles http.Handler,
 ) *chi.Mux {
 	r := chi.NewRouter()
 
@@ -41,6 +52,12 @@ func New(
 		MaxAge:           300,
 	}))
 
+	// Locally stored media (STORAGE_DRIVER=local). S3-backed media is served
+	// directly by the bucket or CDN.
+	if mediaFiles != nil {
+		r.Handle("/media/*", http.StripPrefix("/media", mediaFiles))
+	}
+
 	r.Route("/api", func(r chi.Router) {
 		// Health check.
 		r.Get("/health", func(w http.ResponseWriter, _ *http.Request) {
@@ -51,6 +68,7 @@ func New(
 		r.Route("/auth", func(r chi.Router) {
 			r.Post("/signup", authH.Signup)
 			r.Post("/login", authH.Login)
+			r.Post("/refresh", authH.Refresh)
 		})
 
 		// Public companion browsing (anonymous access).
@@ -59,9 +77,14 @@ func New(
 
 		// Protected routes.
 		r.Group(func(r chi.Router) {
-			r.Use(middleware.Auth(cfg.JWT))
+			r.Use(middleware.Auth(cfg.JWT, sessions))
 
 			r.Get("/auth/me", authH.Me)
+			r.Patch("/auth/me", authH.UpdateMe)
+			r.Post("/auth/logout", authH.Logout)
+			r.Get("/auth/sessions", authH.ListSessions)
+			r.Post("/auth/sessions/revoke-others", authH.RevokeOtherSessions)
+			r.Delete("/auth/sessions/{id}", authH.RevokeSession)
 
 			// Companions.
 			r.Get("/companions", companionH.GetAll)
@@ -71,10 +94,18 @@ func New(
 			r.Get("/stories", storyH.GetActiveStories)
 			r.Get("/companions/{id}/stories", storyH.GetByCompanion)
 			r.Post("/stories/{id}/react", storyH.React)
+			r.Delete("/stories/{id}/react", storyH.Unreact)
+			r.Post("/stories/{id}/view", analyticsH.RecordView)
+			r.Get("/reactions", reactionH.List)
+			r.Get("/companions/{id}/highlights", storyH.GetHighlights)
+			r.Post("/stories/{id}/save", storyH.Save)
+			r.Delete("/stories/{id}/save", storyH.Unsave)
+			r.Get("/saved-stories", storyH.GetSaved)
 
 			// Messages (chat).
 			r.Get("/companions/{id}/messages", messageH.GetHistory)
 			r.Post("/companions/{id}/messages", messageH.Send)
+			r.Get("/companions/{id}/messages/{messageId}/context", messageH.GetContext)
 
 			// Relationships.
 			r.Get("/relationships", relationshipH.GetAllRelationships)
@@ -86,12 +117,88 @@ func New(
 			// Memories.
 			r.Get("/companions/{id}/memories", memoryH.GetByCompanion)
 			r.Post("/companions/{id}/memories", memoryH.Create)
+			r.Get("/companions/{id}/memories/fading", memoryH.GetFading)
 			r.Delete("/memories/{id}", memoryH.Delete)
+			r.Patch("/memories/{id}", memoryH.Update)
 			r.Patch("/memories/{id}/pin", memoryH.TogglePin)
+			r.Post("/memories/{id}/rescue", memoryH.Rescue)
+			r.Get("/memories/{id}/edits", memoryH.GetEdits)
+			r.Get("/companions/{id}/memory-tags", memoryH.GetTagCounts)
+			r.Get("/memory-tags", memoryH.GetTags)
+			r.Post("/memory-tags", memoryH.CreateTag)
+			r.Patch("/memory-tags/{id}", memoryH.RenameTag)
+			r.Post("/memory-tags/{id}/merge", memoryH.MergeTag)
+			r.Delete("/memory-tags/{id}", memoryH.DeleteTag)
+			r.Get("/memory-cards", memoryH.GetCards)
+			r.Post("/memory-cards/{id}/dismiss", memoryH.DismissCard)
+
+			// Memory collections.
+			r.Get("/companions/{id}/collections", collectionH.List)
+			r.Post("/companions/{id}/collections", collectionH.Create)
+			r.Get("/companions/{id}/collections/suggest", collectionH.Suggest)
+			r.Get("/companions/{id}/collections/{collectionId}", collectionH.Get)
+			r.Patch("/companions/{id}/collections/{collectionId}", collectionH.Update)
+			r.Delete("/companions/{id}/collections/{collectionId}", collectionH.Delete)
+			r.Post("/companions/{id}/collections/{collectionId}/memories", collectionH.AddMemory)
+			r.Put("/companions/{id}/collections/{collectionId}/memories", collectionH.Reorder)
+			r.Delete("/companions/{id}/collections/{collectionId}/memories/{memoryId}", collectionH.RemoveMemory)
+
+			// Search.
+			r.Get("/search", searchH.Search)
+			r.Get("/companions/{id}/recall", searchH.Recall)
 
 			// Insights.
+			r.Get("/insights/overview", insightsH.GetOverview)
 			r.Get("/companions/{id}/insights", insightsH.GetInsights)
+			r.Get("/companions/{id}/insights/conversation", insightsH.GetConversation)
 			r.Get("/companions/{id}/reactions/summary", insightsH.GetReactionSummary)
+			r.Get("/companions/{id}/reactions/trends", insightsH.GetReactionTrends)
+
+			// Weekly recaps.
+			r.Get("/companions/{id}/recaps", recapH.List)
+			r.Get("/recaps/{id}", recapH.Get)
+
+			// Notifications.
+			r.Get("/notifications", notificationH.List)
+			r.Post("/notifications/read-all", notificationH.MarkAllRead)
+			r.Post("/notifications/{id}/read", notificationH.MarkRead)
+
+			// Admin: content management.
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(middleware.RequireAdmin(cfg.Admin))
+
+				r.Post("/companions/{id}/avatar", mediaH.UploadAvatar)
+				r.Post("/companions/{id}/stories", mediaH.CreateStory)
+				r.Post("/stories/{id}/media", mediaH.AddStoryMedia)
+				r.Post("/stories/{id}/text-slides", mediaH.AddTextSlide)
+
+				// Highlights.
+				r.Post("/companions/{id}/highlights", storyH.CreateHighlight)
+				r.Delete("/highlights/{id}", storyH.DeleteHighlight)
+				r.Post("/highlights/{id}/stories", storyH.AddToHighlight)
+				r.Delete("/highlights/{id}/stories/{storyId}", storyH.RemoveFromHighlight)
+
+				// Asset library and generated stories.
+				r.Get("/companions/{id}/assets", mediaH.GetAssets)
+				r.Post("/companions/{id}/assets", mediaH.UploadAsset)
+				r.Post("/companions/{id}/story-drafts", storyDraftH.Generate)
+				r.Get("/story-drafts", storyDraftH.List)
+				r.Post("/story-drafts/{id}/approve", storyDraftH.Approve)
+				r.Post("/story-drafts/{id}/reject", storyDraftH.Reject)
+
+				// Reaction catalogue.
+				r.Get("/reactions", reactionH.ListAll)
+				r.Put("/reactions/{key}", reactionH.Upsert)
+
+				// Story analytics.
+				r.Get("/analytics/stories", analyticsH.StoryReport)
+				r.Get("/analytics/stories/{id}", analyticsH.MediaReport)
+
+				// Scheduled jobs.
+				r.Get("/jobs", jobH.List)
+				r.Get("/jobs/runs", jobH.Runs)
+				r.Get("/jobs/{name}/runs", jobH.Runs)
+			})
 		})
 	})
 
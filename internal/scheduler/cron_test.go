@@ -0,0 +1,214 @@
+package scheduler
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func maskValues(mask uint64) []int {
+	var vals []int
+	for v := 0; v < 64; v++ {
+		if mask&(1<<uint(v)) != 0 {
+			vals = append(vals, v)
+		}
+	}
+	return vals
+}
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		field    string
+		min, max int
+		want     []int
+	}{
+		{"*", 1, 12, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}},
+		{"5", 0, 59, []int{5}},
+		{"1,3,5", 0, 59, []int{1, 3, 5}},
+		{"1-4", 0, 59, []int{1, 2, 3, 4}},
+		{"*/15", 0, 59, []int{0, 15, 30, 45}},
+		{"5/10", 0, 59, []int{5, 15, 25, 35, 45, 55}},
+		{"10-20/5", 0, 59, []int{10, 15, 20}},
+		{"*/5", 1, 12, []int{1, 6, 11}},
+		{"1-3,10-12", 1, 12, []int{1, 2, 3, 10, 11, 12}},
+		{"0,30,0", 0, 59, []int{0, 30}},
+		{"0-23", 0, 23, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			mask, err := parseField(tt.field, tt.min, tt.max)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := maskValues(mask); !slices.Equal(got, tt.want) {
+				t.Errorf("parseField(%q) = %v, want %v", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFieldRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    string
+		min, max int
+	}{
+		{"above max", "60", 0, 59},
+		{"below min", "0", 1, 31},
+		{"range above max", "10-13", 1, 12},
+		{"reversed range", "5-2", 0, 59},
+		{"zero step", "*/0", 0, 59},
+		{"zero step on start", "5/0", 0, 59},
+		{"negative step", "*/-1", 0, 59},
+		{"step without number", "*/", 0, 59},
+		{"start above max", "60/5", 0, 59},
+		{"not a number", "a", 0, 59},
+		{"open range", "1-", 0, 59},
+		{"empty", "", 0, 59},
+		{"empty list item", "1,,2", 0, 59},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if mask, err := parseField(tt.field, tt.min, tt.max); err == nil {
+				t.Errorf("parseField(%q) = %v, want error", tt.field, maskValues(mask))
+			}
+		})
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		spec, same string
+	}{
+		{"@hourly", "0 * * * *"},
+		{"@daily", "0 0 * * *"},
+		{"@midnight", "0 0 * * *"},
+		{"@weekly", "0 0 * * 0"},
+		{"@monthly", "0 0 1 * *"},
+		{" @daily ", "0 0 * * *"},
+		{"0 0 * * 7", "0 0 * * 0"},
+		{"0 0 * * 5-7", "0 0 * * 0,5,6"},
+		{"0 0 * * 0,7", "0 0 * * 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := ParseSchedule(tt.same)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.spec {
+				t.Errorf("String() = %q, want %q", got.String(), tt.spec)
+			}
+			got.spec = want.spec
+			if *got != *want {
+				t.Errorf("ParseSchedule(%q) = %+v, want %+v", tt.spec, *got, *want)
+			}
+		})
+	}
+}
+
+func TestParseScheduleRestrictedDays(t *testing.T) {
+	tests := []struct {
+		spec     string
+		dom, dow bool
+	}{
+		{"0 0 * * *", false, false},
+		{"0 0 1 * *", true, false},
+		{"0 0 * * 1", false, true},
+		{"0 0 1 * 1", true, true},
+		{"0 0 */2 * 1", false, true},
+		{"0 0 1-15 * */2", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.domRestricted != tt.dom || s.dowRestricted != tt.dow {
+				t.Errorf("restricted = %v, %v; want %v, %v", s.domRestricted, s.dowRestricted, tt.dom, tt.dow)
+			}
+		})
+	}
+}
+
+func TestParseScheduleRejects(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@yearly",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := ParseSchedule(spec); err == nil {
+				t.Errorf("ParseSchedule(%q) succeeded, want error", spec)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	utc := func(s string) time.Time {
+		t.Helper()
+		v, err := time.Parse("2006-01-02 15:04:05", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"step", "*/15 * * * *", utc("2026-03-02 10:07:30"), utc("2026-03-02 10:15:00")},
+		{"strictly after", "0 * * * *", utc("2026-03-02 10:00:00"), utc("2026-03-02 11:00:00")},
+		{"list", "0 6,18 * * *", utc("2026-03-02 07:00:00"), utc("2026-03-02 18:00:00")},
+		{"daily across month end", "@daily", utc("2026-01-31 23:59:00"), utc("2026-02-01 00:00:00")},
+		{"hourly across day end", "@hourly", utc("2026-03-02 23:30:00"), utc("2026-03-03 00:00:00")},
+		{"across year end", "30 12 1 * *", utc("2026-12-15 00:00:00"), utc("2027-01-01 12:30:00")},
+		{"31st skips short months", "0 0 31 * *", utc("2026-04-01 00:00:00"), utc("2026-05-31 00:00:00")},
+		{"Feb 29 waits for a leap year", "0 0 29 2 *", utc("2026-03-01 00:00:00"), utc("2028-02-29 00:00:00")},
+		{"Feb 29 in a leap year", "0 0 29 2 *", utc("2028-01-15 00:00:00"), utc("2028-02-29 00:00:00")},
+		{"impossible Feb 31", "0 0 31 2 *", utc("2026-03-01 00:00:00"), time.Time{}},
+		{"impossible Feb 30", "0 0 30 2 *", utc("2026-03-01 00:00:00"), time.Time{}},
+		{"impossible April 31", "0 0 31 4 *", utc("2026-03-01 00:00:00"), time.Time{}},
+		{"Sunday as 7", "0 0 * * 7", utc("2026-03-02 00:00:00"), utc("2026-03-08 00:00:00")},
+
+		// Day of month and day of week both set: either matches.
+		{"dom or dow, dow first", "0 0 1 * 1", utc("2026-03-02 00:00:00"), utc("2026-03-09 00:00:00")},
+		{"dom or dow, dom first", "0 0 1 * 1", utc("2026-03-31 00:00:00"), utc("2026-04-01 00:00:00")},
+		{"impossible dom rescued by dow", "0 0 31 2 1", utc("2026-03-01 00:00:00"), utc("2027-02-01 00:00:00")},
+
+		// A */n field counts as unrestricted: both must match.
+		{"dom step and dow", "0 0 */2 * 1", utc("2026-03-01 00:00:00"), utc("2026-03-09 00:00:00")},
+		{"dom and dow step", "0 0 15 * */2", utc("2026-03-01 00:00:00"), utc("2026-03-15 00:00:00")},
+
+		{"converts to UTC", "0 0 * * *", time.Date(2026, 3, 2, 6, 0, 0, 0, time.FixedZone("UTC+7", 7*60*60)), utc("2026-03-02 00:00:00")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
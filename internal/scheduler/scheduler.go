@@ -0,0 +1,263 @@
+// Package scheduler runs cron-scheduled maintenance jobs inside the API
+// process. Every replica runs the same schedule; a Postgres advisory lock
+// plus a per-slot claim row in job_runs ensure each slot runs on only one.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+)
+
+// Func is the work a job performs. It should return promptly once ctx is done.
+type Func func(ctx context.Context) error
+
+// defaultTimeout bounds a run when a job is registered without one.
+const defaultTimeout = 10 * time.Minute
+
+type job struct {
+	name     string
+	schedule *Schedule
+	timeout  time.Duration
+	fn       Func
+
+	mu     sync.Mutex
+	status models.JobStatus
+}
+
+// Scheduler runs registered jobs on their cron schedules.
+type Scheduler struct {
+	runs     repository.JobRunRepository
+	instance string
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+}
+
+// New creates a Scheduler that records runs in runs.
+func New(runs repository.JobRunRepository) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		runs:     runs,
+		instance: fmt.Sprintf("%s:%d", host, os.Getpid()),
+		jobs:     make(map[string]*job),
+	}
+}
+
+// Register adds a job. It must be called before Start. A zero timeout
+// uses the default of ten minutes.
+func (s *Scheduler) Register(name, spec string, timeout time.Duration, fn Func) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("job %s: scheduler already started", name)
+	}
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("job %s is already registered", name)
+	}
+
+	s.jobs[name] = &job{
+		name:     name,
+		schedule: schedule,
+		timeout:  timeout,
+		fn:       fn,
+		status:   models.JobStatus{Name: name, Schedule: spec},
+	}
+	return nil
+}
+
+// Start launches one goroutine per job. Jobs first run at their next
+// scheduled time, not immediately.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+	slog.Info("scheduler started", "jobs", len(s.jobs), "instance", s.instance)
+}
+
+// Stop cancels running jobs and waits for them to record their result, or
+// until ctx is done.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		slog.Info("scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for jobs to stop: %w", ctx.Err())
+	}
+}
+
+// Jobs returns every registered job with this instance's metrics, by name.
+func (s *Scheduler) Jobs() []models.JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]models.JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		j.mu.Lock()
+		st := j.status
+		j.mu.Unlock()
+		if st.NextRun.IsZero() {
+			st.NextRun = j.schedule.Next(time.Now())
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Name < out[b].Name })
+	return out
+}
+
+// History returns recent runs across all replicas, for one job or all jobs
+// when name is empty.
+func (s *Scheduler) History(ctx context.Context, name string, limit int) ([]models.JobRun, error) {
+	if name != "" {
+		s.mu.Lock()
+		_, ok := s.jobs[name]
+		s.mu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("job %s not found", name)
+		}
+	}
+	return s.runs.GetRecent(ctx, name, limit)
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			slog.Error("job schedule never fires", "job", j.name, "schedule", j.schedule)
+			return
+		}
+		j.mu.Lock()
+		j.status.NextRun = next
+		j.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.run(ctx, j, next)
+	}
+}
+
+// run claims the slot and, if this replica won it, executes the job and
+// records the outcome.
+func (s *Scheduler) run(ctx context.Context, j *job, slot time.Time) {
+	// A run that outlives its timeout has been cancelled, so after that
+	// (plus a margin for recording the result) its claim is stale.
+	id, ok, err := s.runs.Claim(ctx, j.name, slot, s.instance, j.timeout+time.Minute)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Error("job claim failed", "job", j.name, "error", err)
+		}
+		return
+	}
+	if !ok {
+		j.mu.Lock()
+		j.status.Skipped++
+		j.mu.Unlock()
+		return
+	}
+
+	j.mu.Lock()
+	j.status.Running = true
+	j.mu.Unlock()
+
+	start := time.Now()
+	runErr := s.execute(ctx, j)
+	elapsed := time.Since(start)
+
+	status := models.JobSucceeded
+	var errMsg *string
+	if runErr != nil {
+		status = models.JobFailed
+		msg := runErr.Error()
+		errMsg = &msg
+		slog.Error("job failed", "job", j.name, "duration", elapsed, "error", runErr)
+	} else {
+		slog.Info("job finished", "job", j.name, "duration", elapsed)
+	}
+
+	j.mu.Lock()
+	j.status.Running = false
+	j.status.Runs++
+	if runErr != nil {
+		j.status.Failures++
+	}
+	j.status.LastRunAt = &start
+	j.status.LastDurationMS = elapsed.Milliseconds()
+	j.status.LastError = errMsg
+	j.mu.Unlock()
+
+	// Record the result even when shutting down, so the slot isn't left
+	// looking like it's still running.
+	finishCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.runs.Finish(finishCtx, id, status, errMsg); err != nil {
+		slog.Error("recording job run failed", "job", j.name, "error", err)
+	}
+}
+
+func (s *Scheduler) execute(ctx context.Context, j *job) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, j.timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return j.fn(ctx)
+}
@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+)
+
+// fakeJobRuns grants or refuses every claim and records finished runs.
+type fakeJobRuns struct {
+	repository.JobRunRepository
+
+	claimOK  bool
+	claimErr error
+	claimed  []time.Time
+	finished []string
+}
+
+func (f *fakeJobRuns) Claim(_ context.Context, _ string, scheduledFor time.Time, _ string, _ time.Duration) (int64, bool, error) {
+	f.claimed = append(f.claimed, scheduledFor)
+	return int64(len(f.claimed)), f.claimOK, f.claimErr
+}
+
+func (f *fakeJobRuns) Finish(_ context.Context, _ int64, status string, _ *string) error {
+	f.finished = append(f.finished, status)
+	return nil
+}
+
+func TestSchedulerRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		claimOK      bool
+		claimErr     error
+		jobErr       error
+		wantCalls    int
+		wantFinished []string
+		wantStatus   models.JobStatus
+	}{
+		{"claimed", true, nil, nil, 1, []string{models.JobSucceeded}, models.JobStatus{Runs: 1}},
+		{"claimed and failed", true, nil, errors.New("boom"), 1, []string{models.JobFailed}, models.JobStatus{Runs: 1, Failures: 1}},
+		{"claimed elsewhere", false, nil, nil, 0, nil, models.JobStatus{Skipped: 1}},
+		{"claim error", false, errors.New("db down"), nil, 0, nil, models.JobStatus{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := &fakeJobRuns{claimOK: tt.claimOK, claimErr: tt.claimErr}
+			s := New(runs)
+			calls := 0
+			err := s.Register("job", "@hourly", time.Minute, func(context.Context) error {
+				calls++
+				return tt.jobErr
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			slot := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+			s.run(context.Background(), s.jobs["job"], slot)
+
+			if len(runs.claimed) != 1 || !runs.claimed[0].Equal(slot) {
+				t.Errorf("claimed slots = %v, want [%s]", runs.claimed, slot)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("job ran %d times, want %d", calls, tt.wantCalls)
+			}
+			if len(runs.finished) != len(tt.wantFinished) || len(tt.wantFinished) > 0 && runs.finished[0] != tt.wantFinished[0] {
+				t.Errorf("finished = %v, want %v", runs.finished, tt.wantFinished)
+			}
+			st := s.Jobs()[0]
+			if st.Runs != tt.wantStatus.Runs || st.Failures != tt.wantStatus.Failures || st.Skipped != tt.wantStatus.Skipped || st.Running {
+				t.Errorf("status = %+v, want runs %d failures %d skipped %d", st, tt.wantStatus.Runs, tt.wantStatus.Failures, tt.wantStatus.Skipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+)
+
+const (
+	// rollupWindow is how many trailing UTC days each rollup run rebuilds,
+	// so late reactions, removals and replies are picked up.
+	rollupWindow = 3
+
+	// maxWatchedMS caps the reported on-screen time of one view.
+	maxWatchedMS = 10 * 60 * 1000
+
+	// maxAnalyticsRange is the longest date range a report may cover.
+	maxAnalyticsRange = 366 * 24 * time.Hour
+)
+
+// AnalyticsService records story views and reports story performance.
+type AnalyticsService struct {
+	analytics     repository.StoryAnalyticsRepository
+	stories       repository.StoryRepository
+	reactions     repository.ReactionRepository
+	viewRetention time.Duration
+}
+
+// NewAnalyticsService creates a new AnalyticsService. Raw view events older
+// than viewRetention are pruned once they have been rolled up.
+func NewAnalyticsService(
+	analytics repository.StoryAnalyticsRepository,
+	stories repository.StoryRepository,
+	reactions repository.ReactionRepository,
+	viewRetention time.Duration,
+) *AnalyticsService {
+	return &AnalyticsService{analytics: analytics, stories: stories, reactions: reactions, viewRetention: viewRetention}
+}
+
+// RecordView records that the user was shown a slide of a story.
+func (s *AnalyticsService) RecordView(ctx context.Context, userID, storyID uuid.UUID, req models.RecordStoryViewRequest) error {
+	media, err := s.stories.GetMediaByID(ctx, req.MediaID)
+	if err != nil {
+		return err
+	}
+	if media.StoryID != storyID {
+		return fmt.Errorf("media does not belong to this story")
+	}
+
+	req.WatchedMS = min(max(req.WatchedMS, 0), maxWatchedMS)
+	return s.analytics.RecordView(ctx, userID, storyID, req)
+}
+
+// Refresh rebuilds recent rollups and prunes old view events. It runs as a
+// scheduled job.
+func (s *AnalyticsService) Refresh(ctx context.Context) error {
+	since := time.Now().UTC().AddDate(0, 0, -(rollupWindow - 1))
+	if err := s.analytics.Rollup(ctx, since); err != nil {
+		return err
+	}
+
+	// Never prune events the rollup still reads.
+	retention := max(s.viewRetention, rollupWindow*24*time.Hour)
+	n, err := s.analytics.DeleteViewsBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		slog.Info("story views pruned", "count", n)
+	}
+	return nil
+}
+
+// StoryReport returns per-story performance for the filter.
+func (s *AnalyticsService) StoryReport(ctx context.Context, filter models.AnalyticsFilter) ([]models.StoryStats, error) {
+	if err := validateRange(filter); err != nil {
+		return nil, err
+	}
+	return s.analytics.GetStoryStats(ctx, filter)
+}
+
+// MediaReport returns per-slide performance of one story for the filter.
+func (s *AnalyticsService) MediaReport(ctx context.Context, storyID uuid.UUID, filter models.AnalyticsFilter) ([]models.StoryMediaStats, error) {
+	if err := validateRange(filter); err != nil {
+		return nil, err
+	}
+	return s.analytics.GetMediaStats(ctx, storyID, filter)
+}
+
+// ReactionKeys returns every catalogue key in display order, for stable
+// report columns.
+func (s *AnalyticsService) ReactionKeys(ctx context.Context) ([]string, error) {
+	types, err := s.reactions.GetAll(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(types))
+	for i, t := range types {
+		keys[i] = t.Key
+	}
+	return keys, nil
+}
+
+func validateRange(f models.AnalyticsFilter) error {
+	if f.To.Before(f.From) {
+		return fmt.Errorf("to must not be before from")
+	}
+	if f.To.Sub(f.From) > maxAnalyticsRange {
+		return fmt.Errorf("date range must be at most 366 days")
+	}
+	return nil
+}
@@ -2,7 +2,14 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,23 +21,34 @@ import (
 	"ai-companion-be/internal/repository"
 )
 
-// AuthService handles user authentication.
+// AuthService handles user authentication and login sessions.
 type AuthService struct {
-	users  repository.UserRepository
-	jwtCfg config.JWTConfig
+	users    repository.UserRepository
+	sessions repository.SessionRepository
+	jwtCfg   config.JWTConfig
 }
 
 // NewAuthService creates a new AuthService.
-func NewAuthService(users repository.UserRepository, jwtCfg config.JWTConfig) *AuthService {
-	return &AuthService{users: users, jwtCfg: jwtCfg}
+func NewAuthService(users repository.UserRepository, sessions repository.SessionRepository, jwtCfg config.JWTConfig) *AuthService {
+	return &AuthService{users: users, sessions: sessions, jwtCfg: jwtCfg}
 }
 
-// Signup registers a new user and returns an auth token.
-func (s *AuthService) Signup(ctx context.Context, req models.SignupRequest) (*models.AuthResponse, error) {
+// maxUserAgentLength caps the user agent stored on a session.
+const maxUserAgentLength = 512
+
+// Signup registers a new user and starts a session on the device.
+func (s *AuthService) Signup(ctx context.Context, req models.SignupRequest, device models.DeviceInfo) (*models.AuthResponse, error) {
 	if req.Email == "" || req.Password == "" || req.Name == "" {
 		return nil, fmt.Errorf("email, password, and name are required")
 	}
 
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if err := validateTimezone(req.Timezone); err != nil {
+		return nil, err
+	}
+
 	hashed, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, fmt.Errorf("hashing password: %w", err)
@@ -41,22 +59,18 @@ func (s *AuthService) Signup(ctx context.Context, req models.SignupRequest) (*mo
 		Email:    req.Email,
 		Password: string(hashed),
 		Name:     req.Name,
+		Timezone: req.Timezone,
 	}
 
 	if err := s.users.Create(ctx, user); err != nil {
 		return nil, fmt.Errorf("creating user: %w", err)
 	}
 
-	token, err := s.generateToken(user.ID)
-	if err != nil {
-		return nil, err
-	}
-
-	return &models.AuthResponse{Token: token, User: *user}, nil
+	return s.startSession(ctx, user, device)
 }
 
-// Login authenticates a user and returns an auth token.
-func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (*models.AuthResponse, error) {
+// Login authenticates a user and starts a session on the device.
+func (s *AuthService) Login(ctx context.Context, req models.LoginRequest, device models.DeviceInfo) (*models.AuthResponse, error) {
 	if req.Email == "" || req.Password == "" {
 		return nil, fmt.Errorf("email and password are required")
 	}
@@ -70,12 +84,77 @@ func (s *AuthService) Login(ctx context.Context, req models.LoginRequest) (*mode
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
-	token, err := s.generateToken(user.ID)
+	return s.startSession(ctx, user, device)
+}
+
+// Refresh exchanges a refresh token for a new access token and the next
+// refresh token of its session. A token that was already exchanged
+// revokes the session, as it means the token leaked; the legitimate holder
+// has to log in again.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string, device models.DeviceInfo) (*models.AuthResponse, error) {
+	if refreshToken == "" {
+		return nil, fmt.Errorf("refresh_token is required")
+	}
+
+	next, nextHash, err := newRefreshToken()
 	if err != nil {
 		return nil, err
 	}
+	session, err := s.sessions.Rotate(ctx, hashRefreshToken(refreshToken), nextHash, cleanDevice(device),
+		time.Now().Add(s.jwtCfg.RefreshExpiration))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenReused) {
+			slog.Warn("refresh token reused, session revoked", "ip", device.IP)
+		}
+		return nil, fmt.Errorf("invalid refresh token")
+	}
 
-	return &models.AuthResponse{Token: token, User: *user}, nil
+	user, err := s.users.GetByID(ctx, session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid refresh token")
+	}
+	return s.authResponse(user, session.ID, next)
+}
+
+// Logout revokes the session the request was made with.
+func (s *AuthService) Logout(ctx context.Context, userID, sessionID uuid.UUID) error {
+	return s.sessions.Revoke(ctx, userID, sessionID, models.SessionRevokedLogout)
+}
+
+// ListSessions returns the user's active sessions, most recently used
+// first, marking the current one.
+func (s *AuthService) ListSessions(ctx context.Context, userID, currentID uuid.UUID) ([]models.Session, error) {
+	sessions, err := s.sessions.ListActive(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes one of the user's sessions.
+func (s *AuthService) RevokeSession(ctx context.Context, userID, id uuid.UUID) error {
+	return s.sessions.Revoke(ctx, userID, id, models.SessionRevokedByUser)
+}
+
+// RevokeOtherSessions revokes every session of the user but the current
+// one, returning how many were revoked.
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID, currentID uuid.UUID) (int64, error) {
+	return s.sessions.RevokeOthers(ctx, userID, currentID, models.SessionRevokedByUser)
+}
+
+// SessionActive reports whether an access token's session is still
+// active, for middleware.Auth.
+func (s *AuthService) SessionActive(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	return s.sessions.IsActive(ctx, userID, sessionID)
+}
+
+// PruneSessions deletes sessions that were revoked or expired longer ago
+// than the retention, returning how many were deleted.
+func (s *AuthService) PruneSessions(ctx context.Context) (int64, error) {
+	return s.sessions.DeleteEndedBefore(ctx, time.Now().Add(-s.jwtCfg.SessionRetention))
 }
 
 // GetUser returns a user by ID.
@@ -83,9 +162,87 @@ func (s *AuthService) GetUser(ctx context.Context, userID uuid.UUID) (*models.Us
 	return s.users.GetByID(ctx, userID)
 }
 
-func (s *AuthService) generateToken(userID uuid.UUID) (string, error) {
+// UpdateUser applies the fields set in req to the user's profile.
+func (s *AuthService) UpdateUser(ctx context.Context, userID uuid.UUID, req models.UpdateUserRequest) (*models.User, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("name must not be empty")
+		}
+		user.Name = name
+	}
+	if req.Timezone != nil {
+		if err := validateTimezone(*req.Timezone); err != nil {
+			return nil, err
+		}
+		user.Timezone = *req.Timezone
+	}
+
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// validateTimezone accepts IANA time zone names. "Local" is rejected, as it
+// means the server's zone rather than the user's.
+func validateTimezone(name string) error {
+	if name == "" || name == "Local" {
+		return fmt.Errorf("timezone must be an IANA time zone name, e.g. Asia/Ho_Chi_Minh")
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("timezone must be an IANA time zone name, e.g. Asia/Ho_Chi_Minh")
+	}
+	return nil
+}
+
+// startSession opens a session for the user on the device and issues its
+// first token pair.
+func (s *AuthService) startSession(ctx context.Context, user *models.User, device models.DeviceInfo) (*models.AuthResponse, error) {
+	refresh, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	device = cleanDevice(device)
+	session := &models.Session{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		UserAgent: device.UserAgent,
+		IP:        device.IP,
+		ExpiresAt: time.Now().Add(s.jwtCfg.RefreshExpiration),
+	}
+	if err := s.sessions.Create(ctx, session, refreshHash); err != nil {
+		return nil, err
+	}
+	return s.authResponse(user, session.ID, refresh)
+}
+
+// authResponse issues an access token for the session alongside its
+// refresh token.
+func (s *AuthService) authResponse(user *models.User, sessionID uuid.UUID, refreshToken string) (*models.AuthResponse, error) {
+	token, err := s.generateToken(user.ID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthResponse{
+		Token:        token,
+		ExpiresIn:    int(s.jwtCfg.Expiration.Seconds()),
+		RefreshToken: refreshToken,
+		SessionID:    sessionID,
+		User:         *user,
+	}, nil
+}
+
+func (s *AuthService) generateToken(userID, sessionID uuid.UUID) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": userID.String(),
+		"sid":     sessionID.String(),
 		"exp":     time.Now().Add(s.jwtCfg.Expiration).Unix(),
 		"iat":     time.Now().Unix(),
 	}
@@ -97,3 +254,27 @@ func (s *AuthService) generateToken(userID uuid.UUID) (string, error) {
 	}
 	return signed, nil
 }
+
+// newRefreshToken returns a random refresh token and the hash it is
+// stored under.
+func newRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("generating refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// cleanDevice trims the user agent to what is stored.
+func cleanDevice(device models.DeviceInfo) models.DeviceInfo {
+	if len(device.UserAgent) > maxUserAgentLength {
+		device.UserAgent = strings.ToValidUTF8(device.UserAgent[:maxUserAgentLength], "")
+	}
+	return device
+}
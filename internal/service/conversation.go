@@ -0,0 +1,455 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/ai"
+	"ai-companion-be/internal/config"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+)
+
+const (
+	defaultConversationSnapshots = 30
+	maxConversationSnapshots     = 365
+
+	// toneThreshold is how far mean sentiment must lean to make the tone
+	// positive or negative.
+	toneThreshold = 0.2
+
+	// Topics need at least minTopicMessages messages; at most maxTopics
+	// are kept, largest first, each labelled with up to topicKeywords
+	// words.
+	minTopicMessages = 3
+	maxTopics        = 5
+	topicKeywords    = 3
+
+	peakHourCount = 3
+
+	// chatSessionGap is the longest pause between messages of one
+	// session. The insights rollups count chat time with the same gap.
+	chatSessionGap = 30 * time.Minute
+)
+
+// ConversationService takes periodic analytics snapshots of each
+// relationship's conversation: message lengths, reply times, active
+// hours, sentiment and topics.
+type ConversationService struct {
+	conversations repository.ConversationRepository
+	messages      repository.MessageRepository
+	embeddings    repository.EmbeddingRepository
+	embedder      ai.Embedder
+	cfg           config.ConversationConfig
+}
+
+// NewConversationService creates a new ConversationService.
+func NewConversationService(
+	conversations repository.ConversationRepository,
+	messages repository.MessageRepository,
+	embeddings repository.EmbeddingRepository,
+	embedder ai.Embedder,
+	cfg config.ConversationConfig,
+) *ConversationService {
+	return &ConversationService{
+		conversations: conversations,
+		messages:      messages,
+		embeddings:    embeddings,
+		embedder:      embedder,
+		cfg:           cfg,
+	}
+}
+
+// History returns up to limit of the pair's snapshots, newest first.
+func (s *ConversationService) History(ctx context.Context, userID, companionID uuid.UUID, limit int) ([]models.ConversationSnapshot, error) {
+	if limit <= 0 || limit > maxConversationSnapshots {
+		limit = defaultConversationSnapshots
+	}
+	return s.conversations.GetSnapshots(ctx, userID, companionID, limit)
+}
+
+// Latest returns the pair's most recent snapshot, or nil before the first.
+func (s *ConversationService) Latest(ctx context.Context, userID, companionID uuid.UUID) (*models.ConversationSnapshot, error) {
+	snapshots, err := s.conversations.GetSnapshots(ctx, userID, companionID, 1)
+	if err != nil || len(snapshots) == 0 {
+		return nil, err
+	}
+	return &snapshots[0], nil
+}
+
+// Run snapshots every relationship the user has written in during the
+// window. It runs as a scheduled job; a pair that fails is logged and
+// skipped.
+func (s *ConversationService) Run(ctx context.Context) error {
+	now := time.Now()
+	since := now.Add(-s.cfg.Window)
+	pairs, err := s.conversations.GetActivePairs(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	saved := 0
+	for _, p := range pairs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.snapshot(ctx, p, since, now); err != nil {
+			slog.Error("conversation snapshot failed", "user_id", p.UserID, "companion_id", p.CompanionID, "error", err)
+			continue
+		}
+		saved++
+	}
+
+	slog.Info("conversation snapshots taken", "count", saved)
+	return nil
+}
+
+// snapshot analyses one pair's messages between since and now.
+func (s *ConversationService) snapshot(ctx context.Context, p models.ConversationPair, since, now time.Time) error {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	newest, err := s.messages.GetBefore(ctx, p.UserID, p.CompanionID, nil, false, s.cfg.MaxMessages)
+	if err != nil {
+		return err
+	}
+	// Oldest first, within the window.
+	var msgs []models.Message
+	for i := len(newest) - 1; i >= 0; i-- {
+		if !newest[i].CreatedAt.Before(since) && newest[i].CreatedAt.Before(now) {
+			msgs = append(msgs, newest[i])
+		}
+	}
+	// A busy pair can send more than MaxMessages in the window; the
+	// snapshot then covers only the messages analysed.
+	windowStart := since
+	if len(newest) == s.cfg.MaxMessages && len(msgs) > 0 && msgs[0].ID == newest[len(newest)-1].ID {
+		windowStart = msgs[0].CreatedAt
+	}
+
+	snap := &models.ConversationSnapshot{
+		ID:           uuid.New(),
+		UserID:       p.UserID,
+		CompanionID:  p.CompanionID,
+		SnapshotDate: localToday(loc).Format(time.DateOnly),
+		WindowStart:  windowStart,
+		WindowEnd:    now,
+		ActiveHours:  make([]int, 24),
+	}
+
+	var userMsgs []models.Message
+	var userChars, companionChars int
+	var replies []float64
+	for i, m := range msgs {
+		length := len([]rune(m.Content))
+		if m.Role != "user" {
+			snap.CompanionMessages++
+			companionChars += length
+			continue
+		}
+		snap.UserMessages++
+		userChars += length
+		userMsgs = append(userMsgs, m)
+		snap.ActiveHours[m.CreatedAt.In(loc).Hour()]++
+
+		if i > 0 && msgs[i-1].Role != "user" {
+			if gap := m.CreatedAt.Sub(msgs[i-1].CreatedAt); gap <= chatSessionGap {
+				replies = append(replies, gap.Seconds())
+			}
+		}
+	}
+	if snap.UserMessages > 0 {
+		snap.AvgUserLength = float64(userChars) / float64(snap.UserMessages)
+	}
+	if snap.CompanionMessages > 0 {
+		snap.AvgCompanionLength = float64(companionChars) / float64(snap.CompanionMessages)
+	}
+
+	snap.Replies = len(replies)
+	if len(replies) > 0 {
+		sort.Float64s(replies)
+		median, p90 := percentile(replies, 0.5), percentile(replies, 0.9)
+		snap.ReplyMedianSeconds, snap.ReplyP90Seconds = &median, &p90
+	}
+
+	snap.Sentiment, snap.SentimentTrend = sentimentTrend(userMsgs, loc)
+	snap.Tone = toneOf(snap.Sentiment)
+
+	vectors, err := s.embeddings.GetMessageVectors(ctx, s.embedder.Model(), messageIDs(userMsgs))
+	if err != nil {
+		return err
+	}
+	snap.Topics = clusterTopics(userMsgs, vectors, s.cfg.TopicSimilarity)
+
+	snap.PeakHours = models.PeakHours(snap.ActiveHours, peakHourCount)
+	return s.conversations.SaveSnapshot(ctx, snap)
+}
+
+func messageIDs(msgs []models.Message) []uuid.UUID {
+	ids := make([]uuid.UUID, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
+
+func toneOf(sentiment *float64) string {
+	switch {
+	case sentiment == nil:
+		return models.ToneNeutral
+	case *sentiment > toneThreshold:
+		return models.TonePositive
+	case *sentiment < -toneThreshold:
+		return models.ToneNegative
+	}
+	return models.ToneNeutral
+}
+
+// sentimentTrend returns the mean sentiment of the messages that carry
+// any, overall and per local day, oldest day first.
+func sentimentTrend(msgs []models.Message, loc *time.Location) (*float64, []models.SentimentPoint) {
+	trend := []models.SentimentPoint{}
+	var total float64
+	var scored int
+	for _, m := range msgs {
+		score, ok := messageSentiment(m.Content)
+		if !ok {
+			continue
+		}
+		total += score
+		scored++
+
+		date := m.CreatedAt.In(loc).Format(time.DateOnly)
+		if n := len(trend); n == 0 || trend[n-1].Date != date {
+			trend = append(trend, models.SentimentPoint{Date: date})
+		}
+		pt := &trend[len(trend)-1]
+		// Running mean.
+		pt.Messages++
+		pt.Sentiment += (score - pt.Sentiment) / float64(pt.Messages)
+	}
+	if scored == 0 {
+		return nil, trend
+	}
+	mean := total / float64(scored)
+	return &mean, trend
+}
+
+// messageSentiment scores text from -1 to 1 by its positive and negative
+// words, a negation flipping the next of them. ok is false when the text
+// has none.
+func messageSentiment(text string) (score float64, ok bool) {
+	var pos, neg int
+	negated := false
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		polarity := 0
+		switch {
+		case negationWords[w]:
+			negated = true
+			continue
+		case positiveWords[w]:
+			polarity = 1
+		case negativeWords[w]:
+			polarity = -1
+		}
+		if polarity == 0 {
+			continue
+		}
+		if negated {
+			polarity = -polarity
+			negated = false
+		}
+		if polarity > 0 {
+			pos++
+		} else {
+			neg++
+		}
+	}
+	if pos+neg == 0 {
+		return 0, false
+	}
+	return float64(pos-neg) / float64(pos+neg), true
+}
+
+// clusterTopics groups messages whose embeddings are at least threshold
+// similar, each joining the closest topic so far or starting a new one.
+// Messages without an embedding are left out.
+func clusterTopics(msgs []models.Message, vectors map[uuid.UUID][]float32, threshold float64) []models.ConversationTopic {
+	type cluster struct {
+		sum  []float64
+		msgs []models.Message
+	}
+	var clusters []*cluster
+	clustered := 0
+	for _, m := range msgs {
+		v, ok := vectors[m.ID]
+		if !ok || len(v) == 0 {
+			continue
+		}
+		clustered++
+
+		var best *cluster
+		bestSim := threshold
+		for _, c := range clusters {
+			if sim := centroidSimilarity(v, c.sum); sim >= bestSim {
+				best, bestSim = c, sim
+			}
+		}
+		if best == nil {
+			best = &cluster{sum: make([]float64, len(v))}
+			clusters = append(clusters, best)
+		}
+		for i, x := range v {
+			best.sum[i] += float64(x)
+		}
+		best.msgs = append(best.msgs, m)
+	}
+
+	// Word document frequencies across all clustered messages, for
+	// picking each topic's distinctive words.
+	docWords := make(map[uuid.UUID]map[string]bool)
+	overall := make(map[string]int)
+	for _, c := range clusters {
+		for _, m := range c.msgs {
+			words := topicWords(m.Content)
+			docWords[m.ID] = words
+			for w := range words {
+				overall[w]++
+			}
+		}
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool { return len(clusters[i].msgs) > len(clusters[j].msgs) })
+
+	topics := []models.ConversationTopic{}
+	for _, c := range clusters {
+		if len(topics) == maxTopics || len(c.msgs) < minTopicMessages {
+			break
+		}
+		keywords := distinctiveWords(c.msgs, docWords, overall, clustered)
+		if len(keywords) == 0 {
+			continue
+		}
+		topics = append(topics, models.ConversationTopic{
+			Label:    strings.Join(keywords[:min(2, len(keywords))], " & "),
+			Keywords: keywords,
+			Messages: len(c.msgs),
+			Share:    float64(len(c.msgs)) / float64(clustered),
+		})
+	}
+	return topics
+}
+
+// centroidSimilarity returns the cosine similarity of v and a cluster's
+// vector sum.
+func centroidSimilarity(v []float32, sum []float64) float64 {
+	if len(v) != len(sum) {
+		return 0
+	}
+	var dot, vv, ss float64
+	for i, x := range v {
+		dot += float64(x) * sum[i]
+		vv += float64(x) * float64(x)
+		ss += sum[i] * sum[i]
+	}
+	if vv == 0 || ss == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(vv*ss)
+}
+
+// distinctiveWords returns up to topicKeywords words used in at least two
+// of the cluster's messages, ranked by how much more often they appear in
+// the cluster than overall.
+func distinctiveWords(msgs []models.Message, docWords map[uuid.UUID]map[string]bool, overall map[string]int, total int) []string {
+	counts := make(map[string]int)
+	for _, m := range msgs {
+		for w := range docWords[m.ID] {
+			counts[w]++
+		}
+	}
+
+	type scored struct {
+		word  string
+		score float64
+	}
+	var ranked []scored
+	for w, n := range counts {
+		if n < 2 {
+			continue
+		}
+		ranked = append(ranked, scored{w, float64(n)/float64(len(msgs)) - float64(overall[w])/float64(total)})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].word < ranked[j].word
+	})
+
+	words := []string{}
+	for _, r := range ranked {
+		if len(words) == topicKeywords {
+			break
+		}
+		words = append(words, r.word)
+	}
+	return words
+}
+
+// topicWords returns the words of text that can describe a topic.
+func topicWords(text string) map[string]bool {
+	words := wordSet(text)
+	for w := range words {
+		if topicStopwords[w] || positiveWords[w] || negativeWords[w] {
+			delete(words, w)
+		}
+	}
+	return words
+}
+
+var negationWords = setOf("not", "no", "never", "don't", "dont", "didn't", "didnt", "isn't", "isnt",
+	"wasn't", "wasnt", "can't", "cant", "won't", "wont", "doesn't", "doesnt", "aren't", "arent")
+
+var positiveWords = setOf("love", "loved", "lovely", "liked", "happy", "glad", "great", "good",
+	"awesome", "amazing", "wonderful", "fantastic", "excited", "exciting", "fun", "enjoy", "enjoyed",
+	"beautiful", "nice", "cool", "perfect", "proud", "grateful", "thanks", "thank", "sweet", "cute",
+	"yay", "best", "better", "calm", "relaxed", "hope", "hopeful", "laugh", "laughed", "smile")
+
+var negativeWords = setOf("hate", "hated", "sad", "unhappy", "bad", "awful", "terrible", "horrible",
+	"angry", "mad", "upset", "annoyed", "annoying", "tired", "exhausted", "stressed", "stress", "worried",
+	"worry", "anxious", "scared", "afraid", "lonely", "alone", "hurt", "hurts", "cry", "cried", "crying",
+	"sick", "boring", "bored", "worst", "worse", "sorry", "depressed", "frustrated", "disappointed", "ugh")
+
+var topicStopwords = setOf("about", "after", "again", "also", "been", "before", "being", "could",
+	"does", "doing", "dont", "from", "have", "having", "here", "just", "know", "like", "maybe", "more",
+	"much", "really", "should", "some", "something", "still", "than", "that", "thats", "their", "them",
+	"then", "there", "these", "they", "thing", "things", "think", "this", "those", "today", "very",
+	"want", "were", "what", "when", "where", "which", "while", "will", "with", "would", "yeah", "your",
+	"youre", "going", "gonna", "okay", "right", "time", "feel", "feeling", "make", "made", "because",
+	"only", "even", "into", "over", "other", "back", "well", "said", "tell", "told", "kind", "sure")
+
+func setOf(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
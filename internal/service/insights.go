@@ -2,6 +2,10 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,44 +17,243 @@ import (
 // InsightsService handles relationship insights business logic.
 type InsightsService struct {
 	insights      repository.InsightsRepository
-	relationships repository.RelationshipRepository
+	users         repository.UserRepository
+	milestones    *MilestoneService
+	conversations *ConversationService
 }
 
 // NewInsightsService creates a new InsightsService.
-func NewInsightsService(insights repository.InsightsRepository, relationships repository.RelationshipRepository) *InsightsService {
-	return &InsightsService{insights: insights, relationships: relationships}
+func NewInsightsService(
+	insights repository.InsightsRepository,
+	users repository.UserRepository,
+	milestones *MilestoneService,
+	conversations *ConversationService,
+) *InsightsService {
+	return &InsightsService{insights: insights, users: users, milestones: milestones, conversations: conversations}
 }
 
-// GetInsights computes the full insights payload for a user-companion pair.
-func (s *InsightsService) GetInsights(ctx context.Context, userID, companionID uuid.UUID) (*models.CompanionInsights, error) {
-	moodHistory, err := s.insights.GetMoodHistory(ctx, userID, companionID, 14)
+// Location returns the time zone the user's days are counted in.
+func (s *InsightsService) Location(ctx context.Context, userID uuid.UUID) (*time.Location, error) {
+	user, err := s.users.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	return user.Location(), nil
+}
+
+// localToday returns the current date in loc, as midnight UTC so dates
+// compare and subtract in whole days.
+func localToday(loc *time.Location) time.Time {
+	y, m, d := time.Now().In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Insights range limits.
+const (
+	DefaultInsightsDays = 14
+	maxInsightsDays     = 3 * 366
+	maxInsightsBuckets  = 400
+)
+
+// InsightsRangeFor builds and validates an insights range. Without from and
+// to it covers the last days days, today in loc included; with only one of
+// them it covers days days from or to it. Granularity defaults to day.
+func InsightsRangeFor(from, to *time.Time, days int, granularity string, loc *time.Location) (models.InsightsRange, error) {
+	switch granularity {
+	case "":
+		granularity = models.GranularityDay
+	case models.GranularityDay, models.GranularityWeek, models.GranularityMonth:
+	default:
+		return models.InsightsRange{}, fmt.Errorf("granularity must be day, week or month")
+	}
+	if days <= 0 {
+		days = DefaultInsightsDays
+	}
+
+	var start, end time.Time
+	switch {
+	case from != nil && to != nil:
+		start, end = *from, *to
+	case from != nil:
+		start, end = *from, from.AddDate(0, 0, days-1)
+	case to != nil:
+		start, end = to.AddDate(0, 0, -(days-1)), *to
+	default:
+		end = localToday(loc)
+		start = end.AddDate(0, 0, -(days - 1))
+	}
+	if end.Before(start) {
+		return models.InsightsRange{}, fmt.Errorf("from must not be after to")
+	}
+
+	span := int(end.Sub(start).Hours()/24) + 1
+	if span > maxInsightsDays {
+		return models.InsightsRange{}, fmt.Errorf("range must be at most %d days", maxInsightsDays)
+	}
+	buckets := span
+	switch granularity {
+	case models.GranularityWeek:
+		buckets = span/7 + 2
+	case models.GranularityMonth:
+		buckets = span/28 + 2
+	}
+	if buckets > maxInsightsBuckets {
+		return models.InsightsRange{}, fmt.Errorf("range is too long for %s granularity", granularity)
+	}
 
-	dates, err := s.insights.GetMessageDates(ctx, userID, companionID)
+	return models.InsightsRange{
+		From:        start.Format(time.DateOnly),
+		To:          end.Format(time.DateOnly),
+		Granularity: granularity,
+		Timezone:    loc.String(),
+	}, nil
+}
+
+// GetInsights computes the full insights payload for a user-companion pair,
+// with mood history and the aggregated series covering rng.
+func (s *InsightsService) GetInsights(ctx context.Context, userID, companionID uuid.UUID, rng models.InsightsRange) (*models.CompanionInsights, error) {
+	moodHistory, err := s.insights.GetMoodHistory(ctx, userID, companionID, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := s.insights.GetSeries(ctx, userID, companionID, rng)
 	if err != nil {
 		return nil, err
 	}
-	streak := computeStreak(dates)
+
+	state, err := s.insights.GetStreak(ctx, userID, &companionID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := s.Location(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	streak := streakInfo(state, localToday(loc))
 
 	stats, err := s.insights.GetStats(ctx, userID, companionID)
 	if err != nil {
 		return nil, err
 	}
 
-	state, _ := s.relationships.GetByUserAndCompanion(ctx, userID, companionID)
+	// Days together can cross a threshold without any interaction.
+	if _, err := s.milestones.Check(ctx, userID, companionID); err != nil {
+		slog.Warn("checking milestones failed", "error", err)
+	}
+	milestones, err := s.milestones.List(ctx, userID, companionID)
+	if err != nil {
+		return nil, err
+	}
 
-	milestones := computeMilestones(stats, state)
+	conversation, err := s.conversations.Latest(ctx, userID, companionID)
+	if err != nil {
+		return nil, err
+	}
 
 	return &models.CompanionInsights{
-		MoodHistory: moodHistory,
-		Streak:      streak,
-		Milestones:  milestones,
-		Stats:       *stats,
+		Range:        rng,
+		MoodHistory:  moodHistory,
+		Series:       series,
+		Streak:       streak,
+		Milestones:   milestones,
+		Stats:        *stats,
+		Conversation: conversation,
 	}, nil
 }
 
+// Overview limits.
+const (
+	DefaultHeatmapWeeks = 12
+	MaxHeatmapWeeks     = 52
+
+	overviewMilestones = 10
+)
+
+// GetConversationHistory returns up to limit of the pair's conversation
+// analytics snapshots, newest first.
+func (s *InsightsService) GetConversationHistory(ctx context.Context, userID, companionID uuid.UUID, limit int) ([]models.ConversationSnapshot, error) {
+	return s.conversations.History(ctx, userID, companionID, limit)
+}
+
+// GetOverview summarises the user's activity across all companions, with
+// an hourly activity heatmap over the last weeks weeks.
+func (s *InsightsService) GetOverview(ctx context.Context, userID uuid.UUID, weeks int) (*models.InsightsOverview, error) {
+	if weeks <= 0 || weeks > MaxHeatmapWeeks {
+		weeks = DefaultHeatmapWeeks
+	}
+	loc, err := s.Location(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	companions, err := s.insights.GetCompanionActivity(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.insights.GetStreak(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := s.milestones.Recent(ctx, userID, overviewMilestones)
+	if err != nil {
+		return nil, err
+	}
+
+	today := localToday(loc)
+	from := today.AddDate(0, 0, -(weeks*7 - 1))
+	since := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	counts, err := s.insights.GetActivityHeatmap(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &models.InsightsOverview{
+		Companions:       companions,
+		Streak:           streakInfo(state, today),
+		RecentMilestones: recent,
+		Heatmap: models.ActivityHeatmap{
+			Weeks:    weeks,
+			From:     from.Format(time.DateOnly),
+			Timezone: loc.String(),
+			Counts:   counts,
+		},
+	}
+
+	totals := &overview.Totals
+	totals.Companions = len(companions)
+	totals.ActiveDays = state.ActiveDays
+	for _, c := range companions {
+		totals.Messages += c.Messages
+		totals.Memories += c.Memories
+		totals.Reactions += c.Reactions
+		totals.ChatMinutes += c.ChatMinutes
+	}
+	for i := range companions {
+		c := &companions[i]
+		if totals.Messages > 0 {
+			c.MessageShare = float64(c.Messages) / float64(totals.Messages)
+		}
+		if overview.StrongestBond == nil || strongerBond(c, overview.StrongestBond) {
+			overview.StrongestBond = c
+		}
+	}
+
+	return overview, nil
+}
+
+// strongerBond reports whether a's bond beats b's: higher relationship
+// score, then higher mood, then more recent interaction.
+func strongerBond(a, b *models.CompanionActivity) bool {
+	if a.RelationshipScore != b.RelationshipScore {
+		return a.RelationshipScore > b.RelationshipScore
+	}
+	if a.MoodScore != b.MoodScore {
+		return a.MoodScore > b.MoodScore
+	}
+	return a.LastInteraction.After(b.LastInteraction)
+}
+
 // RecordMood records a daily mood snapshot (called from other services on interaction).
 func (s *InsightsService) RecordMood(ctx context.Context, userID, companionID uuid.UUID, moodScore float64) {
 	_ = s.insights.RecordMoodSnapshot(ctx, userID, companionID, moodScore)
@@ -61,145 +264,191 @@ func (s *InsightsService) GetReactionSummary(ctx context.Context, userID, compan
 	return s.insights.GetReactionSummary(ctx, userID, companionID)
 }
 
-func computeStreak(dates []time.Time) models.StreakInfo {
-	if len(dates) == 0 {
-		return models.StreakInfo{}
-	}
+// Reaction trend limits.
+const (
+	maxTrendStories = 20
 
-	today := time.Now().UTC().Truncate(24 * time.Hour)
+	// minCorrelationDays is the fewest days with both reactions and mood
+	// a correlation is reported for.
+	minCorrelationDays = 3
+)
 
-	current := 0
-	longest := 0
-	streak := 1
+// GetReactionTrends buckets the user's reactions to the companion's stories
+// over rng like the insights series, relates them to the relationship's
+// daily mood and breaks them down by story.
+func (s *InsightsService) GetReactionTrends(ctx context.Context, userID, companionID uuid.UUID, rng models.InsightsRange) (*models.ReactionTrends, error) {
+	series, err := s.insights.GetSeries(ctx, userID, companionID, rng)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.insights.GetReactionDays(ctx, userID, companionID, rng)
+	if err != nil {
+		return nil, err
+	}
+	moodHistory, err := s.insights.GetMoodHistory(ctx, userID, companionID, rng)
+	if err != nil {
+		return nil, err
+	}
+	stories, err := s.insights.GetStoryReactions(ctx, userID, companionID, rng, maxTrendStories)
+	if err != nil {
+		return nil, err
+	}
 
-	// dates are DESC order. Check if today or yesterday is included for current streak.
-	firstDate := dates[0].UTC().Truncate(24 * time.Hour)
-	daysSinceFirst := int(today.Sub(firstDate).Hours() / 24)
+	trends := &models.ReactionTrends{
+		Range:   rng,
+		Buckets: make([]models.ReactionBucket, len(series)),
+		Mood:    reactionMood(days, moodHistory),
+		Stories: stories,
+	}
 
-	if daysSinceFirst > 1 {
-		// Last message was more than 1 day ago — no current streak.
-		current = 0
-	} else {
-		current = 1
+	// Days and series buckets are both in date order, so one pass assigns
+	// each day to its bucket.
+	d := 0
+	for i, sb := range series {
+		var uses []models.ReactionUse
+		for ; d < len(days) && days[d].Date <= sb.End; d++ {
+			uses = mergeUses(uses, days[d].Uses)
+		}
+		total, counts, sentiment := tallyUses(uses)
+		b := models.ReactionBucket{
+			Start:    sb.Start,
+			End:      sb.End,
+			Total:    total,
+			Counts:   counts,
+			Dominant: models.DominantReaction(uses),
+			MoodAvg:  sb.MoodAvg,
+		}
+		if total > 0 {
+			b.Sentiment = &sentiment
+		}
+		trends.Buckets[i] = b
+		trends.Total += total
+	}
+
+	for i := range trends.Stories {
+		st := &trends.Stories[i]
+		st.Total, st.Counts, st.Sentiment = tallyUses(st.Uses)
+		st.Dominant = models.DominantReaction(st.Uses)
 	}
 
-	for i := 1; i < len(dates); i++ {
-		prev := dates[i-1].UTC().Truncate(24 * time.Hour)
-		curr := dates[i].UTC().Truncate(24 * time.Hour)
-		diff := int(prev.Sub(curr).Hours() / 24)
+	return trends, nil
+}
 
-		if diff == 1 {
-			streak++
-		} else {
-			if streak > longest {
-				longest = streak
+// mergeUses adds more to uses, summing the counts of reactions in both.
+func mergeUses(uses, more []models.ReactionUse) []models.ReactionUse {
+next:
+	for _, m := range more {
+		for i := range uses {
+			if uses[i].Reaction == m.Reaction {
+				uses[i].Count += m.Count
+				continue next
 			}
-			streak = 1
 		}
+		uses = append(uses, m)
+	}
+	return uses
+}
+
+// tallyUses returns the total, per-reaction counts and mean sentiment
+// weight of uses.
+func tallyUses(uses []models.ReactionUse) (int, map[string]int, float64) {
+	counts := make(map[string]int, len(uses))
+	total := 0
+	var weighted float64
+	for _, u := range uses {
+		counts[u.Reaction] += u.Count
+		total += u.Count
+		weighted += u.Weight * float64(u.Count)
 	}
-	if streak > longest {
-		longest = streak
+	if total == 0 {
+		return 0, counts, 0
+	}
+	return total, counts, weighted / float64(total)
+}
+
+// reactionMood relates each day's reactions to that day's mood snapshot.
+func reactionMood(days []models.ReactionDay, history []models.MoodSnapshot) models.ReactionMoodCorrelation {
+	mood := make(map[string]float64, len(history))
+	for _, h := range history {
+		mood[h.Date] = h.MoodScore
 	}
 
-	// Current streak: count consecutive days backward from today/yesterday.
-	if current > 0 {
-		for i := 1; i < len(dates); i++ {
-			prev := dates[i-1].UTC().Truncate(24 * time.Hour)
-			curr := dates[i].UTC().Truncate(24 * time.Hour)
-			if int(prev.Sub(curr).Hours()/24) == 1 {
-				current++
-			} else {
-				break
+	corr := models.ReactionMoodCorrelation{ByReaction: []models.ReactionMood{}}
+	var sentiments, scores []float64
+	var order []models.ReactionUse
+	byReaction := make(map[string]*models.ReactionMood)
+	for _, day := range days {
+		score, ok := mood[day.Date]
+		if !ok {
+			continue
+		}
+		_, _, sentiment := tallyUses(day.Uses)
+		sentiments = append(sentiments, sentiment)
+		scores = append(scores, score)
+
+		for _, u := range day.Uses {
+			rm, ok := byReaction[u.Reaction]
+			if !ok {
+				rm = &models.ReactionMood{Reaction: u.Reaction}
+				byReaction[u.Reaction] = rm
+				order = append(order, u)
 			}
+			rm.Days++
+			rm.MoodAvg += score
 		}
 	}
 
-	return models.StreakInfo{
-		Current: current,
-		Longest: longest,
+	corr.Days = len(scores)
+	if corr.Days >= minCorrelationDays {
+		corr.Coefficient = pearson(sentiments, scores)
 	}
+
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].Order != order[j].Order {
+			return order[i].Order < order[j].Order
+		}
+		return order[i].Reaction < order[j].Reaction
+	})
+	for _, u := range order {
+		rm := byReaction[u.Reaction]
+		rm.MoodAvg /= float64(rm.Days)
+		corr.ByReaction = append(corr.ByReaction, *rm)
+	}
+	return corr
 }
 
-func computeMilestones(stats *models.InsightStats, state *models.RelationshipState) []models.Milestone {
-	milestones := []models.Milestone{
-		{
-			Key:         "first_message",
-			Title:       "First Words",
-			Description: "Sent your first message",
-			Achieved:    stats.TotalMessages > 0,
-		},
-		{
-			Key:         "messages_50",
-			Title:       "Getting Chatty",
-			Description: "Exchanged 50 messages",
-			Achieved:    stats.TotalMessages >= 50,
-		},
-		{
-			Key:         "messages_200",
-			Title:       "Deep Conversations",
-			Description: "Exchanged 200 messages",
-			Achieved:    stats.TotalMessages >= 200,
-		},
-		{
-			Key:         "messages_1000",
-			Title:       "Inseparable",
-			Description: "Exchanged 1,000 messages",
-			Achieved:    stats.TotalMessages >= 1000,
-		},
-		{
-			Key:         "first_memory",
-			Title:       "First Memory",
-			Description: "Saved your first memory together",
-			Achieved:    stats.TotalMemories > 0,
-		},
-		{
-			Key:         "memories_10",
-			Title:       "Memory Lane",
-			Description: "Saved 10 memories together",
-			Achieved:    stats.TotalMemories >= 10,
-		},
-		{
-			Key:         "week_together",
-			Title:       "One Week Together",
-			Description: "Been connected for 7 days",
-			Achieved:    stats.DaysTogether >= 7,
-		},
-		{
-			Key:         "month_together",
-			Title:       "One Month Together",
-			Description: "Been connected for 30 days",
-			Achieved:    stats.DaysTogether >= 30,
-		},
+// pearson returns the correlation coefficient of xs and ys, or nil when
+// either doesn't vary.
+func pearson(xs, ys []float64) *float64 {
+	n := float64(len(xs))
+	var sx, sy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
 	}
+	mx, my := sx/n, sy/n
 
-	if state != nil {
-		milestones = append(milestones,
-			models.Milestone{
-				Key:         "mood_happy",
-				Title:       "Warming Up",
-				Description: "Reached Happy mood level",
-				Achieved:    state.MoodScore >= 50,
-			},
-			models.Milestone{
-				Key:         "mood_attached",
-				Title:       "Deeply Attached",
-				Description: "Reached Attached mood level",
-				Achieved:    state.MoodScore >= 80,
-			},
-			models.Milestone{
-				Key:         "bond_50",
-				Title:       "Strong Bond",
-				Description: "Relationship score reached 50",
-				Achieved:    state.RelationshipScore >= 50,
-			},
-			models.Milestone{
-				Key:         "bond_max",
-				Title:       "Soulmates",
-				Description: "Reached maximum relationship score",
-				Achieved:    state.RelationshipScore >= 100,
-			},
-		)
-	}
-
-	return milestones
+	var cov, vx, vy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return nil
+	}
+	r := cov / math.Sqrt(vx*vy)
+	return &r
+}
+
+// streakInfo reads a rolled-up streak as of today, the user's current
+// local date at midnight UTC. The latest run is current while its last day
+// is today or yesterday.
+func streakInfo(state *models.StreakState, today time.Time) models.StreakInfo {
+	info := models.StreakInfo{Longest: state.LongestRun}
+	if state.LastActiveDate != nil && today.Sub(state.LastActiveDate.UTC()) <= 24*time.Hour {
+		info.Current = state.CurrentRun
+	}
+	return info
 }
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"ai-companion-be/internal/models"
+)
+
+func TestStreakInfo(t *testing.T) {
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(n int) *time.Time {
+		d := today.AddDate(0, 0, -n)
+		return &d
+	}
+
+	tests := []struct {
+		name  string
+		state models.StreakState
+		want  models.StreakInfo
+	}{
+		{"no messages", models.StreakState{}, models.StreakInfo{}},
+		{"active today", models.StreakState{LastActiveDate: daysAgo(0), CurrentRun: 4, LongestRun: 6}, models.StreakInfo{Current: 4, Longest: 6}},
+		{"active yesterday", models.StreakState{LastActiveDate: daysAgo(1), CurrentRun: 3, LongestRun: 3}, models.StreakInfo{Current: 3, Longest: 3}},
+		{"one day gap", models.StreakState{LastActiveDate: daysAgo(2), CurrentRun: 3, LongestRun: 5}, models.StreakInfo{Current: 0, Longest: 5}},
+		{"long gap", models.StreakState{LastActiveDate: daysAgo(30), CurrentRun: 1, LongestRun: 2}, models.StreakInfo{Current: 0, Longest: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := streakInfo(&tt.state, today); got != tt.want {
+				t.Errorf("streakInfo = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
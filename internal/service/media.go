@@ -0,0 +1,346 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/config"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+	"ai-companion-be/internal/storage"
+)
+
+const (
+	storyLifetime     = 24 * time.Hour
+	defaultSlideSecs  = 5
+	thumbnailMaxDim   = 320
+	thumbnailMimeType = "image/jpeg"
+)
+
+// allowedMedia maps sniffed content types to file extensions.
+// Only these types are accepted for upload.
+var allowedMedia = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"video/mp4":  ".mp4",
+	"video/webm": ".webm",
+}
+
+// Upload is a file received from a multipart request.
+type Upload struct {
+	Body     io.Reader
+	Size     int64
+	Duration int // display duration in seconds; story slides only
+
+	// Optional slide text, story slides only.
+	Caption   *string
+	LinkURL   *string
+	LinkLabel *string
+}
+
+const maxCaptionLength = 500
+
+// MediaService handles uploads of companion avatars and story media.
+type MediaService struct {
+	store      storage.Storage
+	companions repository.CompanionRepository
+	stories    repository.StoryRepository
+	assets     repository.AssetRepository
+	cfg        config.StorageConfig
+}
+
+// NewMediaService creates a new MediaService.
+func NewMediaService(
+	store storage.Storage,
+	companions repository.CompanionRepository,
+	stories repository.StoryRepository,
+	assets repository.AssetRepository,
+	cfg config.StorageConfig,
+) *MediaService {
+	return &MediaService{store: store, companions: companions, stories: stories, assets: assets, cfg: cfg}
+}
+
+// MaxUploadSize returns the largest upload any endpoint accepts, for request body limits.
+func (s *MediaService) MaxUploadSize() int64 {
+	return max(s.cfg.MaxImageSize, s.cfg.MaxVideoSize)
+}
+
+// UploadAvatar stores a new avatar image for a companion and returns the updated companion.
+func (s *MediaService) UploadAvatar(ctx context.Context, companionID uuid.UUID, up Upload) (*models.Companion, error) {
+	if _, err := s.companions.GetByID(ctx, companionID); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.save(ctx, fmt.Sprintf("avatars/%s", companionID), up, true)
+	if err != nil {
+		return nil, err
+	}
+
+	companion, err := s.companions.UpdateAvatar(ctx, companionID, stored.url, stored.thumbnailURL)
+	if err != nil {
+		s.discard(ctx, stored)
+		return nil, err
+	}
+	return companion, nil
+}
+
+// CreateStory stores an uploaded file as the single slide of a new story
+// that expires after the standard 24 hours.
+func (s *MediaService) CreateStory(ctx context.Context, companionID uuid.UUID, up Upload) (*models.Story, error) {
+	if _, err := s.companions.GetByID(ctx, companionID); err != nil {
+		return nil, err
+	}
+
+	story := &models.Story{
+		ID:          uuid.New(),
+		CompanionID: companionID,
+		ExpiresAt:   time.Now().Add(storyLifetime),
+	}
+
+	media, stored, err := s.storeSlide(ctx, story, up)
+	if err != nil {
+		return nil, err
+	}
+
+	story.Media = []models.StoryMedia{*media}
+	if err := s.stories.Create(ctx, story); err != nil {
+		s.discard(ctx, stored)
+		return nil, fmt.Errorf("creating story: %w", err)
+	}
+	return story, nil
+}
+
+// AddStoryMedia stores an uploaded file as a new slide at the end of an existing story.
+func (s *MediaService) AddStoryMedia(ctx context.Context, storyID uuid.UUID, up Upload) (*models.StoryMedia, error) {
+	story, err := s.stories.GetByID(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	media, stored, err := s.storeSlide(ctx, story, up)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.stories.CreateMedia(ctx, media); err != nil {
+		s.discard(ctx, stored)
+		return nil, fmt.Errorf("creating story media: %w", err)
+	}
+	return media, nil
+}
+
+// UploadAsset adds a file to a companion's asset library for generated stories.
+func (s *MediaService) UploadAsset(ctx context.Context, companionID uuid.UUID, up Upload, description string, tags []string) (*models.CompanionAsset, error) {
+	if _, err := s.companions.GetByID(ctx, companionID); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.save(ctx, fmt.Sprintf("assets/%s", companionID), up, false)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := &models.CompanionAsset{
+		ID:           uuid.New(),
+		CompanionID:  companionID,
+		MediaURL:     stored.url,
+		MediaType:    stored.mediaType,
+		ThumbnailURL: stored.thumbnailURL,
+		Description:  description,
+		Tags:         tags,
+	}
+	if err := s.assets.Create(ctx, asset); err != nil {
+		s.discard(ctx, stored)
+		return nil, fmt.Errorf("creating asset: %w", err)
+	}
+	return asset, nil
+}
+
+// GetAssets returns a companion's asset library, least recently used first.
+func (s *MediaService) GetAssets(ctx context.Context, companionID uuid.UUID) ([]models.CompanionAsset, error) {
+	return s.assets.GetByCompanion(ctx, companionID)
+}
+
+// AddTextSlide appends a text-only slide to an existing story.
+func (s *MediaService) AddTextSlide(ctx context.Context, storyID uuid.UUID, req models.CreateTextSlideRequest) (*models.StoryMedia, error) {
+	caption := strings.TrimSpace(req.Caption)
+	if caption == "" {
+		return nil, fmt.Errorf("caption is required for text slides")
+	}
+	if err := validateSlideText(&caption, req.LinkURL); err != nil {
+		return nil, err
+	}
+	if req.Background != nil && len(req.Background.Gradient) == 1 {
+		return nil, fmt.Errorf("background gradient needs at least two colours")
+	}
+
+	story, err := s.stories.GetByID(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+
+	media := &models.StoryMedia{
+		ID:         uuid.New(),
+		StoryID:    story.ID,
+		MediaType:  models.MediaTypeText,
+		Duration:   slideDuration(req.Duration),
+		Caption:    &caption,
+		LinkURL:    req.LinkURL,
+		LinkLabel:  req.LinkLabel,
+		Background: req.Background,
+	}
+	if err := s.stories.CreateMedia(ctx, media); err != nil {
+		return nil, fmt.Errorf("creating story media: %w", err)
+	}
+	return media, nil
+}
+
+func (s *MediaService) storeSlide(ctx context.Context, story *models.Story, up Upload) (*models.StoryMedia, *storedMedia, error) {
+	if err := validateSlideText(up.Caption, up.LinkURL); err != nil {
+		return nil, nil, err
+	}
+
+	stored, err := s.save(ctx, fmt.Sprintf("stories/%s/%s", story.CompanionID, story.ID), up, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &models.StoryMedia{
+		ID:           uuid.New(),
+		StoryID:      story.ID,
+		MediaURL:     stored.url,
+		MediaType:    stored.mediaType,
+		Duration:     slideDuration(up.Duration),
+		ThumbnailURL: stored.thumbnailURL,
+		Caption:      up.Caption,
+		LinkURL:      up.LinkURL,
+		LinkLabel:    up.LinkLabel,
+	}, stored, nil
+}
+
+func slideDuration(d int) int {
+	if d <= 0 || d > 60 {
+		return defaultSlideSecs
+	}
+	return d
+}
+
+// validateSlideText checks the optional caption and link shared by all slide types.
+func validateSlideText(caption, linkURL *string) error {
+	if caption != nil && len(*caption) > maxCaptionLength {
+		return fmt.Errorf("caption must be at most %d characters", maxCaptionLength)
+	}
+	if linkURL != nil {
+		u, err := url.Parse(*linkURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("link_url must be an http or https URL")
+		}
+	}
+	return nil
+}
+
+type storedMedia struct {
+	url          string
+	thumbnailURL *string
+	mediaType    string   // "image" or "video"
+	keys         []string // the stored objects, thumbnail included
+}
+
+// discard deletes the objects of an upload whose database write failed, so
+// they are not left orphaned. It runs even if ctx was cancelled.
+func (s *MediaService) discard(ctx context.Context, stored *storedMedia) {
+	ctx = context.WithoutCancel(ctx)
+	for _, key := range stored.keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			slog.Warn("deleting orphaned upload failed", "key", key, "error", err)
+		}
+	}
+}
+
+// save validates an upload by sniffing its content, writes it under prefix,
+// and generates a JPEG thumbnail for decodable images.
+func (s *MediaService) save(ctx context.Context, prefix string, up Upload, imageOnly bool) (*storedMedia, error) {
+	br := bufio.NewReaderSize(up.Body, 512)
+	head, _ := br.Peek(512)
+	contentType := http.DetectContentType(head)
+
+	ext, ok := allowedMedia[contentType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported media type %s", contentType)
+	}
+
+	mediaType := "image"
+	limit := s.cfg.MaxImageSize
+	if strings.HasPrefix(contentType, "video/") {
+		if imageOnly {
+			return nil, fmt.Errorf("only images are allowed")
+		}
+		mediaType = "video"
+		limit = s.cfg.MaxVideoSize
+	}
+	if up.Size <= 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if up.Size > limit {
+		return nil, fmt.Errorf("file too large: %s uploads are limited to %d MB", mediaType, limit>>20)
+	}
+
+	id := uuid.New()
+	key := fmt.Sprintf("%s/%s%s", prefix, id, ext)
+	out := &storedMedia{mediaType: mediaType}
+
+	if mediaType == "video" {
+		if err := s.store.Put(ctx, key, br, up.Size, contentType); err != nil {
+			return nil, fmt.Errorf("storing media: %w", err)
+		}
+		out.url = s.store.URL(key)
+		out.keys = []string{key}
+		return out, nil
+	}
+
+	// Images are small enough to buffer, which lets us thumbnail them too.
+	data, err := io.ReadAll(io.LimitReader(br, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading upload: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("file too large: %s uploads are limited to %d MB", mediaType, limit>>20)
+	}
+
+	if err := s.store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, fmt.Errorf("storing media: %w", err)
+	}
+	out.url = s.store.URL(key)
+	out.keys = []string{key}
+
+	// Thumbnails are best-effort: WebP has no stdlib decoder, and a corrupt
+	// image should not fail an otherwise valid upload.
+	thumb, err := storage.Thumbnail(bytes.NewReader(data), thumbnailMaxDim)
+	if err != nil {
+		slog.Warn("skipping thumbnail", "key", key, "error", err)
+		return out, nil
+	}
+
+	thumbKey := fmt.Sprintf("%s/%s_thumb.jpg", prefix, id)
+	if err := s.store.Put(ctx, thumbKey, bytes.NewReader(thumb), int64(len(thumb)), thumbnailMimeType); err != nil {
+		slog.Warn("storing thumbnail failed", "key", thumbKey, "error", err)
+		return out, nil
+	}
+	thumbURL := s.store.URL(thumbKey)
+	out.thumbnailURL = &thumbURL
+	out.keys = append(out.keys, thumbKey)
+
+	return out, nil
+}
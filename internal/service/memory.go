@@ -2,50 +2,195 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 
+	"ai-companion-be/internal/ai"
+	"ai-companion-be/internal/config"
+	"ai-companion-be/internal/cursor"
 	"ai-companion-be/internal/models"
 	"ai-companion-be/internal/repository"
 )
 
 // MemoryService handles memory-related business logic.
 type MemoryService struct {
-	memories repository.MemoryRepository
+	memories   repository.MemoryRepository
+	tags       repository.MemoryTagRepository
+	messages   repository.MessageRepository
+	stories    repository.StoryRepository
+	ai         *ai.Client
+	milestones *MilestoneService
+	cursors    *cursor.Codec
+	cfg        config.MemoryConfig
 }
 
 // NewMemoryService creates a new MemoryService.
-func NewMemoryService(memories repository.MemoryRepository) *MemoryService {
-	return &MemoryService{memories: memories}
+func NewMemoryService(
+	memories repository.MemoryRepository,
+	tags repository.MemoryTagRepository,
+	messages repository.MessageRepository,
+	stories repository.StoryRepository,
+	aiClient *ai.Client,
+	milestones *MilestoneService,
+	cursors *cursor.Codec,
+	cfg config.MemoryConfig,
+) *MemoryService {
+	return &MemoryService{
+		memories:   memories,
+		tags:       tags,
+		messages:   messages,
+		stories:    stories,
+		ai:         aiClient,
+		milestones: milestones,
+		cursors:    cursors,
+		cfg:        cfg,
+	}
 }
 
+// maxTagLength bounds tag names, in characters.
+const maxTagLength = 32
+
+// Importance boosts for each way a memory is reinforced.
+const (
+	chatRecallBoost = 0.05
+	resurfaceBoost  = 0.1
+	rescueBoost     = 0.15
+)
+
+// maxFadingMemories caps the fading view.
+const maxFadingMemories = 50
+
 // Create stores a new memory for a user-companion pair.
 func (s *MemoryService) Create(ctx context.Context, userID, companionID uuid.UUID, req models.CreateMemoryRequest) (*models.Memory, error) {
 	if req.Content == "" {
 		return nil, fmt.Errorf("memory content is required")
 	}
 
+	// The source message must be from this conversation.
+	if req.MessageID != nil {
+		if _, err := s.messages.GetKey(ctx, userID, companionID, *req.MessageID); err != nil {
+			return nil, err
+		}
+	}
+
+	// The source slide must be from one of this companion's stories.
+	if req.StoryMediaID != nil {
+		media, err := s.stories.GetMediaByID(ctx, *req.StoryMediaID)
+		if err != nil {
+			return nil, err
+		}
+		story, err := s.stories.GetByID(ctx, media.StoryID)
+		if err != nil {
+			return nil, err
+		}
+		if story.CompanionID != companionID {
+			return nil, fmt.Errorf("story does not belong to this companion")
+		}
+	}
+
+	tag, err := s.resolveTag(ctx, userID, req.Tag)
+	if err != nil {
+		return nil, err
+	}
+
 	memory := &models.Memory{
-		ID:          uuid.New(),
-		UserID:      userID,
-		CompanionID: companionID,
-		MessageID:   req.MessageID,
-		Content:     req.Content,
-		Tag:         req.Tag,
-		Pinned:      false,
+		ID:           uuid.New(),
+		UserID:       userID,
+		CompanionID:  companionID,
+		MessageID:    req.MessageID,
+		StoryMediaID: req.StoryMediaID,
+		Content:      req.Content,
+		Tag:          tag,
+		Pinned:       false,
+	}
+
+	memory.Importance, err = s.ai.RateMemoryImportance(ctx, *memory)
+	if err != nil {
+		slog.Warn("openai importance rating failed, using fallback", "error", err)
+		memory.Importance = estimateImportance(*memory)
 	}
 
 	if err := s.memories.Create(ctx, memory); err != nil {
 		return nil, fmt.Errorf("creating memory: %w", err)
 	}
 
+	if _, err := s.milestones.Check(ctx, userID, companionID); err != nil {
+		slog.Warn("checking milestones failed", "error", err)
+	}
+
 	return memory, nil
 }
 
-// GetByCompanion returns a paginated list of memories for a user-companion pair, pinned first.
-func (s *MemoryService) GetByCompanion(ctx context.Context, userID, companionID uuid.UUID, limit int) (*models.MemoryPage, error) {
-	return s.memories.GetByUserAndCompanion(ctx, userID, companionID, limit)
+const (
+	memoryCursorKind   = "memories"
+	defaultMemoryLimit = 50
+	maxMemoryLimit     = 100
+)
+
+// GetByCompanion returns a page of memories for a user-companion pair,
+// pinned first and then newest first. A cursor is only valid with the
+// filters it was issued for; malformed or mismatched cursors return an
+// error wrapping cursor.ErrInvalid.
+func (s *MemoryService) GetByCompanion(ctx context.Context, userID, companionID uuid.UUID, q models.MemoryQuery) (*models.MemoryPage, error) {
+	limit := q.Limit
+	if limit <= 0 || limit > maxMemoryLimit {
+		limit = defaultMemoryLimit
+	}
+	if q.Tag != nil {
+		tag := normalizeTag(*q.Tag)
+		q.Tag = &tag
+	}
+
+	kind := memoryCursorKindFor(companionID, q.MemoryFilter)
+
+	var after *models.MemoryKey
+	if q.Cursor != "" {
+		var key models.MemoryKey
+		if err := s.cursors.Decode(kind, q.Cursor, &key); err != nil {
+			return nil, err
+		}
+		after = &key
+	}
+
+	memories, err := s.memories.GetByUserAndCompanion(ctx, userID, companionID, q.MemoryFilter, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.MemoryPage{}
+	if len(memories) > limit {
+		page.HasMore = true
+		memories = memories[:limit]
+	}
+	page.Memories = memories
+	if page.HasMore {
+		last := memories[len(memories)-1]
+		page.NextCursor = s.cursors.Encode(kind, models.MemoryKey{Pinned: last.Pinned, CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return page, nil
+}
+
+// memoryCursorKindFor scopes cursors to one companion's list under one set
+// of filters, so a cursor can't be replayed against a different listing.
+func memoryCursorKindFor(companionID uuid.UUID, f models.MemoryFilter) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:%s:pinned=%t", memoryCursorKind, companionID, f.PinnedOnly)
+	if f.Tag != nil {
+		fmt.Fprintf(&b, ":tag=%q", *f.Tag)
+	}
+	if f.From != nil {
+		fmt.Fprintf(&b, ":from=%d", f.From.UnixNano())
+	}
+	if f.To != nil {
+		fmt.Fprintf(&b, ":to=%d", f.To.UnixNano())
+	}
+	return b.String()
 }
 
 // Delete removes a memory, verifying ownership.
@@ -71,3 +216,217 @@ func (s *MemoryService) TogglePin(ctx context.Context, userID uuid.UUID, memoryI
 	}
 	return s.memories.TogglePin(ctx, memoryID)
 }
+
+// GetFading returns the pair's memories that will drop out of the
+// companion's active recall within the fading window, or already have,
+// soonest first.
+func (s *MemoryService) GetFading(ctx context.Context, userID, companionID uuid.UUID) ([]models.FadingMemory, error) {
+	if s.cfg.FadeThreshold <= 0 {
+		// Nothing ever fades.
+		return []models.FadingMemory{}, nil
+	}
+	now := time.Now()
+	return s.memories.GetFading(ctx, userID, companionID, now, halfLifeDays(s.cfg.HalfLife),
+		s.cfg.FadeThreshold, now.Add(s.cfg.FadingWindow), maxFadingMemories)
+}
+
+// Rescue reinforces a fading memory, verifying ownership: its forgetting
+// curve restarts and its importance rises.
+func (s *MemoryService) Rescue(ctx context.Context, userID, memoryID uuid.UUID) (*models.Memory, error) {
+	memory, err := s.memories.GetByID(ctx, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	if memory.UserID != userID {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if err := s.memories.Reinforce(ctx, []uuid.UUID{memoryID}, rescueBoost); err != nil {
+		return nil, err
+	}
+	return s.memories.GetByID(ctx, memoryID)
+}
+
+// Update edits a memory's content and/or tag, verifying ownership. The
+// previous values are kept in the memory's edit history.
+func (s *MemoryService) Update(ctx context.Context, userID, memoryID uuid.UUID, req models.UpdateMemoryRequest) (*models.Memory, error) {
+	memory, err := s.memories.GetByID(ctx, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	if memory.UserID != userID {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if req.Content == nil && req.Tag == nil {
+		return nil, fmt.Errorf("nothing to update")
+	}
+
+	content := memory.Content
+	if req.Content != nil {
+		if *req.Content == "" {
+			return nil, fmt.Errorf("memory content is required")
+		}
+		content = *req.Content
+	}
+
+	tag := memory.Tag
+	if req.Tag != nil {
+		if tag, err = s.resolveTag(ctx, userID, req.Tag); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.memories.Update(ctx, memoryID, content, tag)
+}
+
+// GetEdits returns a memory's edit history, newest first, verifying ownership.
+func (s *MemoryService) GetEdits(ctx context.Context, userID, memoryID uuid.UUID) ([]models.MemoryEdit, error) {
+	memory, err := s.memories.GetByID(ctx, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	if memory.UserID != userID {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return s.memories.GetEdits(ctx, memoryID)
+}
+
+// GetTags returns the user's tag vocabulary with memory counts.
+func (s *MemoryService) GetTags(ctx context.Context, userID uuid.UUID) ([]models.MemoryTag, error) {
+	return s.tags.GetByUser(ctx, userID)
+}
+
+// GetTagCounts returns tag usage across the user's memories with one
+// companion, most used first.
+func (s *MemoryService) GetTagCounts(ctx context.Context, userID, companionID uuid.UUID) ([]models.MemoryTagCount, error) {
+	return s.tags.CountByCompanion(ctx, userID, companionID)
+}
+
+// CreateTag adds a tag to the user's vocabulary.
+func (s *MemoryService) CreateTag(ctx context.Context, userID uuid.UUID, req models.MemoryTagRequest) (*models.MemoryTag, error) {
+	name, err := validTag(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := s.tags.Create(ctx, userID, name)
+	if errors.Is(err, repository.ErrTagExists) {
+		return nil, fmt.Errorf("tag %q already exists", name)
+	}
+	return tag, err
+}
+
+// RenameTag renames one of the user's tags on every memory carrying it.
+// Renaming onto an existing tag is refused; merge them instead.
+func (s *MemoryService) RenameTag(ctx context.Context, userID, tagID uuid.UUID, req models.MemoryTagRequest) (*models.MemoryTag, error) {
+	if _, err := s.ownTag(ctx, userID, tagID); err != nil {
+		return nil, err
+	}
+	name, err := validTag(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := s.tags.Rename(ctx, tagID, name)
+	if errors.Is(err, repository.ErrTagExists) {
+		return nil, fmt.Errorf("tag %q already exists; merge into it instead", name)
+	}
+	return tag, err
+}
+
+// MergeTag retags every memory carrying one of the user's tags with another
+// and removes the first. It returns the surviving tag.
+func (s *MemoryService) MergeTag(ctx context.Context, userID, sourceID uuid.UUID, req models.MergeMemoryTagRequest) (*models.MemoryTag, error) {
+	if sourceID == req.Into {
+		return nil, fmt.Errorf("cannot merge a tag into itself")
+	}
+	if _, err := s.ownTag(ctx, userID, sourceID); err != nil {
+		return nil, err
+	}
+	if _, err := s.ownTag(ctx, userID, req.Into); err != nil {
+		return nil, err
+	}
+	return s.tags.Merge(ctx, sourceID, req.Into)
+}
+
+// DeleteTag removes one of the user's tags from the vocabulary and from
+// every memory carrying it.
+func (s *MemoryService) DeleteTag(ctx context.Context, userID, tagID uuid.UUID) error {
+	if _, err := s.ownTag(ctx, userID, tagID); err != nil {
+		return err
+	}
+	return s.tags.Delete(ctx, tagID)
+}
+
+func (s *MemoryService) ownTag(ctx context.Context, userID, tagID uuid.UUID) (*models.MemoryTag, error) {
+	tag, err := s.tags.GetByID(ctx, tagID)
+	if err != nil {
+		return nil, err
+	}
+	if tag.UserID != userID {
+		return nil, fmt.Errorf("tag not found")
+	}
+	return tag, nil
+}
+
+// resolveTag normalises a requested tag and adds it to the user's
+// vocabulary. A nil or blank tag means no tag.
+func (s *MemoryService) resolveTag(ctx context.Context, userID uuid.UUID, raw *string) (*string, error) {
+	if raw == nil || normalizeTag(*raw) == "" {
+		return nil, nil
+	}
+	name, err := validTag(*raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tags.Ensure(ctx, userID, name); err != nil {
+		return nil, err
+	}
+	return &name, nil
+}
+
+// normalizeTag lower-cases a tag and collapses its whitespace, so "Road
+// Trip " and "road trip" are the same tag.
+func normalizeTag(raw string) string {
+	return strings.ToLower(strings.Join(strings.Fields(raw), " "))
+}
+
+func validTag(raw string) (string, error) {
+	name := normalizeTag(raw)
+	if name == "" {
+		return "", fmt.Errorf("tag name is required")
+	}
+	if utf8.RuneCountInString(name) > maxTagLength {
+		return "", fmt.Errorf("tag name must be at most %d characters", maxTagLength)
+	}
+	return name, nil
+}
+
+// importantWords raise a memory's fallback importance rating. Like wordSet,
+// they are longer than three letters.
+var importantWords = map[string]bool{
+	"love": true, "birthday": true, "first": true, "family": true, "mother": true, "father": true,
+	"promise": true, "never": true, "always": true, "dream": true, "scared": true, "proud": true,
+	"anniversary": true, "married": true, "wedding": true, "baby": true, "graduated": true,
+	"sorry": true, "miss": true, "forever": true, "secret": true,
+}
+
+// estimateImportance rates a memory without OpenAI: a neutral 0.5, raised
+// for emotionally weighty words, a tag and length.
+func estimateImportance(m models.Memory) float64 {
+	score := 0.5
+	for w := range wordSet(m.Content) {
+		if importantWords[w] {
+			score += 0.1
+		}
+	}
+	if m.Tag != nil {
+		score += 0.05
+	}
+	if utf8.RuneCountInString(m.Content) > 140 {
+		score += 0.05
+	}
+	return min(score, 0.9)
+}
+
+// halfLifeDays converts a half-life to fractional days for SQL.
+func halfLifeDays(d time.Duration) float64 {
+	return d.Hours() / 24
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/ai"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+)
+
+const maxCollectionName = 50
+
+// CollectionService handles memory collections ("albums").
+type CollectionService struct {
+	collections repository.MemoryCollectionRepository
+	memories    repository.MemoryRepository
+	companions  repository.CompanionRepository
+	ai          *ai.Client
+}
+
+// NewCollectionService creates a new CollectionService.
+func NewCollectionService(
+	collections repository.MemoryCollectionRepository,
+	memories repository.MemoryRepository,
+	companions repository.CompanionRepository,
+	aiClient *ai.Client,
+) *CollectionService {
+	return &CollectionService{collections: collections, memories: memories, companions: companions, ai: aiClient}
+}
+
+// List returns a user's collections with a companion, in display order.
+func (s *CollectionService) List(ctx context.Context, userID, companionID uuid.UUID) ([]models.MemoryCollection, error) {
+	return s.collections.GetByUserAndCompanion(ctx, userID, companionID)
+}
+
+// Get returns one collection with its memories in order.
+func (s *CollectionService) Get(ctx context.Context, userID, companionID, collectionID uuid.UUID) (*models.MemoryCollection, error) {
+	c, err := s.ownCollection(ctx, userID, companionID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	if c.Memories, err = s.collections.GetMemories(ctx, collectionID); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Create makes an empty collection for a user-companion pair.
+func (s *CollectionService) Create(ctx context.Context, userID, companionID uuid.UUID, req models.CreateCollectionRequest) (*models.MemoryCollection, error) {
+	name, err := validCollectionName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &models.MemoryCollection{
+		ID:               uuid.New(),
+		UserID:           userID,
+		CompanionID:      companionID,
+		Name:             name,
+		Description:      optionalText(req.Description),
+		ExplicitCoverURL: optionalText(req.CoverURL),
+		SortOrder:        req.SortOrder,
+	}
+	if err := s.collections.Create(ctx, c); err != nil {
+		if errors.Is(err, repository.ErrCollectionExists) {
+			return nil, fmt.Errorf("a collection named %q already exists", name)
+		}
+		return nil, fmt.Errorf("creating collection: %w", err)
+	}
+	c.CoverURL = c.ExplicitCoverURL
+	return c, nil
+}
+
+// Update renames, describes, re-covers or reorders a collection.
+func (s *CollectionService) Update(ctx context.Context, userID, companionID, collectionID uuid.UUID, req models.UpdateCollectionRequest) (*models.MemoryCollection, error) {
+	c, err := s.ownCollection(ctx, userID, companionID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if c.Name, err = validCollectionName(*req.Name); err != nil {
+			return nil, err
+		}
+	}
+	if req.Description != nil {
+		c.Description = optionalText(req.Description)
+	}
+	if req.CoverURL != nil {
+		c.ExplicitCoverURL = optionalText(req.CoverURL)
+	}
+	if req.SortOrder != nil {
+		c.SortOrder = *req.SortOrder
+	}
+
+	if err := s.collections.Update(ctx, c); err != nil {
+		if errors.Is(err, repository.ErrCollectionExists) {
+			return nil, fmt.Errorf("a collection named %q already exists", c.Name)
+		}
+		return nil, err
+	}
+	// Re-read so the resolved cover reflects the change.
+	return s.collections.GetByID(ctx, collectionID)
+}
+
+// Delete removes a collection. Its memories are kept.
+func (s *CollectionService) Delete(ctx context.Context, userID, companionID, collectionID uuid.UUID) error {
+	if _, err := s.ownCollection(ctx, userID, companionID, collectionID); err != nil {
+		return err
+	}
+	return s.collections.Delete(ctx, collectionID)
+}
+
+// AddMemory appends one of the pair's memories to a collection.
+func (s *CollectionService) AddMemory(ctx context.Context, userID, companionID, collectionID uuid.UUID, req models.AddCollectionMemoryRequest) error {
+	if _, err := s.ownCollection(ctx, userID, companionID, collectionID); err != nil {
+		return err
+	}
+	memory, err := s.memories.GetByID(ctx, req.MemoryID)
+	if err != nil {
+		return err
+	}
+	if memory.UserID != userID || memory.CompanionID != companionID {
+		return fmt.Errorf("memory not found")
+	}
+	return s.collections.AddMemory(ctx, collectionID, req.MemoryID)
+}
+
+// RemoveMemory takes a memory out of a collection.
+func (s *CollectionService) RemoveMemory(ctx context.Context, userID, companionID, collectionID, memoryID uuid.UUID) error {
+	if _, err := s.ownCollection(ctx, userID, companionID, collectionID); err != nil {
+		return err
+	}
+	return s.collections.RemoveMemory(ctx, collectionID, memoryID)
+}
+
+// Reorder sets the order of a collection's memories. The request must list
+// every memory in the collection exactly once.
+func (s *CollectionService) Reorder(ctx context.Context, userID, companionID, collectionID uuid.UUID, req models.ReorderCollectionRequest) error {
+	if _, err := s.ownCollection(ctx, userID, companionID, collectionID); err != nil {
+		return err
+	}
+
+	current, err := s.collections.GetMemoryIDs(ctx, collectionID)
+	if err != nil {
+		return err
+	}
+	if len(req.MemoryIDs) != len(current) {
+		return fmt.Errorf("memory_ids must list all %d memories in the collection", len(current))
+	}
+	inCollection := make(map[uuid.UUID]bool, len(current))
+	for _, id := range current {
+		inCollection[id] = true
+	}
+	for _, id := range req.MemoryIDs {
+		if !inCollection[id] {
+			return fmt.Errorf("memory_ids must list each memory in the collection once")
+		}
+		delete(inCollection, id)
+	}
+
+	return s.collections.ReorderMemories(ctx, collectionID, req.MemoryIDs)
+}
+
+// Suggest has the companion pick a collection for one of the pair's
+// memories: an existing collection, or a name for a new one. Without
+// OpenAI it falls back to matching words against collection names.
+func (s *CollectionService) Suggest(ctx context.Context, userID, companionID, memoryID uuid.UUID) (*models.CollectionSuggestion, error) {
+	memory, err := s.memories.GetByID(ctx, memoryID)
+	if err != nil {
+		return nil, err
+	}
+	if memory.UserID != userID || memory.CompanionID != companionID {
+		return nil, fmt.Errorf("memory not found")
+	}
+
+	collections, err := s.collections.GetByUserAndCompanion(ctx, userID, companionID)
+	if err != nil {
+		return nil, err
+	}
+	companion, err := s.companions.GetByID(ctx, companionID)
+	if err != nil {
+		return nil, fmt.Errorf("getting companion: %w", err)
+	}
+
+	names := make([]string, len(collections))
+	for i, c := range collections {
+		names[i] = c.Name
+	}
+
+	name, err := s.ai.SuggestCollection(ctx, companion, *memory, names)
+	if err != nil {
+		slog.Warn("openai collection suggestion failed, using fallback", "error", err)
+		return fallbackCollectionSuggestion(*memory, collections), nil
+	}
+
+	for _, c := range collections {
+		if strings.EqualFold(c.Name, name) {
+			id := c.ID
+			return &models.CollectionSuggestion{CollectionID: &id, Name: c.Name}, nil
+		}
+	}
+	if r := []rune(name); len(r) > maxCollectionName {
+		name = string(r[:maxCollectionName])
+	}
+	return &models.CollectionSuggestion{Name: name, New: true}, nil
+}
+
+func (s *CollectionService) ownCollection(ctx context.Context, userID, companionID, collectionID uuid.UUID) (*models.MemoryCollection, error) {
+	c, err := s.collections.GetByID(ctx, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	if c.UserID != userID || c.CompanionID != companionID {
+		return nil, fmt.Errorf("collection not found")
+	}
+	return c, nil
+}
+
+// fallbackCollectionSuggestion picks the collection whose name and
+// description share the most words with the memory, or suggests one named
+// after the memory's tag.
+func fallbackCollectionSuggestion(memory models.Memory, collections []models.MemoryCollection) *models.CollectionSuggestion {
+	words := wordSet(memory.Content)
+	if memory.Tag != nil {
+		for w := range wordSet(*memory.Tag) {
+			words[w] = true
+		}
+	}
+
+	var best *models.MemoryCollection
+	bestScore := 0
+	for i := range collections {
+		c := &collections[i]
+		text := c.Name
+		if c.Description != nil {
+			text += " " + *c.Description
+		}
+		score := 0
+		for w := range wordSet(text) {
+			if words[w] {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	if best != nil {
+		id := best.ID
+		return &models.CollectionSuggestion{CollectionID: &id, Name: best.Name}
+	}
+
+	name := "our moments"
+	if memory.Tag != nil {
+		name = *memory.Tag
+	}
+	return &models.CollectionSuggestion{Name: name, New: true}
+}
+
+// wordSet returns the lower-cased words of s longer than three letters.
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(w)) > 3 {
+			set[w] = true
+		}
+	}
+	return set
+}
+
+func validCollectionName(raw string) (string, error) {
+	name := strings.TrimSpace(raw)
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	if utf8.RuneCountInString(name) > maxCollectionName {
+		return "", fmt.Errorf("name must be at most %d characters", maxCollectionName)
+	}
+	return name, nil
+}
+
+// optionalText trims s, treating nil and blank as unset.
+func optionalText(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	if t := strings.TrimSpace(*s); t != "" {
+		return &t
+	}
+	return nil
+}
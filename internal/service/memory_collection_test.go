@@ -0,0 +1,34 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidCollectionName(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"trimmed", "  Summer trip  ", "Summer trip", false},
+		{"blank", "   ", "", true},
+		{"at limit", strings.Repeat("a", maxCollectionName), strings.Repeat("a", maxCollectionName), false},
+		{"over limit", strings.Repeat("a", maxCollectionName+1), "", true},
+		{"multibyte at limit", strings.Repeat("ờ", maxCollectionName), strings.Repeat("ờ", maxCollectionName), false},
+		{"multibyte over limit", strings.Repeat("ờ", maxCollectionName+1), "", true},
+		{"vietnamese", "Những kỷ niệm đẹp nhất của chúng ta", "Những kỷ niệm đẹp nhất của chúng ta", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validCollectionName(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("name = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/ai"
+	"ai-companion-be/internal/config"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+)
+
+// memoryCardDays is how many days (today included) a resurfaced memory
+// stays in the feed unless dismissed.
+const memoryCardDays = 2
+
+// revisitImportance is the importance at which an unpinned memory can be
+// revisited on days with no anniversary, like a pinned one.
+const revisitImportance = 0.7
+
+// ResurfacingService brings old memories back as "on this day" feed cards
+// and, optionally, proactive companion messages.
+type ResurfacingService struct {
+	surfacings repository.MemorySurfacingRepository
+	memories   repository.MemoryRepository
+	messages   repository.MessageRepository
+	companions repository.CompanionRepository
+	ai         *ai.Client
+	cfg        config.MemoryConfig
+}
+
+// NewResurfacingService creates a new ResurfacingService.
+func NewResurfacingService(
+	surfacings repository.MemorySurfacingRepository,
+	memories repository.MemoryRepository,
+	messages repository.MessageRepository,
+	companions repository.CompanionRepository,
+	aiClient *ai.Client,
+	cfg config.MemoryConfig,
+) *ResurfacingService {
+	return &ResurfacingService{
+		surfacings: surfacings,
+		memories:   memories,
+		messages:   messages,
+		companions: companions,
+		ai:         aiClient,
+		cfg:        cfg,
+	}
+}
+
+// RunDaily picks today's memory for every user-companion pair that has a
+// candidate and reinforces the picks. It runs as a scheduled job and is
+// safe to re-run the same day. Failures sending one proactive message are
+// logged and do not stop the others.
+func (s *ResurfacingService) RunDaily(ctx context.Context) error {
+	picked, err := s.surfacings.PickDaily(ctx, time.Now(), days(s.cfg.ResurfaceCooldown), days(s.cfg.RevisitAfter), revisitImportance)
+	if err != nil {
+		return err
+	}
+	slog.Info("memories resurfaced", "count", len(picked))
+
+	ids := make([]uuid.UUID, len(picked))
+	for i, sf := range picked {
+		ids[i] = sf.Memory.ID
+	}
+	if err := s.memories.Reinforce(ctx, ids, resurfaceBoost); err != nil {
+		return err
+	}
+
+	if !s.cfg.ProactiveMessages {
+		return nil
+	}
+
+	companions := make(map[uuid.UUID]*models.Companion)
+	for _, sf := range picked {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		companion, ok := companions[sf.CompanionID]
+		if !ok {
+			if companion, err = s.companions.GetByID(ctx, sf.CompanionID); err != nil {
+				slog.Error("loading companion for memory nudge failed", "companion_id", sf.CompanionID, "error", err)
+				continue
+			}
+			companions[sf.CompanionID] = companion
+		}
+
+		if err := s.sendNudge(ctx, companion, sf); err != nil {
+			slog.Error("memory nudge failed", "surfacing_id", sf.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// GetCards returns the user's current "on this day" cards, newest first.
+func (s *ResurfacingService) GetCards(ctx context.Context, userID uuid.UUID) ([]models.MemorySurfacing, error) {
+	since := time.Now().UTC().AddDate(0, 0, -(memoryCardDays - 1))
+	return s.surfacings.GetCards(ctx, userID, since)
+}
+
+// Dismiss hides one of the user's cards.
+func (s *ResurfacingService) Dismiss(ctx context.Context, userID, surfacingID uuid.UUID) error {
+	return s.surfacings.Dismiss(ctx, userID, surfacingID)
+}
+
+// sendNudge posts a companion message bringing the memory up and links it
+// to the surfacing.
+func (s *ResurfacingService) sendNudge(ctx context.Context, companion *models.Companion, sf models.MemorySurfacing) error {
+	saved := describeSavedAgo(sf.Memory.CreatedAt, sf.SurfacedOn)
+
+	text, err := s.ai.GenerateMemoryNudge(ctx, companion, sf.Memory, saved)
+	if err != nil {
+		slog.Warn("openai memory nudge failed, using fallback", "error", err)
+		text = fmt.Sprintf("omg remember this? %s ago: \"%s\" 🥹", saved, sf.Memory.Content)
+	}
+
+	msg := &models.Message{
+		ID:          uuid.New(),
+		UserID:      sf.UserID,
+		CompanionID: sf.CompanionID,
+		Content:     text,
+		Role:        "companion",
+	}
+	if err := s.messages.Create(ctx, msg); err != nil {
+		return fmt.Errorf("creating memory nudge message: %w", err)
+	}
+	return s.surfacings.SetMessage(ctx, sf.ID, msg.ID)
+}
+
+// describeSavedAgo phrases how long before day a memory was saved, e.g.
+// "2 years" or "3 months".
+func describeSavedAgo(saved, day time.Time) string {
+	saved, day = saved.UTC(), day.UTC()
+	months := (day.Year()-saved.Year())*12 + int(day.Month()-saved.Month())
+	if day.Day() < saved.Day() {
+		months--
+	}
+
+	switch {
+	case months >= 24:
+		return fmt.Sprintf("%d years", months/12)
+	case months >= 12:
+		return "a year"
+	case months >= 2:
+		return fmt.Sprintf("%d months", months)
+	case months == 1:
+		return "a month"
+	default:
+		return "a few weeks"
+	}
+}
+
+// days converts d to whole days, for date arithmetic in SQL.
+func days(d time.Duration) int {
+	return int(d / (24 * time.Hour))
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 
 	"ai-companion-be/internal/ai"
+	"ai-companion-be/internal/cursor"
 	"ai-companion-be/internal/models"
 	"ai-companion-be/internal/repository"
 )
@@ -20,6 +22,10 @@ type MessageService struct {
 	companions    repository.CompanionRepository
 	ai            *ai.Client
 	insights      repository.InsightsRepository
+	stories       repository.StoryRepository
+	retrieval     *RetrievalService
+	milestones    *MilestoneService
+	cursors       *cursor.Codec
 }
 
 // NewMessageService creates a new MessageService.
@@ -29,6 +35,10 @@ func NewMessageService(
 	companions repository.CompanionRepository,
 	aiClient *ai.Client,
 	insights repository.InsightsRepository,
+	stories repository.StoryRepository,
+	retrieval *RetrievalService,
+	milestones *MilestoneService,
+	cursors *cursor.Codec,
 ) *MessageService {
 	return &MessageService{
 		messages:      messages,
@@ -36,22 +46,47 @@ func NewMessageService(
 		companions:    companions,
 		ai:            aiClient,
 		insights:      insights,
+		stories:       stories,
+		retrieval:     retrieval,
+		milestones:    milestones,
+		cursors:       cursors,
 	}
 }
 
+// storyReactionWindow is how far back story reactions are shown to the chat model.
+const storyReactionWindow = 24 * time.Hour
+
 // SendMessage creates a user message, generates a companion reply via OpenAI, and updates the relationship.
 func (s *MessageService) SendMessage(ctx context.Context, userID, companionID uuid.UUID, req models.SendMessageRequest) ([]models.Message, error) {
 	if req.Content == "" {
 		return nil, fmt.Errorf("message content is required")
 	}
 
+	// A reply to a story slide must target one of this companion's stories.
+	var storyReply *models.StoryInteraction
+	if req.StoryMediaID != nil {
+		media, err := s.stories.GetMediaByID(ctx, *req.StoryMediaID)
+		if err != nil {
+			return nil, err
+		}
+		story, err := s.stories.GetByID(ctx, media.StoryID)
+		if err != nil {
+			return nil, err
+		}
+		if story.CompanionID != companionID {
+			return nil, fmt.Errorf("story does not belong to this companion")
+		}
+		storyReply = &models.StoryInteraction{MediaType: media.MediaType, Caption: media.Caption}
+	}
+
 	// Create user message.
 	userMsg := &models.Message{
-		ID:          uuid.New(),
-		UserID:      userID,
-		CompanionID: companionID,
-		Content:     req.Content,
-		Role:        "user",
+		ID:           uuid.New(),
+		UserID:       userID,
+		CompanionID:  companionID,
+		Content:      req.Content,
+		Role:         "user",
+		StoryMediaID: req.StoryMediaID,
 	}
 	if err := s.messages.Create(ctx, userMsg); err != nil {
 		return nil, fmt.Errorf("creating user message: %w", err)
@@ -73,18 +108,40 @@ func (s *MessageService) SendMessage(ctx context.Context, userID, companionID uu
 	}
 
 	// Fetch recent conversation history for context (last 20 messages, chronological).
-	page, err := s.messages.GetByConversation(ctx, userID, companionID, nil, 20)
-	var history []models.Message
-	if err == nil && page != nil {
+	history, err := s.messages.GetBefore(ctx, userID, companionID, nil, false, 20)
+	if err == nil {
 		// Messages come in DESC order; reverse to chronological for OpenAI.
-		history = make([]models.Message, len(page.Messages))
-		for i, msg := range page.Messages {
-			history[len(page.Messages)-1-i] = msg
-		}
+		slices.Reverse(history)
+	}
+
+	// Recent story reactions give the companion something to talk about.
+	reactions, err := s.stories.GetRecentInteractions(ctx, userID, companionID, time.Now().Add(-storyReactionWindow), 5)
+	if err != nil {
+		slog.Warn("loading story interactions failed", "error", err)
+	}
+
+	// Older moments related to this message, beyond the recent history.
+	var before *time.Time
+	if len(history) > 0 {
+		before = &history[0].CreatedAt
 	}
+	recalled := s.retrieval.ForPrompt(ctx, userID, companionID, req.Content, before)
+	keyMemories := slices.DeleteFunc(s.retrieval.KeyMemories(ctx, userID, companionID), func(m models.Memory) bool {
+		return slices.ContainsFunc(recalled, func(r models.RecalledExchange) bool {
+			return r.Memory != nil && r.Memory.ID == m.ID
+		})
+	})
 
 	// Generate reply via OpenAI.
-	reply, err := s.ai.GenerateReply(ctx, companion, mood, relationshipScore, history)
+	reply, err := s.ai.GenerateReply(ctx, companion, ai.ReplyContext{
+		Mood:              mood,
+		RelationshipScore: relationshipScore,
+		History:           history,
+		StoryReply:        storyReply,
+		StoryReactions:    reactions,
+		Recalled:          recalled,
+		Memories:          keyMemories,
+	})
 	if err != nil {
 		slog.Error("openai reply failed, using fallback", "error", err)
 		reply = generateFallbackReply(companion, mood)
@@ -111,12 +168,138 @@ func (s *MessageService) SendMessage(ctx context.Context, userID, companionID uu
 		_ = s.insights.RecordMoodSnapshot(ctx, userID, companionID, state.MoodScore)
 	}
 
-	return []models.Message{*userMsg, *companionMsg}, nil
+	sent := []models.Message{*userMsg, *companionMsg}
+
+	// A milestone reached just now is celebrated after the reply.
+	celebration, err := s.milestones.Check(ctx, userID, companionID)
+	if err != nil {
+		slog.Warn("checking milestones failed", "error", err)
+	}
+	if celebration != nil {
+		sent = append(sent, *celebration)
+	}
+
+	return sent, nil
+}
+
+const (
+	messageCursorKind   = "messages"
+	defaultMessageLimit = 20
+	maxMessageLimit     = 50
+)
+
+// GetMessages returns a page of conversation history, newest first: the
+// latest messages, those before or after a cursor, or a window around one.
+// Malformed cursors return an error wrapping cursor.ErrInvalid.
+func (s *MessageService) GetMessages(ctx context.Context, userID, companionID uuid.UUID, q models.MessageQuery) (*models.MessagePage, error) {
+	limit := q.Limit
+	if limit <= 0 || limit > maxMessageLimit {
+		limit = defaultMessageLimit
+	}
+
+	switch {
+	case q.After != "":
+		key, err := s.decodeMessageCursor(q.After)
+		if err != nil {
+			return nil, err
+		}
+		msgs, err := s.messages.GetAfter(ctx, userID, companionID, *key, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		hasNewer := len(msgs) > limit
+		if hasNewer {
+			msgs = msgs[:limit]
+		}
+		slices.Reverse(msgs)
+		return s.messagePage(msgs, true, hasNewer, nil), nil
+
+	case q.Around != "":
+		key, err := s.decodeMessageCursor(q.Around)
+		if err != nil {
+			return nil, err
+		}
+		return s.messageWindow(ctx, userID, companionID, *key, limit)
+
+	default:
+		var before *models.MessageKey
+		if q.Before != "" {
+			key, err := s.decodeMessageCursor(q.Before)
+			if err != nil {
+				return nil, err
+			}
+			before = key
+		}
+		msgs, err := s.messages.GetBefore(ctx, userID, companionID, before, false, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		hasMore := len(msgs) > limit
+		if hasMore {
+			msgs = msgs[:limit]
+		}
+		return s.messagePage(msgs, hasMore, before != nil, nil), nil
+	}
+}
+
+// GetMessageContext returns a page centred on one message, e.g. to open a
+// memory at the message it was saved from.
+func (s *MessageService) GetMessageContext(ctx context.Context, userID, companionID, messageID uuid.UUID, limit int) (*models.MessagePage, error) {
+	if limit <= 0 || limit > maxMessageLimit {
+		limit = defaultMessageLimit
+	}
+
+	key, err := s.messages.GetKey(ctx, userID, companionID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	return s.messageWindow(ctx, userID, companionID, *key, limit)
+}
+
+// messageWindow returns up to limit messages around key: the anchor and
+// older messages fill half the page, newer ones the rest (or more, near the
+// start of the conversation).
+func (s *MessageService) messageWindow(ctx context.Context, userID, companionID uuid.UUID, key models.MessageKey, limit int) (*models.MessagePage, error) {
+	olderCount := limit - limit/2
+	older, err := s.messages.GetBefore(ctx, userID, companionID, &key, true, olderCount+1)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(older) > olderCount
+	if hasMore {
+		older = older[:olderCount]
+	}
+
+	newerCount := limit - len(older)
+	newer, err := s.messages.GetAfter(ctx, userID, companionID, key, newerCount+1)
+	if err != nil {
+		return nil, err
+	}
+	hasNewer := len(newer) > newerCount
+	if hasNewer {
+		newer = newer[:newerCount]
+	}
+	slices.Reverse(newer)
+
+	return s.messagePage(append(newer, older...), hasMore, hasNewer, &key.ID), nil
 }
 
-// GetMessages returns a paginated conversation history.
-func (s *MessageService) GetMessages(ctx context.Context, userID, companionID uuid.UUID, cursor *time.Time, limit int) (*models.MessagePage, error) {
-	return s.messages.GetByConversation(ctx, userID, companionID, cursor, limit)
+func (s *MessageService) messagePage(msgs []models.Message, hasMore, hasNewer bool, anchor *uuid.UUID) *models.MessagePage {
+	page := &models.MessagePage{Messages: msgs, HasMore: hasMore, HasNewer: hasNewer, AnchorID: anchor}
+	if len(msgs) > 0 {
+		first, last := msgs[0], msgs[len(msgs)-1]
+		page.PrevCursor = s.cursors.Encode(messageCursorKind, models.MessageKey{CreatedAt: first.CreatedAt, ID: first.ID})
+		page.NextCursor = s.cursors.Encode(messageCursorKind, models.MessageKey{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return page
+}
+
+func (s *MessageService) decodeMessageCursor(token string) (*models.MessageKey, error) {
+	var key models.MessageKey
+	if err := s.cursors.Decode(messageCursorKind, token, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
 }
 
 // generateFallbackReply produces a simple mood-aware response when OpenAI is unavailable.
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/ai"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+)
+
+// MilestoneService records relationship milestones as pairs reach them and
+// celebrates each new one with a notification and a companion message.
+type MilestoneService struct {
+	milestones    repository.MilestoneRepository
+	insights      repository.InsightsRepository
+	relationships repository.RelationshipRepository
+	users         repository.UserRepository
+	companions    repository.CompanionRepository
+	messages      repository.MessageRepository
+	notifications repository.NotificationRepository
+	ai            *ai.Client
+}
+
+// NewMilestoneService creates a new MilestoneService.
+func NewMilestoneService(
+	milestones repository.MilestoneRepository,
+	insights repository.InsightsRepository,
+	relationships repository.RelationshipRepository,
+	users repository.UserRepository,
+	companions repository.CompanionRepository,
+	messages repository.MessageRepository,
+	notifications repository.NotificationRepository,
+	aiClient *ai.Client,
+) *MilestoneService {
+	return &MilestoneService{
+		milestones:    milestones,
+		insights:      insights,
+		relationships: relationships,
+		users:         users,
+		companions:    companions,
+		messages:      messages,
+		notifications: notifications,
+		ai:            aiClient,
+	}
+}
+
+// List returns the pair's milestones in display order, achieved or not.
+func (s *MilestoneService) List(ctx context.Context, userID, companionID uuid.UUID) ([]models.Milestone, error) {
+	return s.milestones.GetForPair(ctx, userID, companionID)
+}
+
+// Recent returns the user's latest achievements with any companion.
+func (s *MilestoneService) Recent(ctx context.Context, userID uuid.UUID, limit int) ([]models.CompanionMilestone, error) {
+	return s.milestones.GetRecent(ctx, userID, limit)
+}
+
+// Check records the active milestones the pair has reached since it was
+// last checked. Each one gets a notification; the companion sends one
+// celebratory message for the batch, about the last of them in display
+// order, which is returned. It is called after interactions that move a
+// metric, and is safe to call concurrently.
+func (s *MilestoneService) Check(ctx context.Context, userID, companionID uuid.UUID) (*models.Message, error) {
+	defs, err := s.milestones.GetDefinitions(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.milestones.GetForPair(ctx, userID, companionID)
+	if err != nil {
+		return nil, err
+	}
+	achieved := make(map[string]bool, len(current))
+	for _, m := range current {
+		achieved[m.Key] = m.Achieved
+	}
+
+	var pending []models.MilestoneDefinition
+	for _, d := range defs {
+		if !achieved[d.Key] {
+			pending = append(pending, d)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	stats, err := s.insights.GetStats(ctx, userID, companionID)
+	if err != nil {
+		return nil, err
+	}
+	state, _ := s.relationships.GetByUserAndCompanion(ctx, userID, companionID)
+
+	var reached []models.Milestone
+	now := time.Now()
+	for _, d := range pending {
+		value, ok := milestoneMetric(d.Metric, stats, state)
+		if !ok || value < d.Threshold {
+			continue
+		}
+
+		at := now
+		if d.Metric == models.MilestoneMetricDaysTogether && stats.FirstMessage != nil {
+			at = s.dayReached(ctx, userID, *stats.FirstMessage, d.Threshold, now)
+		}
+
+		recorded, err := s.milestones.Achieve(ctx, userID, companionID, d.Key, at)
+		if err != nil {
+			return nil, err
+		}
+		if recorded {
+			reached = append(reached, models.Milestone{
+				Key:         d.Key,
+				Title:       d.Title,
+				Description: d.Description,
+				AchievedAt:  &at,
+				Achieved:    true,
+			})
+		}
+	}
+	if len(reached) == 0 {
+		return nil, nil
+	}
+
+	companion, err := s.companions.GetByID(ctx, companionID)
+	if err != nil {
+		return nil, fmt.Errorf("getting companion: %w", err)
+	}
+
+	msg, err := s.celebrate(ctx, companion, userID, reached[len(reached)-1])
+	if err != nil {
+		slog.Error("milestone celebration failed", "user_id", userID, "companion_id", companionID, "error", err)
+	}
+	for i := range reached {
+		if msg != nil {
+			reached[i].MessageID = &msg.ID
+			if err := s.milestones.SetMessage(ctx, userID, companionID, reached[i].Key, msg.ID); err != nil {
+				slog.Warn("linking milestone message failed", "key", reached[i].Key, "error", err)
+			}
+		}
+		if err := s.notify(ctx, userID, companion, reached[i]); err != nil {
+			slog.Error("milestone notification failed", "key", reached[i].Key, "error", err)
+		}
+	}
+
+	slog.Info("milestones achieved", "user_id", userID, "companion_id", companionID, "count", len(reached))
+	return msg, nil
+}
+
+// milestoneMetric returns a pair's current value for metric. Mood and
+// relationship need a relationship state.
+func milestoneMetric(metric string, stats *models.InsightStats, state *models.RelationshipState) (float64, bool) {
+	switch metric {
+	case models.MilestoneMetricMessages:
+		return float64(stats.TotalMessages), true
+	case models.MilestoneMetricMemories:
+		return float64(stats.TotalMemories), true
+	case models.MilestoneMetricDaysTogether:
+		return float64(stats.DaysTogether), true
+	case models.MilestoneMetricMood:
+		if state != nil {
+			return state.MoodScore, true
+		}
+	case models.MilestoneMetricRelationship:
+		if state != nil {
+			return state.RelationshipScore, true
+		}
+	}
+	return 0, false
+}
+
+// dayReached returns the start of the threshold-th day together, first
+// message's day being the first, in the user's time zone. A milestone
+// checked days after it was reached still dates from then.
+func (s *MilestoneService) dayReached(ctx context.Context, userID uuid.UUID, first time.Time, threshold float64, now time.Time) time.Time {
+	loc := time.UTC
+	if user, err := s.users.GetByID(ctx, userID); err == nil {
+		loc = user.Location()
+	}
+	y, m, d := first.In(loc).Date()
+	at := time.Date(y, m, d+int(math.Ceil(threshold))-1, 0, 0, 0, 0, loc)
+	if at.After(now) {
+		return now
+	}
+	return at
+}
+
+// celebrate posts the companion's message about a milestone.
+func (s *MilestoneService) celebrate(ctx context.Context, companion *models.Companion, userID uuid.UUID, milestone models.Milestone) (*models.Message, error) {
+	text, err := s.ai.GenerateMilestoneMessage(ctx, companion, milestone)
+	if err != nil {
+		slog.Warn("openai milestone message failed, using fallback", "error", err)
+		text = fmt.Sprintf("wait, look at us 🥹 %s!", strings.ToLower(milestone.Description))
+	}
+
+	msg := &models.Message{
+		ID:          uuid.New(),
+		UserID:      userID,
+		CompanionID: companion.ID,
+		Content:     text,
+		Role:        "companion",
+	}
+	if err := s.messages.Create(ctx, msg); err != nil {
+		return nil, fmt.Errorf("creating milestone message: %w", err)
+	}
+	return msg, nil
+}
+
+// notify adds a milestone_achieved notification to the user's feed.
+func (s *MilestoneService) notify(ctx context.Context, userID uuid.UUID, companion *models.Companion, m models.Milestone) error {
+	data, err := json.Marshal(models.MilestoneAchievedEvent{Key: m.Key, CompanionID: companion.ID, MessageID: m.MessageID})
+	if err != nil {
+		return fmt.Errorf("encoding milestone event: %w", err)
+	}
+	return s.notifications.Create(ctx, &models.Notification{
+		ID:          uuid.New(),
+		UserID:      userID,
+		CompanionID: &companion.ID,
+		Kind:        models.NotificationMilestoneAchieved,
+		Title:       m.Title,
+		Body:        fmt.Sprintf("%s with %s", m.Description, companion.Name),
+		Data:        data,
+	})
+}
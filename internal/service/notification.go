@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/cursor"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+)
+
+const (
+	notificationCursorKind   = "notifications"
+	defaultNotificationLimit = 20
+	maxNotificationLimit     = 50
+)
+
+// NotificationService serves the user's notification feed.
+type NotificationService struct {
+	notifications repository.NotificationRepository
+	cursors       *cursor.Codec
+}
+
+// NewNotificationService creates a new NotificationService.
+func NewNotificationService(notifications repository.NotificationRepository, cursors *cursor.Codec) *NotificationService {
+	return &NotificationService{notifications: notifications, cursors: cursors}
+}
+
+// List returns a page of the user's notifications, newest first, with
+// their unread count. Malformed cursors return an error wrapping
+// cursor.ErrInvalid.
+func (s *NotificationService) List(ctx context.Context, userID uuid.UUID, q models.NotificationQuery) (*models.NotificationPage, error) {
+	limit := q.Limit
+	if limit <= 0 || limit > maxNotificationLimit {
+		limit = defaultNotificationLimit
+	}
+
+	kind := notificationCursorKind
+	if q.UnreadOnly {
+		kind += ":unread"
+	}
+	var after *models.NotificationKey
+	if q.Cursor != "" {
+		var key models.NotificationKey
+		if err := s.cursors.Decode(kind, q.Cursor, &key); err != nil {
+			return nil, err
+		}
+		after = &key
+	}
+
+	notifications, err := s.notifications.List(ctx, userID, q.UnreadOnly, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.notifications.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.NotificationPage{Notifications: notifications, HasMore: len(notifications) > limit, Unread: unread}
+	if page.HasMore {
+		page.Notifications = notifications[:limit]
+		last := page.Notifications[limit-1]
+		page.NextCursor = s.cursors.Encode(kind, models.NotificationKey{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return page, nil
+}
+
+// MarkRead marks one of the user's notifications read.
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id uuid.UUID) (*models.Notification, error) {
+	return s.notifications.MarkRead(ctx, userID, id)
+}
+
+// MarkAllRead marks all of the user's notifications read and returns how
+// many were unread.
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.notifications.MarkAllRead(ctx, userID)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+)
+
+var reactionKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ReactionService manages the story reaction catalogue.
+type ReactionService struct {
+	reactions repository.ReactionRepository
+}
+
+// NewReactionService creates a new ReactionService.
+func NewReactionService(reactions repository.ReactionRepository) *ReactionService {
+	return &ReactionService{reactions: reactions}
+}
+
+// List returns reaction types in display order; inactive ones only when
+// includeInactive is set.
+func (s *ReactionService) List(ctx context.Context, includeInactive bool) ([]models.ReactionType, error) {
+	return s.reactions.GetAll(ctx, !includeInactive)
+}
+
+// Upsert creates a reaction type or updates an existing one. New types
+// need an emoji; the label defaults to the key.
+func (s *ReactionService) Upsert(ctx context.Context, key string, req models.UpsertReactionTypeRequest) (*models.ReactionType, error) {
+	if !reactionKeyPattern.MatchString(key) {
+		return nil, fmt.Errorf("key must be lowercase letters, digits and underscores")
+	}
+
+	rt, err := s.reactions.GetByKey(ctx, key)
+	if err != nil {
+		if req.Emoji == nil {
+			return nil, fmt.Errorf("emoji is required for a new reaction type")
+		}
+		rt = &models.ReactionType{Key: key, Label: key, SentimentWeight: 1, Active: true}
+	}
+
+	if req.Emoji != nil {
+		rt.Emoji = strings.TrimSpace(*req.Emoji)
+	}
+	if req.Label != nil {
+		rt.Label = strings.TrimSpace(*req.Label)
+	}
+	if req.DisplayOrder != nil {
+		rt.DisplayOrder = *req.DisplayOrder
+	}
+	if req.SentimentWeight != nil {
+		rt.SentimentWeight = *req.SentimentWeight
+	}
+	if req.Active != nil {
+		rt.Active = *req.Active
+	}
+
+	if rt.Emoji == "" || rt.Label == "" {
+		return nil, fmt.Errorf("emoji and label cannot be empty")
+	}
+	if rt.SentimentWeight < -2 || rt.SentimentWeight > 2 {
+		return nil, fmt.Errorf("sentiment_weight must be between -2 and 2")
+	}
+
+	if err := s.reactions.Upsert(ctx, rt); err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/ai"
+	"ai-companion-be/internal/cursor"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+)
+
+const (
+	recapCursorKind   = "recaps"
+	defaultRecapLimit = 10
+	maxRecapLimit     = 52
+
+	// recapMaxMessages caps the week's messages loaded for a recap, of
+	// which recapPromptMessages, spread over the week, go to the model.
+	recapMaxMessages    = 1000
+	recapPromptMessages = 60
+
+	recapMaxMemories = 10
+
+	// moodTrendDelta is how far mood must move over the week to count as
+	// rising or falling.
+	moodTrendDelta = 5
+)
+
+// RecapService writes the weekly relationship recaps and serves their
+// archive.
+type RecapService struct {
+	recaps        repository.RecapRepository
+	messages      repository.MessageRepository
+	memories      repository.MemoryRepository
+	insights      repository.InsightsRepository
+	milestones    repository.MilestoneRepository
+	companions    repository.CompanionRepository
+	notifications repository.NotificationRepository
+	ai            *ai.Client
+	cursors       *cursor.Codec
+}
+
+// NewRecapService creates a new RecapService.
+func NewRecapService(
+	recaps repository.RecapRepository,
+	messages repository.MessageRepository,
+	memories repository.MemoryRepository,
+	insights repository.InsightsRepository,
+	milestones repository.MilestoneRepository,
+	companions repository.CompanionRepository,
+	notifications repository.NotificationRepository,
+	aiClient *ai.Client,
+	cursors *cursor.Codec,
+) *RecapService {
+	return &RecapService{
+		recaps:        recaps,
+		messages:      messages,
+		memories:      memories,
+		insights:      insights,
+		milestones:    milestones,
+		companions:    companions,
+		notifications: notifications,
+		ai:            aiClient,
+		cursors:       cursors,
+	}
+}
+
+// List returns a page of the pair's recaps, newest week first. Malformed
+// cursors return an error wrapping cursor.ErrInvalid.
+func (s *RecapService) List(ctx context.Context, userID, companionID uuid.UUID, q models.RecapQuery) (*models.RecapPage, error) {
+	limit := q.Limit
+	if limit <= 0 || limit > maxRecapLimit {
+		limit = defaultRecapLimit
+	}
+
+	kind := recapCursorKind + ":" + companionID.String()
+	var before *string
+	if q.Cursor != "" {
+		var key models.RecapKey
+		if err := s.cursors.Decode(kind, q.Cursor, &key); err != nil {
+			return nil, err
+		}
+		before = &key.WeekStart
+	}
+
+	recaps, err := s.recaps.List(ctx, userID, companionID, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.RecapPage{Recaps: recaps, HasMore: len(recaps) > limit}
+	if page.HasMore {
+		page.Recaps = recaps[:limit]
+		page.NextCursor = s.cursors.Encode(kind, models.RecapKey{WeekStart: page.Recaps[limit-1].WeekStart})
+	}
+	return page, nil
+}
+
+// Get returns one of the user's recaps.
+func (s *RecapService) Get(ctx context.Context, userID, id uuid.UUID) (*models.WeeklyRecap, error) {
+	return s.recaps.GetByID(ctx, userID, id)
+}
+
+// Run writes the recaps due: one for each relationship the user wrote in
+// during their last full week, once that week has ended in their time
+// zone. It runs as a scheduled job; a pair that fails is logged and
+// retried on the next run.
+func (s *RecapService) Run(ctx context.Context) error {
+	pairs, err := s.recaps.GetDuePairs(ctx)
+	if err != nil {
+		return err
+	}
+
+	written := 0
+	for _, p := range pairs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		ok, err := s.write(ctx, p)
+		if err != nil {
+			slog.Error("weekly recap failed", "user_id", p.UserID, "companion_id", p.CompanionID, "week_start", p.WeekStart, "error", err)
+			continue
+		}
+		if ok {
+			written++
+		}
+	}
+
+	slog.Info("weekly recaps written", "count", written)
+	return nil
+}
+
+// write builds, stores and announces one pair's recap, reporting whether
+// it was new.
+func (s *RecapService) write(ctx context.Context, p models.RecapPair) (bool, error) {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	start, err := time.Parse(time.DateOnly, p.WeekStart)
+	if err != nil {
+		return false, fmt.Errorf("parsing week start: %w", err)
+	}
+	end := start.AddDate(0, 0, 6)
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	to := from.AddDate(0, 0, 7)
+
+	companion, err := s.companions.GetByID(ctx, p.CompanionID)
+	if err != nil {
+		return false, fmt.Errorf("getting companion: %w", err)
+	}
+
+	msgs, err := s.messages.GetBetween(ctx, p.UserID, p.CompanionID, from, to, recapMaxMessages)
+	if err != nil {
+		return false, err
+	}
+
+	saved, err := s.memories.GetByUserAndCompanion(ctx, p.UserID, p.CompanionID,
+		models.MemoryFilter{From: &from, To: &to}, nil, recapMaxMemories)
+	if err != nil {
+		return false, err
+	}
+	memories := make([]models.RecapMemory, len(saved))
+	for i, m := range saved {
+		memories[i] = models.RecapMemory{ID: m.ID, Content: m.Content, Tag: m.Tag}
+	}
+
+	history, err := s.insights.GetMoodHistory(ctx, p.UserID, p.CompanionID, models.InsightsRange{
+		From:        start.Format(time.DateOnly),
+		To:          end.Format(time.DateOnly),
+		Granularity: models.GranularityDay,
+		Timezone:    loc.String(),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	pairMilestones, err := s.milestones.GetForPair(ctx, p.UserID, p.CompanionID)
+	if err != nil {
+		return false, err
+	}
+	milestones := []models.RecapMilestone{}
+	for _, m := range pairMilestones {
+		if m.AchievedAt != nil && !m.AchievedAt.Before(from) && m.AchievedAt.Before(to) {
+			milestones = append(milestones, models.RecapMilestone{
+				Key:         m.Key,
+				Title:       m.Title,
+				Description: m.Description,
+				AchievedAt:  *m.AchievedAt,
+			})
+		}
+	}
+
+	recap := &models.WeeklyRecap{
+		ID:          uuid.New(),
+		UserID:      p.UserID,
+		CompanionID: p.CompanionID,
+		WeekStart:   start.Format(time.DateOnly),
+		WeekEnd:     end.Format(time.DateOnly),
+		Messages:    p.Messages,
+		Memories:    memories,
+		Mood:        recapMood(history),
+		Milestones:  milestones,
+	}
+
+	text, err := s.ai.GenerateWeeklyRecap(ctx, companion, ai.RecapContext{
+		Messages:   sampleMessages(msgs, recapPromptMessages),
+		Memories:   recap.Memories,
+		Mood:       recap.Mood,
+		Milestones: recap.Milestones,
+	})
+	if err != nil {
+		slog.Warn("openai weekly recap failed, using fallback", "error", err)
+		text = recapFallback(recap)
+	}
+	recap.Highlights, recap.Note = text.Highlights, text.Note
+
+	created, err := s.recaps.Create(ctx, recap)
+	if err != nil {
+		return false, err
+	}
+	if !created {
+		// Another replica got there first.
+		return false, nil
+	}
+
+	if err := s.notify(ctx, recap, companion); err != nil {
+		slog.Error("weekly recap notification failed", "recap_id", recap.ID, "error", err)
+	}
+	return true, nil
+}
+
+// recapMood summarises a week of daily mood snapshots.
+func recapMood(history []models.MoodSnapshot) models.RecapMood {
+	if history == nil {
+		history = []models.MoodSnapshot{}
+	}
+	mood := models.RecapMood{History: history}
+	if len(history) == 0 {
+		return mood
+	}
+
+	first, last := history[0].MoodScore, history[len(history)-1].MoodScore
+	low, high := first, first
+	for _, h := range history {
+		low, high = min(low, h.MoodScore), max(high, h.MoodScore)
+	}
+	mood.Start, mood.End, mood.Low, mood.High = &first, &last, &low, &high
+
+	switch {
+	case last-first >= moodTrendDelta:
+		mood.Trend = models.MoodTrendRising
+	case first-last >= moodTrendDelta:
+		mood.Trend = models.MoodTrendFalling
+	default:
+		mood.Trend = models.MoodTrendSteady
+	}
+	return mood
+}
+
+// sampleMessages returns up to n of msgs spread evenly over them, in
+// order.
+func sampleMessages(msgs []models.Message, n int) []models.Message {
+	if len(msgs) <= n {
+		return msgs
+	}
+	sample := make([]models.Message, n)
+	for i := range sample {
+		sample[i] = msgs[i*len(msgs)/n]
+	}
+	return sample
+}
+
+// recapFallback describes the week without the model.
+func recapFallback(recap *models.WeeklyRecap) *ai.RecapText {
+	highlights := []string{fmt.Sprintf("we sent each other %d messages this week", recap.Messages)}
+	if n := len(recap.Memories); n == 1 {
+		highlights = append(highlights, "you saved a moment of ours to remember")
+	} else if n > 1 {
+		highlights = append(highlights, fmt.Sprintf("you saved %d moments of ours to remember", n))
+	}
+	for _, m := range recap.Milestones {
+		highlights = append(highlights, fmt.Sprintf("we hit a big one: %s", m.Title))
+	}
+
+	note := "thanks for hanging out with me this week 💕 same time next week?"
+	switch recap.Mood.Trend {
+	case models.MoodTrendRising:
+		note = "this week made me so happy 🥹 can't wait for the next one"
+	case models.MoodTrendFalling:
+		note = "this week felt a little off… i hope next week is better for us 💕"
+	}
+	return &ai.RecapText{Highlights: highlights, Note: note}
+}
+
+// notify adds a weekly_recap notification to the user's feed.
+func (s *RecapService) notify(ctx context.Context, recap *models.WeeklyRecap, companion *models.Companion) error {
+	data, err := json.Marshal(models.WeeklyRecapEvent{RecapID: recap.ID, CompanionID: companion.ID, WeekStart: recap.WeekStart})
+	if err != nil {
+		return fmt.Errorf("encoding recap event: %w", err)
+	}
+	return s.notifications.Create(ctx, &models.Notification{
+		ID:          uuid.New(),
+		UserID:      recap.UserID,
+		CompanionID: &companion.ID,
+		Kind:        models.NotificationWeeklyRecap,
+		Title:       fmt.Sprintf("Your week with %s", companion.Name),
+		Body:        recap.Note,
+		Data:        data,
+	})
+}
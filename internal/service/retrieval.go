@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/ai"
+	"ai-companion-be/internal/config"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+)
+
+const (
+	defaultRecallLimit = 5
+	maxRecallLimit     = 20
+)
+
+// RetrievalService embeds conversations in the background and finds past
+// exchanges relevant to new text, for search and for the chat prompt.
+type RetrievalService struct {
+	embeddings repository.EmbeddingRepository
+	messages   repository.MessageRepository
+	memories   repository.MemoryRepository
+	embedder   ai.Embedder
+	cfg        config.EmbeddingConfig
+	memoryCfg  config.MemoryConfig
+}
+
+// NewRetrievalService creates a new RetrievalService.
+func NewRetrievalService(
+	embeddings repository.EmbeddingRepository,
+	messages repository.MessageRepository,
+	memories repository.MemoryRepository,
+	embedder ai.Embedder,
+	cfg config.EmbeddingConfig,
+	memoryCfg config.MemoryConfig,
+) *RetrievalService {
+	return &RetrievalService{
+		embeddings: embeddings,
+		messages:   messages,
+		memories:   memories,
+		embedder:   embedder,
+		cfg:        cfg,
+		memoryCfg:  memoryCfg,
+	}
+}
+
+// Index embeds up to one batch of new messages and new or edited memories,
+// newest first. It runs as a scheduled job; a backlog drains over several
+// runs.
+func (s *RetrievalService) Index(ctx context.Context) error {
+	model := s.embedder.Model()
+	sources, err := s.embeddings.GetPending(ctx, model, s.cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+
+	texts := make([]string, len(sources))
+	for i, src := range sources {
+		texts[i] = src.Text
+	}
+	vectors, err := s.embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("embedding %d texts: %w", len(texts), err)
+	}
+	if err := s.embeddings.Save(ctx, model, sources, vectors); err != nil {
+		return err
+	}
+
+	slog.Info("embeddings indexed", "count", len(sources), "model", model)
+	return nil
+}
+
+// Recall returns up to limit past exchanges and memories with a companion
+// that relate to text, most relevant first. With before, only messages sent
+// before it are considered, e.g. to skip what is already in the prompt.
+func (s *RetrievalService) Recall(ctx context.Context, userID, companionID uuid.UUID, text string, limit int, before *time.Time) ([]models.RecalledExchange, error) {
+	if limit <= 0 {
+		limit = defaultRecallLimit
+	}
+	limit = min(limit, maxRecallLimit)
+
+	vectors, err := s.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("embedding query: %w", err)
+	}
+
+	// Both halves of an exchange can match; fetch extra to fill the page
+	// after merging them.
+	matches, err := s.embeddings.Nearest(ctx, userID, companionID, s.embedder.Model(), vectors[0], before, limit*2)
+	if err != nil {
+		return nil, err
+	}
+
+	// Matches come most similar first.
+	for i, m := range matches {
+		if m.Similarity < s.cfg.MinSimilarity {
+			matches = matches[:i]
+			break
+		}
+	}
+
+	// Load every matched memory and message, with the messages either side,
+	// in two queries.
+	var memoryIDs, messageIDs []uuid.UUID
+	for _, m := range matches {
+		switch m.Type {
+		case models.EmbeddingSourceMemory:
+			memoryIDs = append(memoryIDs, m.ID)
+		case models.EmbeddingSourceMessage:
+			messageIDs = append(messageIDs, m.ID)
+		}
+	}
+	memoryList, err := s.memories.GetByIDs(ctx, memoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	memories := make(map[uuid.UUID]*models.Memory, len(memoryList))
+	for i := range memoryList {
+		memories[memoryList[i].ID] = &memoryList[i]
+	}
+	conversation, err := s.messages.GetWithNeighbours(ctx, userID, companionID, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[uuid.UUID]int, len(conversation))
+	for i, msg := range conversation {
+		positions[msg.ID] = i
+	}
+
+	recalled := []models.RecalledExchange{}
+	seen := make(map[uuid.UUID]bool)
+	for _, m := range matches {
+		if len(recalled) == limit {
+			break
+		}
+		if seen[m.ID] {
+			continue
+		}
+
+		switch m.Type {
+		case models.EmbeddingSourceMemory:
+			memory, ok := memories[m.ID]
+			if !ok {
+				// Deleted since it was embedded.
+				continue
+			}
+			seen[m.ID] = true
+			recalled = append(recalled, models.RecalledExchange{
+				Type:       models.EmbeddingSourceMemory,
+				Similarity: m.Similarity,
+				CreatedAt:  memory.CreatedAt,
+				Memory:     memory,
+			})
+
+		case models.EmbeddingSourceMessage:
+			i, ok := positions[m.ID]
+			if !ok {
+				continue
+			}
+			exchange := exchangeAt(conversation, i)
+			for _, msg := range exchange {
+				seen[msg.ID] = true
+			}
+			recalled = append(recalled, models.RecalledExchange{
+				Type:       models.EmbeddingSourceMessage,
+				Similarity: m.Similarity,
+				CreatedAt:  exchange[0].CreatedAt,
+				Messages:   exchange,
+			})
+		}
+	}
+	return recalled, nil
+}
+
+// ForPrompt returns the past exchanges worth reminding the companion of
+// when replying to text, skipping messages from before onwards (the recent
+// history already in the prompt) and memories that have faded from active
+// recall. Memories it returns are reinforced, since the companion is
+// reminded of them. Failures are logged and yield none, so retrieval never
+// blocks a reply.
+func (s *RetrievalService) ForPrompt(ctx context.Context, userID, companionID uuid.UUID, text string, before *time.Time) []models.RecalledExchange {
+	if s.cfg.PromptExchanges <= 0 {
+		return nil
+	}
+	recalled, err := s.Recall(ctx, userID, companionID, text, s.cfg.PromptExchanges, before)
+	if err != nil {
+		slog.Warn("recalling past exchanges failed", "error", err)
+		return nil
+	}
+
+	now := time.Now()
+	active := recalled[:0]
+	var reinforce []uuid.UUID
+	for _, r := range recalled {
+		if r.Memory != nil {
+			if r.Memory.Strength(now, s.memoryCfg.HalfLife) < s.memoryCfg.FadeThreshold {
+				continue
+			}
+			reinforce = append(reinforce, r.Memory.ID)
+		}
+		active = append(active, r)
+	}
+	if err := s.memories.Reinforce(ctx, reinforce, chatRecallBoost); err != nil {
+		slog.Warn("reinforcing recalled memories failed", "error", err)
+	}
+	return active
+}
+
+// KeyMemories returns the pair's strongest memories still in active recall,
+// for the chat prompt. Failures are logged and yield none.
+func (s *RetrievalService) KeyMemories(ctx context.Context, userID, companionID uuid.UUID) []models.Memory {
+	if s.memoryCfg.PromptMemories <= 0 {
+		return nil
+	}
+	memories, err := s.memories.GetStrongest(ctx, userID, companionID, time.Now(), halfLifeDays(s.memoryCfg.HalfLife),
+		s.memoryCfg.FadeThreshold, s.memoryCfg.PromptMemories)
+	if err != nil {
+		slog.Warn("loading key memories failed", "error", err)
+		return nil
+	}
+	return memories
+}
+
+// exchangeAt returns msgs[i] with its counterpart in chronological order:
+// a user message and the reply that followed it, or a companion message and
+// the user message it answered. msgs must be in conversation order with
+// msgs[i]'s neighbours either side of it.
+func exchangeAt(msgs []models.Message, i int) []models.Message {
+	msg := msgs[i]
+	if msg.Role == "user" {
+		if i+1 < len(msgs) && msgs[i+1].Role != "user" {
+			return []models.Message{msg, msgs[i+1]}
+		}
+		return []models.Message{msg}
+	}
+	if i > 0 && msgs[i-1].Role == "user" {
+		return []models.Message{msgs[i-1], msg}
+	}
+	return []models.Message{msg}
+}
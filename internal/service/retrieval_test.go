@@ -0,0 +1,56 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"ai-companion-be/internal/models"
+)
+
+// conversation builds messages from roles, "u" for the user and "c" for the
+// companion, with the index as content.
+func conversation(roles ...string) []models.Message {
+	msgs := make([]models.Message, len(roles))
+	for i, r := range roles {
+		role := "companion"
+		if r == "u" {
+			role = "user"
+		}
+		msgs[i] = models.Message{Role: role, Content: string(rune('0' + i))}
+	}
+	return msgs
+}
+
+func contents(msgs []models.Message) string {
+	var b strings.Builder
+	for _, m := range msgs {
+		b.WriteString(m.Content)
+	}
+	return b.String()
+}
+
+func TestExchangeAt(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []string
+		i     int
+		want  string
+	}{
+		{"user then reply", []string{"u", "c"}, 0, "01"},
+		{"reply to user", []string{"u", "c"}, 1, "01"},
+		{"user mid conversation", []string{"c", "u", "c", "u"}, 1, "12"},
+		{"reply mid conversation", []string{"u", "c", "u", "c"}, 3, "23"},
+		{"user without reply", []string{"c", "u"}, 1, "1"},
+		{"user followed by user", []string{"u", "u", "c"}, 0, "0"},
+		{"companion opener", []string{"c", "u"}, 0, "0"},
+		{"companion after companion", []string{"u", "c", "c"}, 2, "2"},
+		{"single message", []string{"u"}, 0, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contents(exchangeAt(conversation(tt.roles...), tt.i)); got != tt.want {
+				t.Errorf("exchangeAt = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/cursor"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+)
+
+const (
+	searchCursorKind   = "search"
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+
+	// MaxSearchQuery is the longest search query accepted, in bytes.
+	MaxSearchQuery = 200
+)
+
+// SearchService handles full-text search across a user's conversations.
+type SearchService struct {
+	search  repository.SearchRepository
+	cursors *cursor.Codec
+}
+
+// NewSearchService creates a new SearchService.
+func NewSearchService(search repository.SearchRepository, cursors *cursor.Codec) *SearchService {
+	return &SearchService{search: search, cursors: cursors}
+}
+
+// Search returns a page of the user's messages and memories matching q,
+// newest first; q.Query must be trimmed and non-empty. Each result carries
+// an around= cursor that opens the conversation at the matching message.
+// Malformed cursors return an error wrapping cursor.ErrInvalid.
+func (s *SearchService) Search(ctx context.Context, userID uuid.UUID, q models.SearchQuery) (*models.SearchPage, error) {
+	limit := q.Limit
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+
+	kind := searchCursorKindFor(q.SearchFilter)
+	var after *models.SearchKey
+	if q.Cursor != "" {
+		var key models.SearchKey
+		if err := s.cursors.Decode(kind, q.Cursor, &key); err != nil {
+			return nil, err
+		}
+		after = &key
+	}
+
+	results, err := s.search.Search(ctx, userID, q.SearchFilter, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.SearchPage{Results: results, HasMore: len(results) > limit}
+	if page.HasMore {
+		page.Results = results[:limit]
+		last := page.Results[limit-1]
+		page.NextCursor = s.cursors.Encode(kind, models.SearchKey{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	for i := range page.Results {
+		res := &page.Results[i]
+		res.Snippet = markSnippet(res.Snippet)
+		if res.MessageID != nil && res.MessageCreatedAt != nil {
+			res.Around = s.cursors.Encode(messageCursorKind, models.MessageKey{CreatedAt: *res.MessageCreatedAt, ID: *res.MessageID})
+		}
+	}
+	return page, nil
+}
+
+// searchCursorKindFor scopes search cursors to the query and filters they
+// were issued for, so a cursor can't be replayed against a different search.
+func searchCursorKindFor(f models.SearchFilter) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:type=%s:q=%q", searchCursorKind, f.Type, f.Query)
+	if f.CompanionID != nil {
+		fmt.Fprintf(&b, ":companion=%s", *f.CompanionID)
+	}
+	if f.From != nil {
+		fmt.Fprintf(&b, ":from=%d", f.From.UnixNano())
+	}
+	if f.To != nil {
+		fmt.Fprintf(&b, ":to=%d", f.To.UnixNano())
+	}
+	return b.String()
+}
+
+// markSnippet HTML-escapes a search headline and wraps its matches in <mark>.
+func markSnippet(snippet string) string {
+	return strings.NewReplacer(
+		repository.SearchMatchStart, "<mark>",
+		repository.SearchMatchStop, "</mark>",
+	).Replace(html.EscapeString(snippet))
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,21 +13,107 @@ import (
 	"ai-companion-be/internal/repository"
 )
 
+const maxHighlightTitle = 50
+
 // StoryService handles story-related business logic.
 type StoryService struct {
 	stories       repository.StoryRepository
 	relationships repository.RelationshipRepository
 	insights      repository.InsightsRepository
+	reactions     repository.ReactionRepository
+	milestones    *MilestoneService
 }
 
 // NewStoryService creates a new StoryService.
-func NewStoryService(stories repository.StoryRepository, relationships repository.RelationshipRepository, insights repository.InsightsRepository) *StoryService {
-	return &StoryService{stories: stories, relationships: relationships, insights: insights}
+func NewStoryService(
+	stories repository.StoryRepository,
+	relationships repository.RelationshipRepository,
+	insights repository.InsightsRepository,
+	reactions repository.ReactionRepository,
+	milestones *MilestoneService,
+) *StoryService {
+	return &StoryService{stories: stories, relationships: relationships, insights: insights, reactions: reactions, milestones: milestones}
+}
+
+// GetByCompanionID returns a companion's active stories, plus expired ones
+// kept in a highlight when mode is StoryListWithHighlights.
+func (s *StoryService) GetByCompanionID(ctx context.Context, companionID uuid.UUID, mode models.StoryListMode) ([]models.Story, error) {
+	return s.stories.GetByCompanionID(ctx, companionID, mode)
+}
+
+// GetHighlights returns a companion's highlights with their stories.
+func (s *StoryService) GetHighlights(ctx context.Context, companionID uuid.UUID) ([]models.StoryHighlight, error) {
+	return s.stories.GetHighlights(ctx, companionID)
+}
+
+// CreateHighlight creates an empty highlight for a companion.
+func (s *StoryService) CreateHighlight(ctx context.Context, companionID uuid.UUID, req models.CreateHighlightRequest) (*models.StoryHighlight, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	if len(title) > maxHighlightTitle {
+		return nil, fmt.Errorf("title must be at most %d characters", maxHighlightTitle)
+	}
+
+	highlight := &models.StoryHighlight{
+		ID:          uuid.New(),
+		CompanionID: companionID,
+		Title:       title,
+		CoverURL:    req.CoverURL,
+		SortOrder:   req.SortOrder,
+		Stories:     []models.Story{},
+	}
+	if err := s.stories.CreateHighlight(ctx, highlight); err != nil {
+		return nil, fmt.Errorf("creating highlight: %w", err)
+	}
+	return highlight, nil
 }
 
-// GetByCompanionID returns all active stories for a companion.
-func (s *StoryService) GetByCompanionID(ctx context.Context, companionID uuid.UUID) ([]models.Story, error) {
-	return s.stories.GetByCompanionID(ctx, companionID)
+// DeleteHighlight removes a highlight. Its stories become eligible for
+// cleanup again once expired, unless saved or in another highlight.
+func (s *StoryService) DeleteHighlight(ctx context.Context, highlightID uuid.UUID) error {
+	return s.stories.DeleteHighlight(ctx, highlightID)
+}
+
+// AddToHighlight adds one of the highlight's companion's stories to it.
+func (s *StoryService) AddToHighlight(ctx context.Context, highlightID, storyID uuid.UUID) error {
+	highlight, err := s.stories.GetHighlightByID(ctx, highlightID)
+	if err != nil {
+		return err
+	}
+	story, err := s.stories.GetByID(ctx, storyID)
+	if err != nil {
+		return err
+	}
+	if story.CompanionID != highlight.CompanionID {
+		return fmt.Errorf("story belongs to a different companion")
+	}
+	return s.stories.AddToHighlight(ctx, highlightID, storyID)
+}
+
+// RemoveFromHighlight takes a story out of a highlight.
+func (s *StoryService) RemoveFromHighlight(ctx context.Context, highlightID, storyID uuid.UUID) error {
+	return s.stories.RemoveFromHighlight(ctx, highlightID, storyID)
+}
+
+// SaveStory bookmarks a story for the user so it outlives its expiry.
+// Saving is idempotent.
+func (s *StoryService) SaveStory(ctx context.Context, userID, storyID uuid.UUID) error {
+	if _, err := s.stories.GetByID(ctx, storyID); err != nil {
+		return err
+	}
+	return s.stories.SaveStory(ctx, userID, storyID)
+}
+
+// UnsaveStory removes a story from the user's saved list.
+func (s *StoryService) UnsaveStory(ctx context.Context, userID, storyID uuid.UUID) error {
+	return s.stories.UnsaveStory(ctx, userID, storyID)
+}
+
+// GetSavedStories returns the user's saved stories, most recently saved first.
+func (s *StoryService) GetSavedStories(ctx context.Context, userID uuid.UUID) ([]models.Story, error) {
+	return s.stories.GetSavedStories(ctx, userID)
 }
 
 // GetActiveStories returns a paginated list of currently active stories.
@@ -38,13 +126,51 @@ func (s *StoryService) GetActiveStoriesGrouped(ctx context.Context, userID uuid.
 	return s.stories.GetActiveStoriesGrouped(ctx, userID)
 }
 
+// CleanupExpired deletes expired stories that are neither highlighted nor
+// saved. It runs as a scheduled job.
+func (s *StoryService) CleanupExpired(ctx context.Context) error {
+	n, err := s.stories.CleanupExpired(ctx)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		slog.Info("expired stories deleted", "count", n)
+	}
+	return nil
+}
+
+// Relationship effect of a reaction with sentiment weight 1.
+const (
+	reactionMoodBoost         = 3
+	reactionRelationshipBoost = 2
+)
+
 // ReactToStory records a user's reaction and updates the relationship state.
+// Replacing an earlier reaction to the same slide reverses its effect first.
 func (s *StoryService) ReactToStory(ctx context.Context, userID uuid.UUID, storyID uuid.UUID, req models.ReactToStoryRequest) error {
-	validReactions := map[string]bool{
-		"love": true, "sad": true, "heart_eyes": true, "angry": true,
+	rt, err := s.reactions.GetByKey(ctx, req.Reaction)
+	if err != nil || !rt.Active {
+		return fmt.Errorf("invalid reaction: %s", req.Reaction)
 	}
-	if !validReactions[req.Reaction] {
-		return fmt.Errorf("invalid reaction: must be love, sad, heart_eyes, or angry")
+
+	// The slide must belong to the story being reacted to. Any slide type,
+	// including text slides, can be reacted to.
+	media, err := s.stories.GetMediaByID(ctx, req.MediaID)
+	if err != nil {
+		return err
+	}
+	if media.StoryID != storyID {
+		return fmt.Errorf("media does not belong to this story")
+	}
+
+	story, err := s.stories.GetByID(ctx, storyID)
+	if err != nil {
+		return err
+	}
+
+	previous, err := s.stories.GetReaction(ctx, userID, req.MediaID)
+	if err != nil {
+		return err
 	}
 
 	reaction := &models.StoryReaction{
@@ -55,34 +181,64 @@ func (s *StoryService) ReactToStory(ctx context.Context, userID uuid.UUID, story
 		Reaction: req.Reaction,
 	}
 
+	// Users without a relationship to the companion can still react; there
+	// is just no state to update.
+	state, err := s.relationships.GetByUserAndCompanion(ctx, userID, story.CompanionID)
+	hasState := err == nil
+	if hasState {
+		if previous != nil {
+			state.MoodScore = clampScore(state.MoodScore - previous.MoodDelta)
+			state.RelationshipScore = clampScore(state.RelationshipScore - previous.RelationshipDelta)
+		}
+		mood := clampScore(state.MoodScore + reactionMoodBoost*rt.SentimentWeight)
+		rel := clampScore(state.RelationshipScore + reactionRelationshipBoost*rt.SentimentWeight)
+		reaction.MoodDelta = mood - state.MoodScore
+		reaction.RelationshipDelta = rel - state.RelationshipScore
+		state.MoodScore, state.RelationshipScore = mood, rel
+	}
+
 	if err := s.stories.CreateReaction(ctx, reaction); err != nil {
 		return fmt.Errorf("creating reaction: %w", err)
 	}
 
-	// Update relationship state: story reactions boost mood and relationship.
-	s.updateRelationshipOnReaction(ctx, userID, storyID)
-
+	if hasState {
+		s.saveRelationshipState(ctx, state)
+	}
 	return nil
 }
 
-func (s *StoryService) updateRelationshipOnReaction(ctx context.Context, userID, storyID uuid.UUID) {
-	// Look up the single story by ID instead of fetching all active stories.
-	story, err := s.stories.GetByID(ctx, storyID)
+// RemoveReaction deletes the user's reaction to a slide and reverses the
+// relationship effect it had.
+func (s *StoryService) RemoveReaction(ctx context.Context, userID, storyID, mediaID uuid.UUID) error {
+	reaction, err := s.stories.DeleteReaction(ctx, userID, storyID, mediaID)
 	if err != nil {
-		return
+		return err
 	}
 
+	story, err := s.stories.GetByID(ctx, storyID)
+	if err != nil {
+		return nil
+	}
 	state, err := s.relationships.GetByUserAndCompanion(ctx, userID, story.CompanionID)
 	if err != nil {
-		return
+		return nil
 	}
 
-	state.MoodScore = clampScore(state.MoodScore + 3)
-	state.RelationshipScore = clampScore(state.RelationshipScore + 2)
+	state.MoodScore = clampScore(state.MoodScore - reaction.MoodDelta)
+	state.RelationshipScore = clampScore(state.RelationshipScore - reaction.RelationshipDelta)
+	s.saveRelationshipState(ctx, state)
+	return nil
+}
+
+func (s *StoryService) saveRelationshipState(ctx context.Context, state *models.RelationshipState) {
 	_ = s.relationships.Update(ctx, state)
 
 	// Record daily mood snapshot for insights.
-	_ = s.insights.RecordMoodSnapshot(ctx, userID, story.CompanionID, state.MoodScore)
+	_ = s.insights.RecordMoodSnapshot(ctx, state.UserID, state.CompanionID, state.MoodScore)
+
+	if _, err := s.milestones.Check(ctx, state.UserID, state.CompanionID); err != nil {
+		slog.Warn("checking milestones failed", "error", err)
+	}
 }
 
 func clampScore(v float64) float64 {
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ai-companion-be/internal/ai"
+	"ai-companion-be/internal/config"
+	"ai-companion-be/internal/models"
+	"ai-companion-be/internal/repository"
+)
+
+// StoryGenerator writes in-character "life update" stories for
+// each companion from their asset library.
+type StoryGenerator struct {
+	companions repository.CompanionRepository
+	assets     repository.AssetRepository
+	drafts     repository.StoryDraftRepository
+	stories    repository.StoryRepository
+	ai         *ai.Client
+	cfg        config.StoryGenConfig
+}
+
+// NewStoryGenerator creates a new StoryGenerator.
+func NewStoryGenerator(
+	companions repository.CompanionRepository,
+	assets repository.AssetRepository,
+	drafts repository.StoryDraftRepository,
+	stories repository.StoryRepository,
+	aiClient *ai.Client,
+	cfg config.StoryGenConfig,
+) *StoryGenerator {
+	return &StoryGenerator{
+		companions: companions,
+		assets:     assets,
+		drafts:     drafts,
+		stories:    stories,
+		ai:         aiClient,
+		cfg:        cfg,
+	}
+}
+
+// RunOnce generates a story for every companion that is not throttled. It
+// runs as a scheduled job. Failures for one companion are logged and do not
+// stop the others.
+func (g *StoryGenerator) RunOnce(ctx context.Context) error {
+	companions, err := g.companions.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range companions {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		companion := &companions[i]
+		ok, err := g.allowed(ctx, companion.ID)
+		if err != nil {
+			slog.Error("story generation throttle check failed", "companion", companion.Name, "error", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		draft, err := g.Generate(ctx, companion)
+		if err != nil {
+			slog.Error("story generation failed", "companion", companion.Name, "error", err)
+			continue
+		}
+		slog.Info("story generated", "companion", companion.Name, "draft", draft.ID, "status", draft.Status)
+	}
+
+	return nil
+}
+
+// Generate writes a new story for a companion, ignoring throttling. Depending
+// on configuration the result is either a pending draft or already published.
+func (g *StoryGenerator) Generate(ctx context.Context, companion *models.Companion) (*models.StoryDraft, error) {
+	library, err := g.assets.GetByCompanion(ctx, companion.ID)
+	if err != nil {
+		return nil, err
+	}
+	picked := pickAssets(library, g.cfg.MaxSlides)
+	if len(picked) == 0 {
+		return nil, fmt.Errorf("companion %s has no assets in their library", companion.Name)
+	}
+
+	caption, err := g.ai.GenerateStoryCaption(ctx, companion, picked)
+	if err != nil {
+		return nil, fmt.Errorf("generating caption: %w", err)
+	}
+
+	draft := &models.StoryDraft{
+		ID:          uuid.New(),
+		CompanionID: companion.ID,
+		Caption:     caption,
+		Status:      models.DraftPending,
+		Assets:      picked,
+	}
+	for _, a := range picked {
+		draft.AssetIDs = append(draft.AssetIDs, a.ID)
+	}
+
+	if err := g.drafts.Create(ctx, draft); err != nil {
+		return nil, fmt.Errorf("creating story draft: %w", err)
+	}
+
+	// Mark assets used now rather than on publish so pending drafts don't
+	// all pick the same asset.
+	if err := g.assets.MarkUsed(ctx, draft.AssetIDs); err != nil {
+		slog.Warn("marking assets used failed", "error", err)
+	}
+
+	if g.cfg.RequireReview {
+		return draft, nil
+	}
+	return g.publish(ctx, draft)
+}
+
+// GenerateFor generates a story for one companion on demand (admin trigger).
+func (g *StoryGenerator) GenerateFor(ctx context.Context, companionID uuid.UUID) (*models.StoryDraft, error) {
+	companion, err := g.companions.GetByID(ctx, companionID)
+	if err != nil {
+		return nil, err
+	}
+	return g.Generate(ctx, companion)
+}
+
+// ListDrafts returns drafts with the given status, oldest first, with their assets.
+func (g *StoryGenerator) ListDrafts(ctx context.Context, status string, limit int) ([]models.StoryDraft, error) {
+	drafts, err := g.drafts.GetByStatus(ctx, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range drafts {
+		drafts[i].Assets, err = g.assets.GetByIDs(ctx, drafts[i].AssetIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return drafts, nil
+}
+
+// Approve publishes a pending draft.
+func (g *StoryGenerator) Approve(ctx context.Context, draftID uuid.UUID) (*models.StoryDraft, error) {
+	draft, err := g.drafts.GetByID(ctx, draftID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != models.DraftPending {
+		return nil, fmt.Errorf("story draft is already %s", draft.Status)
+	}
+
+	draft.Assets, err = g.assets.GetByIDs(ctx, draft.AssetIDs)
+	if err != nil {
+		return nil, err
+	}
+	return g.publish(ctx, draft)
+}
+
+// Reject discards a pending draft.
+func (g *StoryGenerator) Reject(ctx context.Context, draftID uuid.UUID) error {
+	return g.drafts.UpdateStatus(ctx, draftID, models.DraftRejected, nil)
+}
+
+// publish turns a pending draft into a story with the standard 24h expiry.
+// The caption goes on the first slide.
+func (g *StoryGenerator) publish(ctx context.Context, draft *models.StoryDraft) (*models.StoryDraft, error) {
+	if len(draft.Assets) == 0 {
+		return nil, fmt.Errorf("story draft has no usable assets")
+	}
+
+	story := &models.Story{
+		ID:          uuid.New(),
+		CompanionID: draft.CompanionID,
+		ExpiresAt:   time.Now().Add(storyLifetime),
+	}
+
+	media := make([]models.StoryMedia, len(draft.Assets))
+	for i, a := range draft.Assets {
+		media[i] = models.StoryMedia{
+			ID:           uuid.New(),
+			StoryID:      story.ID,
+			MediaURL:     a.MediaURL,
+			MediaType:    a.MediaType,
+			Duration:     defaultSlideSecs,
+			ThumbnailURL: a.ThumbnailURL,
+		}
+		if i == 0 {
+			caption := draft.Caption
+			media[i].Caption = &caption
+		}
+	}
+
+	if err := g.drafts.Publish(ctx, draft.ID, story, media); err != nil {
+		return nil, err
+	}
+
+	draft.Status = models.DraftPublished
+	draft.StoryID = &story.ID
+	return draft, nil
+}
+
+// allowed reports whether a companion may get a new story now, given the
+// minimum gap and daily cap.
+func (g *StoryGenerator) allowed(ctx context.Context, companionID uuid.UUID) (bool, error) {
+	count, last, err := g.drafts.GetGenerationStats(ctx, companionID, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return false, err
+	}
+	if g.cfg.MaxPerDay > 0 && count >= g.cfg.MaxPerDay {
+		return false, nil
+	}
+	if last != nil && time.Since(*last) < g.cfg.MinGap {
+		return false, nil
+	}
+	return true, nil
+}
+
+// pickAssets chooses the least recently used asset, then composes up to
+// maxSlides-1 more that share a tag with it so multi-slide stories hang together.
+// library must be ordered least recently used first.
+func pickAssets(library []models.CompanionAsset, maxSlides int) []models.CompanionAsset {
+	if len(library) == 0 {
+		return nil
+	}
+	if maxSlides < 1 {
+		maxSlides = 1
+	}
+
+	lead := library[0]
+	picked := []models.CompanionAsset{lead}
+
+	tags := make(map[string]bool, len(lead.Tags))
+	for _, t := range lead.Tags {
+		tags[t] = true
+	}
+
+	for _, a := range library[1:] {
+		if len(picked) >= maxSlides {
+			break
+		}
+		for _, t := range a.Tags {
+			if tags[t] {
+				picked = append(picked, a)
+				break
+			}
+		}
+	}
+	return picked
+}
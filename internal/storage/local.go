@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStorage stores objects on the local filesystem. It is intended for
+// development and tests; Handler serves the objects over HTTP so URLs behave
+// like a public bucket.
+type LocalStorage struct {
+	root      string
+	publicURL string
+	secret    []byte
+	maxUpload int64
+}
+
+// NewLocalStorage creates a LocalStorage rooted at dir, creating it if needed.
+// Presigned PUTs through Handler are limited to maxUpload bytes.
+func NewLocalStorage(dir, publicURL, secret string, maxUpload int64) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating storage directory: %w", err)
+	}
+	return &LocalStorage{
+		root:      dir,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		secret:    []byte(secret),
+		maxUpload: maxUpload,
+	}, nil
+}
+
+func (s *LocalStorage) Put(_ context.Context, key string, body io.Reader, _ int64, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating object directory: %w", err)
+	}
+
+	// Write to a temp file and rename so readers never see partial objects.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing object: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing object: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("storing object: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) Get(_ context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("opening object: %w", err)
+	}
+
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("stat object: %w", err)
+	}
+
+	return f, &ObjectInfo{
+		Key:         key,
+		ContentType: contentTypeForKey(key),
+		Size:        st.Size(),
+		ModTime:     st.ModTime(),
+	}, nil
+}
+
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("deleting object: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) Presign(_ context.Context, method, key string, expiry time.Duration) (string, error) {
+	if err := validateMethod(method); err != nil {
+		return "", err
+	}
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+
+	expires := time.Now().Add(expiry).Unix()
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(expires, 10))
+	q.Set("signature", s.sign(method, key, expires))
+
+	return s.URL(key) + "?" + q.Encode(), nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+// Handler serves objects under the public URL path. GET is public (mirroring
+// a public bucket); PUT requires a URL produced by Presign.
+// Mount it with the public URL prefix stripped.
+func (s *LocalStorage) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/")
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			if r.URL.Query().Has("signature") && !s.verify(http.MethodGet, key, r.URL.Query()) {
+				http.Error(w, "invalid or expired signature", http.StatusForbidden)
+				return
+			}
+			s.serve(w, r, key)
+
+		case http.MethodPut:
+			if !s.verify(http.MethodPut, key, r.URL.Query()) {
+				http.Error(w, "invalid or expired signature", http.StatusForbidden)
+				return
+			}
+			body := http.MaxBytesReader(w, r.Body, s.maxUpload)
+			if err := s.Put(r.Context(), key, body, r.ContentLength, r.Header.Get("Content-Type")); err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					http.Error(w, "object too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				http.Error(w, "failed to store object", http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// serve writes the object at key. Only regular files are served, so a key
+// naming a directory is not found rather than listed.
+func (s *LocalStorage) serve(w http.ResponseWriter, r *http.Request, key string) {
+	path, err := s.path(key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil || !st.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, st.Name(), st.ModTime(), f)
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStorage) sign(method, key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d", method, key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalStorage) verify(method, key string, q url.Values) bool {
+	expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	want := s.sign(method, key, expires)
+	return hmac.Equal([]byte(want), []byte(q.Get("signature")))
+}
+
+func contentTypeForKey(key string) string {
+	if ct := mime.TypeByExtension(filepath.Ext(key)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocalStorage(t *testing.T, maxUpload int64) (*LocalStorage, *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(nil)
+	s, err := NewLocalStorage(t.TempDir(), "http://"+srv.Listener.Addr().String(), "secret", maxUpload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Config.Handler = s.Handler()
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func TestLocalHandlerPut(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"within limit", "hello", http.StatusOK},
+		{"over limit", strings.Repeat("x", 11), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestLocalStorage(t, 10)
+			u, err := s.Presign(context.Background(), http.MethodPut, "stories/a.txt", time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, _ := http.NewRequest(http.MethodPut, u, strings.NewReader(tt.body))
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("PUT status = %d, want %d", resp.StatusCode, tt.want)
+			}
+
+			_, statErr := os.Stat(filepath.Join(s.root, "stories", "a.txt"))
+			if stored := statErr == nil; stored != (tt.want == http.StatusOK) {
+				t.Errorf("object stored = %v, want %v", stored, tt.want == http.StatusOK)
+			}
+		})
+	}
+}
+
+func TestLocalHandlerGet(t *testing.T) {
+	s, srv := newTestLocalStorage(t, 10)
+	if err := s.Put(context.Background(), "stories/c/a.txt", strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"stories/c/a.txt", http.StatusOK},
+		{"stories/c", http.StatusNotFound},
+		{"stories/c/missing.txt", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(srv.URL + "/" + (&url.URL{Path: tt.key}).EscapedPath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("GET %s status = %d, want %d", tt.key, resp.StatusCode, tt.want)
+		}
+	}
+}
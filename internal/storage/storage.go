@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ai-companion-be/internal/config"
+)
+
+// ErrNotFound is returned when an object does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Key         string
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
+
+// Storage is a minimal object store used for avatars and story media.
+//
+// Keys are slash-separated paths such as "stories/{companion_id}/{file}".
+type Storage interface {
+	// Put writes an object, replacing any existing object at key.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+
+	// Get opens an object for reading. The caller must close the reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+
+	// Delete removes an object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// Presign returns a time-limited URL that allows method (GET or PUT)
+	// on key without further credentials.
+	Presign(ctx context.Context, method, key string, expiry time.Duration) (string, error)
+
+	// URL returns the public URL for key.
+	URL(key string) string
+}
+
+// New creates the Storage backend selected by cfg.Driver.
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocalStorage(cfg.LocalDir, cfg.PublicURL, cfg.SigningSecret, max(cfg.MaxImageSize, cfg.MaxVideoSize))
+	case "s3":
+		return NewS3Storage(cfg.S3, cfg.PublicURL)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// validateKey rejects keys that could escape the bucket or root directory.
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid object key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid object key %q", key)
+		}
+	}
+	return nil
+}
+
+func validateMethod(method string) error {
+	if method != http.MethodGet && method != http.MethodPut {
+		return fmt.Errorf("unsupported presign method %q", method)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+
+	// Register decoders for the upload formats we can thumbnail.
+	_ "image/gif"
+	_ "image/png"
+)
+
+// MaxThumbnailPixels caps the width × height of images Thumbnail decodes.
+// Image headers can declare dimensions far beyond the file's size, so a few
+// kilobytes could otherwise make the decoder allocate gigabytes.
+const MaxThumbnailPixels = 50_000_000
+
+// Thumbnail decodes a JPEG, PNG or GIF image and returns a JPEG whose longest
+// side is at most maxDim pixels. Images already within bounds are re-encoded
+// at their original size. Images over MaxThumbnailPixels are rejected from
+// their header, before decoding.
+func Thumbnail(r io.Reader, maxDim int) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading image: %w", err)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image header: %w", err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, fmt.Errorf("image has no pixels")
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxThumbnailPixels {
+		return nil, fmt.Errorf("image is too large to thumbnail: %dx%d pixels", cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return nil, fmt.Errorf("image has no pixels")
+	}
+
+	tw, th := w, h
+	if w > maxDim || h > maxDim {
+		if w >= h {
+			tw, th = maxDim, max(1, h*maxDim/w)
+		} else {
+			tw, th = max(1, w*maxDim/h), maxDim
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+
+	// Box filter: average every source pixel that maps onto each target pixel.
+	for y := 0; y < th; y++ {
+		sy0 := b.Min.Y + y*h/th
+		sy1 := max(sy0+1, b.Min.Y+(y+1)*h/th)
+		for x := 0; x < tw; x++ {
+			sx0 := b.Min.X + x*w/tw
+			sx1 := max(sx0+1, b.Min.X+(x+1)*w/tw)
+
+			var rs, gs, bs, as, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					rs, gs, bs, as = rs+uint64(cr), gs+uint64(cg), bs+uint64(cb), as+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(rs / n), G: uint16(gs / n), B: uint16(bs / n), A: uint16(as / n),
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, fmt.Errorf("encoding thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
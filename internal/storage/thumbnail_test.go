@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestThumbnailScalesLongestSide(t *testing.T) {
+	tests := []struct {
+		name         string
+		w, h         int
+		wantW, wantH int
+	}{
+		{"landscape", 640, 320, 320, 160},
+		{"portrait", 100, 400, 80, 320},
+		{"within bounds", 200, 100, 200, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb, err := Thumbnail(bytes.NewReader(encodePNG(t, tt.w, tt.h)), 320)
+			if err != nil {
+				t.Fatalf("Thumbnail: %v", err)
+			}
+			cfg, err := jpeg.DecodeConfig(bytes.NewReader(thumb))
+			if err != nil {
+				t.Fatalf("decoding thumbnail: %v", err)
+			}
+			if cfg.Width != tt.wantW || cfg.Height != tt.wantH {
+				t.Errorf("thumbnail is %dx%d, want %dx%d", cfg.Width, cfg.Height, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestThumbnailRejectsDecompressionBomb(t *testing.T) {
+	// A 1x1 PNG whose header is rewritten to claim 50000x50000 pixels.
+	data := encodePNG(t, 1, 1)
+	ihdr := data[8:] // length, "IHDR", 13 bytes of data, CRC
+	binary.BigEndian.PutUint32(ihdr[8:], 50000)
+	binary.BigEndian.PutUint32(ihdr[12:], 50000)
+	binary.BigEndian.PutUint32(ihdr[21:], crc32.ChecksumIEEE(ihdr[4:21]))
+
+	_, err := Thumbnail(bytes.NewReader(data), 320)
+	if err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Fatalf("Thumbnail error = %v, want too large", err)
+	}
+}
@@ -0,0 +1,10 @@
+-- ============================================================================
+-- Media uploads: thumbnails for uploaded story slides and companion avatars.
+--
+-- Objects live in the configured storage backend (local disk or an
+-- S3-compatible bucket); these columns hold their public URLs.
+-- NULL means no thumbnail was generated (videos, WebP, or legacy seed rows).
+-- ============================================================================
+
+ALTER TABLE story_media ADD COLUMN IF NOT EXISTS thumbnail_url text;
+ALTER TABLE companions  ADD COLUMN IF NOT EXISTS avatar_thumbnail_url text;
@@ -0,0 +1,49 @@
+-- ============================================================================
+-- AI-generated companion stories.
+--
+-- companion_assets: per-companion media library the generator picks from.
+-- story_drafts:     generated stories, optionally held for review before
+--                   they are published as a regular story.
+-- story_media.caption: text shown over a slide (written by the generator).
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS companion_assets (
+    id             uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    companion_id   uuid NOT NULL REFERENCES companions(id) ON DELETE CASCADE,
+    media_url      text NOT NULL,
+    media_type     text NOT NULL CHECK (media_type IN ('image', 'video')),
+    thumbnail_url  text,
+    description    text NOT NULL DEFAULT '',
+    tags           text[] NOT NULL DEFAULT '{}',
+    last_used_at   timestamptz,
+    created_at     timestamptz NOT NULL DEFAULT now()
+);
+
+-- Generator picks the least recently used asset per companion.
+CREATE INDEX IF NOT EXISTS idx_companion_assets_lru
+    ON companion_assets (companion_id, last_used_at ASC NULLS FIRST);
+
+ALTER TABLE companion_assets ENABLE ROW LEVEL SECURITY;
+
+CREATE TABLE IF NOT EXISTS story_drafts (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    companion_id  uuid NOT NULL REFERENCES companions(id) ON DELETE CASCADE,
+    caption       text NOT NULL,
+    asset_ids     uuid[] NOT NULL DEFAULT '{}',
+    status        text NOT NULL DEFAULT 'pending'
+                  CHECK (status IN ('pending', 'published', 'rejected')),
+    story_id      uuid REFERENCES stories(id) ON DELETE SET NULL,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    reviewed_at   timestamptz
+);
+
+-- Throttling: recent drafts per companion.
+CREATE INDEX IF NOT EXISTS idx_story_drafts_companion_created
+    ON story_drafts (companion_id, created_at DESC);
+-- Review queue: WHERE status = 'pending' ORDER BY created_at
+CREATE INDEX IF NOT EXISTS idx_story_drafts_status_created
+    ON story_drafts (status, created_at);
+
+ALTER TABLE story_drafts ENABLE ROW LEVEL SECURITY;
+
+ALTER TABLE story_media ADD COLUMN IF NOT EXISTS caption text;
@@ -0,0 +1,38 @@
+-- ============================================================================
+-- Text-only and captioned story slides.
+--
+--   media_type 'text'  → a slide with no media file; caption is the body text
+--                        and background holds its style ({color, gradient,
+--                        text_color, font}). media_url is ''.
+--   caption, link_*    → optional on every slide.
+--   messages.story_media_id → the slide a chat message replies to.
+-- ============================================================================
+
+DO $$ BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'story_media_media_type_check'
+          AND pg_get_constraintdef(oid) LIKE '%text%'
+    ) THEN
+        ALTER TABLE story_media DROP CONSTRAINT IF EXISTS story_media_media_type_check;
+        ALTER TABLE story_media ADD CONSTRAINT story_media_media_type_check
+            CHECK (media_type IN ('image', 'video', 'text'));
+    END IF;
+END $$;
+
+ALTER TABLE story_media ADD COLUMN IF NOT EXISTS background jsonb;
+ALTER TABLE story_media ADD COLUMN IF NOT EXISTS link_url   text;
+ALTER TABLE story_media ADD COLUMN IF NOT EXISTS link_label text;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'story_media_text_caption_check') THEN
+        ALTER TABLE story_media ADD CONSTRAINT story_media_text_caption_check
+            CHECK (media_type <> 'text' OR caption IS NOT NULL);
+    END IF;
+END $$;
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS story_media_id uuid REFERENCES story_media(id) ON DELETE SET NULL;
+
+-- FK index; most messages are not story replies.
+CREATE INDEX IF NOT EXISTS idx_messages_story_media_id
+    ON messages (story_media_id) WHERE story_media_id IS NOT NULL;
@@ -0,0 +1,80 @@
+-- ============================================================================
+-- Story highlights and saved stories.
+--
+-- Highlights are companion-level collections that keep selected stories
+-- visible after they expire. Saved stories are a per-user bookmark list.
+-- cleanup_expired_stories() skips anything highlighted or saved.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS story_highlights (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    companion_id  uuid NOT NULL REFERENCES companions(id) ON DELETE CASCADE,
+    title         text NOT NULL,
+    cover_url     text,
+    sort_order    int NOT NULL DEFAULT 0,
+    created_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_story_highlights_companion
+    ON story_highlights (companion_id, sort_order);
+
+ALTER TABLE story_highlights ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'story_highlights' AND policyname = 'story_highlights_read_all') THEN
+        CREATE POLICY story_highlights_read_all ON story_highlights FOR SELECT USING (true);
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS story_highlight_items (
+    highlight_id  uuid NOT NULL REFERENCES story_highlights(id) ON DELETE CASCADE,
+    story_id      uuid NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    sort_order    int NOT NULL DEFAULT 0,
+    added_at      timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (highlight_id, story_id)
+);
+
+-- Cleanup and the with-highlights feed look items up by story.
+CREATE INDEX IF NOT EXISTS idx_story_highlight_items_story_id ON story_highlight_items (story_id);
+
+ALTER TABLE story_highlight_items ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'story_highlight_items' AND policyname = 'story_highlight_items_read_all') THEN
+        CREATE POLICY story_highlight_items_read_all ON story_highlight_items FOR SELECT USING (true);
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS saved_stories (
+    user_id   uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    story_id  uuid NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    saved_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, story_id)
+);
+
+-- PK serves per-user listing; this serves the cleanup check.
+CREATE INDEX IF NOT EXISTS idx_saved_stories_story_id ON saved_stories (story_id);
+
+ALTER TABLE saved_stories ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'saved_stories' AND policyname = 'saved_stories_own_access') THEN
+        CREATE POLICY saved_stories_own_access ON saved_stories FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+END $$;
+
+-- Keep highlighted and saved stories when cleaning up expired ones.
+CREATE OR REPLACE FUNCTION cleanup_expired_stories()
+RETURNS integer
+LANGUAGE sql
+AS $$
+    WITH deleted AS (
+        DELETE FROM stories s
+        WHERE s.expires_at < NOW() - INTERVAL '1 hour'
+          AND NOT EXISTS (SELECT 1 FROM story_highlight_items hi WHERE hi.story_id = s.id)
+          AND NOT EXISTS (SELECT 1 FROM saved_stories ss WHERE ss.story_id = s.id)
+        RETURNING id
+    )
+    SELECT count(*)::integer FROM deleted;
+$$;
@@ -0,0 +1,33 @@
+-- ============================================================================
+-- Scheduled job run history.
+--
+-- Every replica runs the in-process scheduler. Before running a job a
+-- replica takes pg_try_advisory_xact_lock for the job and claims the
+-- (job_name, scheduled_for) slot here; the unique constraint means each
+-- slot runs on exactly one replica even if the lock is taken in turns.
+-- Transaction-scoped locks are used because session locks do not survive
+-- the transaction-mode pooler.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    id             bigserial PRIMARY KEY,
+    job_name       text NOT NULL,
+    scheduled_for  timestamptz NOT NULL,
+    instance       text NOT NULL,
+    status         text NOT NULL DEFAULT 'running'
+                   CHECK (status IN ('running', 'succeeded', 'failed')),
+    error          text,
+    started_at     timestamptz NOT NULL DEFAULT now(),
+    finished_at    timestamptz,
+    UNIQUE (job_name, scheduled_for)
+);
+
+-- History listing per job, newest first. The unique constraint's index
+-- (job_name, scheduled_for) also serves this, so only pruning needs its own.
+CREATE INDEX IF NOT EXISTS idx_job_runs_started_at ON job_runs (started_at);
+
+-- Overlap check: is a run of this job still in progress?
+CREATE INDEX IF NOT EXISTS idx_job_runs_running
+    ON job_runs (job_name) WHERE status = 'running';
+
+ALTER TABLE job_runs ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,54 @@
+-- ============================================================================
+-- Reaction catalogue.
+--
+-- reaction_types replaces the reaction keys hard-coded in the story service
+-- and insights queries. Deactivating a type stops new reactions with it but
+-- keeps it in summaries and history.
+--
+-- sentiment_weight scales the relationship effect of a reaction: a weight of
+-- 1 gives the original +3 mood / +2 relationship, negative weights lower them.
+--
+-- story_reactions.mood_delta / relationship_delta record the effect actually
+-- applied (after clamping), so removing or changing a reaction can reverse it
+-- exactly.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS reaction_types (
+    key               text PRIMARY KEY CHECK (key ~ '^[a-z][a-z0-9_]*$'),
+    emoji             text NOT NULL,
+    label             text NOT NULL,
+    display_order     int NOT NULL DEFAULT 0,
+    sentiment_weight  real NOT NULL DEFAULT 1,
+    active            boolean NOT NULL DEFAULT true,
+    created_at        timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE reaction_types ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'reaction_types' AND policyname = 'reaction_types_read_all') THEN
+        CREATE POLICY reaction_types_read_all ON reaction_types FOR SELECT USING (true);
+    END IF;
+END $$;
+
+-- Seed the original four. DO NOTHING so edits made since are kept.
+INSERT INTO reaction_types (key, emoji, label, display_order, sentiment_weight) VALUES
+    ('love',       '❤️', 'love',       1,  1.0),
+    ('heart_eyes', '😍', 'heart eyes', 2,  1.0),
+    ('sad',        '😢', 'sad',        3,  0.5),
+    ('angry',      '😠', 'angry',      4, -0.5)
+ON CONFLICT (key) DO NOTHING;
+
+-- Reactions made before this migration all applied +3 / +2, so backfill
+-- those when the columns are first added.
+DO $$ BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'story_reactions' AND column_name = 'mood_delta'
+    ) THEN
+        ALTER TABLE story_reactions
+            ADD COLUMN mood_delta real NOT NULL DEFAULT 0,
+            ADD COLUMN relationship_delta real NOT NULL DEFAULT 0;
+        UPDATE story_reactions SET mood_delta = 3, relationship_delta = 2;
+    END IF;
+END $$;
@@ -0,0 +1,91 @@
+-- ============================================================================
+-- Story performance analytics.
+--
+-- story_views is the raw event stream (one row per slide view). A scheduled
+-- job folds it, together with story_reactions and story replies in messages,
+-- into per-slide daily rollups that the admin reports read:
+--
+--   story_media_viewers        one row per (slide, user, day)
+--   story_media_daily_stats    one row per (slide, day)
+--   story_media_reaction_stats one row per (slide, day, reaction)
+--
+-- Rollups carry story_id/companion_id and have no foreign keys, so figures
+-- survive the expired-story cleanup. Days are UTC.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS story_views (
+    id          bigserial PRIMARY KEY,
+    user_id     uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    story_id    uuid NOT NULL REFERENCES stories(id) ON DELETE CASCADE,
+    media_id    uuid NOT NULL REFERENCES story_media(id) ON DELETE CASCADE,
+    completed   boolean NOT NULL DEFAULT false,
+    watched_ms  int NOT NULL DEFAULT 0,
+    viewed_at   timestamptz NOT NULL DEFAULT now()
+);
+
+-- Rollup and pruning scan by time.
+CREATE INDEX IF NOT EXISTS idx_story_views_viewed_at ON story_views (viewed_at);
+CREATE INDEX IF NOT EXISTS idx_story_views_media_id ON story_views (media_id);
+
+ALTER TABLE story_views ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'story_views' AND policyname = 'story_views_own_access') THEN
+        CREATE POLICY story_views_own_access ON story_views FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS story_media_viewers (
+    media_id      uuid NOT NULL,
+    user_id       uuid NOT NULL,
+    day           date NOT NULL,
+    story_id      uuid NOT NULL,
+    companion_id  uuid NOT NULL,
+    views         int NOT NULL,
+    completed     boolean NOT NULL,
+    PRIMARY KEY (media_id, day, user_id)
+);
+
+-- Per-story reports count distinct viewers across all of a story's slides.
+CREATE INDEX IF NOT EXISTS idx_story_media_viewers_story_day
+    ON story_media_viewers (story_id, day);
+
+ALTER TABLE story_media_viewers ENABLE ROW LEVEL SECURITY;
+
+CREATE TABLE IF NOT EXISTS story_media_daily_stats (
+    media_id            uuid NOT NULL,
+    day                 date NOT NULL,
+    story_id            uuid NOT NULL,
+    companion_id        uuid NOT NULL,
+    views               int NOT NULL DEFAULT 0,
+    viewers             int NOT NULL DEFAULT 0,
+    completions         int NOT NULL DEFAULT 0,
+    reactions           int NOT NULL DEFAULT 0,
+    replies             int NOT NULL DEFAULT 0,
+    mood_delta          real NOT NULL DEFAULT 0,
+    relationship_delta  real NOT NULL DEFAULT 0,
+    PRIMARY KEY (media_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_story_media_daily_stats_story_day
+    ON story_media_daily_stats (story_id, day);
+CREATE INDEX IF NOT EXISTS idx_story_media_daily_stats_companion_day
+    ON story_media_daily_stats (companion_id, day);
+
+ALTER TABLE story_media_daily_stats ENABLE ROW LEVEL SECURITY;
+
+CREATE TABLE IF NOT EXISTS story_media_reaction_stats (
+    media_id      uuid NOT NULL,
+    day           date NOT NULL,
+    reaction      text NOT NULL,
+    story_id      uuid NOT NULL,
+    companion_id  uuid NOT NULL,
+    count         int NOT NULL,
+    PRIMARY KEY (media_id, day, reaction)
+);
+
+CREATE INDEX IF NOT EXISTS idx_story_media_reaction_stats_story_day
+    ON story_media_reaction_stats (story_id, day);
+
+ALTER TABLE story_media_reaction_stats ENABLE ROW LEVEL SECURITY;
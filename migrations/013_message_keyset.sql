@@ -0,0 +1,13 @@
+-- ============================================================================
+-- Message keyset pagination on (created_at, id).
+--
+-- Cursors now carry the message id as a tie-breaker so messages sharing a
+-- timestamp are never skipped. The new index serves both directions
+-- (before: backward scan, after: forward scan) and "jump to message".
+-- idx_messages_conversation is a left-prefix of it and is dropped.
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_messages_conversation_keyset
+    ON messages (user_id, companion_id, created_at DESC, id DESC);
+
+DROP INDEX IF EXISTS idx_messages_conversation;
@@ -0,0 +1,13 @@
+-- ============================================================================
+-- Memory keyset pagination on (pinned, created_at, id).
+--
+-- Memory listings page with a cursor over pinned DESC, created_at DESC,
+-- id DESC; the id tie-breaker keeps memories sharing a timestamp from being
+-- skipped. idx_memories_user_companion is a left-prefix of the new index
+-- and is dropped.
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_memories_keyset
+    ON memories (user_id, companion_id, pinned DESC, created_at DESC, id DESC);
+
+DROP INDEX IF EXISTS idx_memories_user_companion;
@@ -0,0 +1,67 @@
+-- ============================================================================
+-- Memory editing and per-user tag vocabulary.
+--
+-- memory_edits keeps the previous and new content/tag of every edit made
+-- through PATCH /api/memories/{id}. memory_tags is each user's tag
+-- vocabulary; memories.tag stays a plain text column holding the tag name,
+-- and rename/merge rewrite it on every affected memory.
+--
+-- Tags are normalised to lower case with collapsed whitespace; existing tags
+-- are normalised here and seeded into the vocabulary.
+-- ============================================================================
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'memories' AND column_name = 'edited_at') THEN
+        ALTER TABLE memories ADD COLUMN edited_at timestamptz;
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS memory_edits (
+    id                uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    memory_id         uuid NOT NULL REFERENCES memories(id) ON DELETE CASCADE,
+    user_id           uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    previous_content  text NOT NULL,
+    previous_tag      text,
+    content           text NOT NULL,
+    tag               text,
+    edited_at         timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_memory_edits_memory ON memory_edits (memory_id, edited_at DESC);
+
+ALTER TABLE memory_edits ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'memory_edits' AND policyname = 'memory_edits_own_access') THEN
+        CREATE POLICY memory_edits_own_access ON memory_edits FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS memory_tags (
+    id          uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name        text NOT NULL CHECK (name <> ''),
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (user_id, name)
+);
+
+ALTER TABLE memory_tags ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'memory_tags' AND policyname = 'memory_tags_own_access') THEN
+        CREATE POLICY memory_tags_own_access ON memory_tags FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+END $$;
+
+-- Normalise free-form tags written before the vocabulary existed.
+UPDATE memories SET tag = NULLIF(lower(regexp_replace(btrim(tag), '\s+', ' ', 'g')), '')
+WHERE tag IS DISTINCT FROM NULLIF(lower(regexp_replace(btrim(tag), '\s+', ' ', 'g')), '');
+
+INSERT INTO memory_tags (user_id, name)
+SELECT DISTINCT user_id, tag FROM memories WHERE tag IS NOT NULL
+ON CONFLICT (user_id, name) DO NOTHING;
+
+-- Serves tag filters, per-companion tag counts and rename/merge rewrites.
+CREATE INDEX IF NOT EXISTS idx_memories_user_tag ON memories (user_id, tag) WHERE tag IS NOT NULL;
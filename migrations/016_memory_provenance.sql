@@ -0,0 +1,34 @@
+-- ============================================================================
+-- Memory provenance.
+--
+--   memories.message_id     → the chat message a memory was saved from.
+--   memories.story_media_id → the story slide a memory was saved from.
+--
+-- message_id was used by the code (memory creation, is_memorized) but never
+-- created by a migration, so fresh databases failed. Both links are SET NULL
+-- on delete: the memory outlives its source (e.g. expired-story cleanup).
+-- ============================================================================
+
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS message_id uuid;
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS story_media_id uuid;
+
+-- Databases patched by hand may have the column without its foreign key.
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'memories_message_id_fkey') THEN
+        UPDATE memories m SET message_id = NULL
+        WHERE message_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM messages WHERE id = m.message_id);
+        ALTER TABLE memories ADD CONSTRAINT memories_message_id_fkey
+            FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE SET NULL;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'memories_story_media_id_fkey') THEN
+        ALTER TABLE memories ADD CONSTRAINT memories_story_media_id_fkey
+            FOREIGN KEY (story_media_id) REFERENCES story_media(id) ON DELETE SET NULL;
+    END IF;
+END $$;
+
+-- Most memories have no source; partial indexes keep the is_memorized
+-- lookup and the SET NULL cascades cheap without indexing those rows.
+CREATE INDEX IF NOT EXISTS idx_memories_message_id
+    ON memories (message_id) WHERE message_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_memories_story_media_id
+    ON memories (story_media_id) WHERE story_media_id IS NOT NULL;
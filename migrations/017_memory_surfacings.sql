@@ -0,0 +1,37 @@
+-- ============================================================================
+-- "On this day" memory resurfacing.
+--
+-- The memory-resurfacing job picks at most one memory per user-companion
+-- pair each day: one saved on the same date in an earlier month or year,
+-- or else a pinned memory that hasn't been shown in a while. Each pick is
+-- recorded here; it is shown as a feed card and, optionally, brought up by
+-- the companion in a proactive message (message_id).
+--
+-- The unique key makes the job idempotent per day, and recent rows stop
+-- the same memory being resurfaced too often.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS memory_surfacings (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    memory_id     uuid NOT NULL REFERENCES memories(id) ON DELETE CASCADE,
+    user_id       uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    companion_id  uuid NOT NULL REFERENCES companions(id) ON DELETE CASCADE,
+    reason        text NOT NULL CHECK (reason IN ('on_this_day', 'revisit')),
+    surfaced_on   date NOT NULL,
+    message_id    uuid REFERENCES messages(id) ON DELETE SET NULL,
+    dismissed_at  timestamptz,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (user_id, companion_id, surfaced_on)
+);
+
+-- Cooldown lookups by memory; the unique key serves the feed.
+CREATE INDEX IF NOT EXISTS idx_memory_surfacings_memory ON memory_surfacings (memory_id, surfaced_on DESC);
+
+ALTER TABLE memory_surfacings ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'memory_surfacings' AND policyname = 'memory_surfacings_own_access') THEN
+        CREATE POLICY memory_surfacings_own_access ON memory_surfacings FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+END $$;
@@ -0,0 +1,56 @@
+-- ============================================================================
+-- Memory collections ("albums").
+--
+-- Named, ordered groups of memories for one user-companion pair. A memory
+-- can be in several collections. Without an explicit cover_url, a
+-- collection's cover is the first story slide one of its memories was
+-- saved from (resolved at read time).
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS memory_collections (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    companion_id  uuid NOT NULL REFERENCES companions(id) ON DELETE CASCADE,
+    name          text NOT NULL,
+    description   text,
+    cover_url     text,
+    sort_order    int NOT NULL DEFAULT 0,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (user_id, companion_id, name)
+);
+
+-- The unique key's (user_id, companion_id) prefix serves per-pair listing.
+
+ALTER TABLE memory_collections ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'memory_collections' AND policyname = 'memory_collections_own_access') THEN
+        CREATE POLICY memory_collections_own_access ON memory_collections FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS memory_collection_items (
+    collection_id  uuid NOT NULL REFERENCES memory_collections(id) ON DELETE CASCADE,
+    memory_id      uuid NOT NULL REFERENCES memories(id) ON DELETE CASCADE,
+    sort_order     int NOT NULL DEFAULT 0,
+    added_at       timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (collection_id, memory_id)
+);
+
+-- Deleting a memory cascades through this.
+CREATE INDEX IF NOT EXISTS idx_memory_collection_items_memory_id ON memory_collection_items (memory_id);
+
+ALTER TABLE memory_collection_items ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'memory_collection_items' AND policyname = 'memory_collection_items_own_access') THEN
+        CREATE POLICY memory_collection_items_own_access ON memory_collection_items FOR ALL
+            USING (EXISTS (
+                SELECT 1 FROM memory_collections c
+                WHERE c.id = collection_id
+                  AND c.user_id = (select current_setting('app.current_user_id', true))::uuid
+            ));
+    END IF;
+END $$;
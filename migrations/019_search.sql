@@ -0,0 +1,17 @@
+-- ============================================================================
+-- Full-text search over messages and memories.
+--
+-- Stored generated tsvector columns stay in sync with content (including
+-- memory edits) without triggers; GIN indexes serve the @@ match. Search is
+-- always scoped to one user, which is applied after the index lookup.
+-- A memory's tag is searchable along with its content.
+-- ============================================================================
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', content)) STORED;
+
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', content || ' ' || coalesce(tag, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_messages_search ON messages USING gin (search_vector);
+CREATE INDEX IF NOT EXISTS idx_memories_search ON memories USING gin (search_vector);
@@ -0,0 +1,45 @@
+-- ============================================================================
+-- Embeddings for semantic retrieval.
+--
+-- One vector per message or memory per embedding model, written by the
+-- embedding-index job. Vectors are stored as real[] so any model and
+-- dimension fits; when the pgvector extension is available the repository
+-- casts them to vector and ranks in SQL, otherwise it ranks in-process.
+-- content_hash is the md5 of the embedded text, so edited memories are
+-- re-embedded.
+-- ============================================================================
+
+DO $$ BEGIN
+    IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector') THEN
+        CREATE EXTENSION IF NOT EXISTS vector;
+    END IF;
+EXCEPTION WHEN insufficient_privilege THEN
+    RAISE NOTICE 'pgvector not enabled (insufficient privilege); using in-process similarity';
+END $$;
+
+CREATE TABLE IF NOT EXISTS embeddings (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    companion_id  uuid NOT NULL REFERENCES companions(id) ON DELETE CASCADE,
+    message_id    uuid REFERENCES messages(id) ON DELETE CASCADE,
+    memory_id     uuid REFERENCES memories(id) ON DELETE CASCADE,
+    model         text NOT NULL,
+    embedding     real[] NOT NULL,
+    content_hash  text NOT NULL,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    CHECK ((message_id IS NULL) <> (memory_id IS NULL)),
+    UNIQUE (message_id, model),
+    UNIQUE (memory_id, model)
+);
+
+-- Retrieval scans one pair's vectors for the current model.
+CREATE INDEX IF NOT EXISTS idx_embeddings_pair ON embeddings (user_id, companion_id, model);
+
+ALTER TABLE embeddings ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'embeddings' AND policyname = 'embeddings_own_access') THEN
+        CREATE POLICY embeddings_own_access ON embeddings FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+END $$;
@@ -0,0 +1,32 @@
+-- ============================================================================
+-- Memory importance and forgetting curve.
+--
+-- importance (0..1) is rated when a memory is saved and boosted whenever it
+-- is recalled in chat, resurfaced or rescued; last_recalled_at records the
+-- latest of those. A memory's strength decays from its importance with a
+-- configurable half-life since it was last reinforced. Pinned memories
+-- never fade. Below a threshold strength a memory drops out of the
+-- companion's active recall until it is reinforced again.
+-- ============================================================================
+
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS importance real NOT NULL DEFAULT 0.5;
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS last_recalled_at timestamptz;
+ALTER TABLE memories ADD COLUMN IF NOT EXISTS recall_count int NOT NULL DEFAULT 0;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'memories_importance_range') THEN
+        ALTER TABLE memories ADD CONSTRAINT memories_importance_range CHECK (importance BETWEEN 0 AND 1);
+    END IF;
+END $$;
+
+-- Strength of a memory at a point in time. Mirrors models.Memory.Strength;
+-- keep the two in step.
+CREATE OR REPLACE FUNCTION memory_strength(
+    importance real, pinned boolean, reinforced_at timestamptz, at timestamptz, half_life_days double precision
+) RETURNS double precision
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT CASE
+        WHEN pinned THEN 1
+        ELSE importance * power(0.5, greatest(extract(epoch FROM at - reinforced_at), 0) / 86400 / half_life_days)
+    END
+$$;
@@ -0,0 +1,14 @@
+-- ============================================================================
+-- User time zones.
+--
+-- Day boundaries for streaks, mood snapshots, milestones and days together
+-- follow the user's IANA time zone rather than the database session's.
+-- Names are validated by the app when set.
+--
+-- Existing users start out in UTC, which is how their days were counted
+-- until now, so nothing shifts until they set a zone. Mood snapshots keep
+-- the date they were recorded under: they store no time of day to re-date
+-- them by, and from the next snapshot on new days are local.
+-- ============================================================================
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone text NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,147 @@
+-- ============================================================================
+-- Milestones and notifications.
+--
+-- milestone_definitions replaces the milestone list hard-coded in the
+-- insights service. A milestone is achieved once its metric reaches its
+-- threshold for a user-companion pair:
+--   messages / memories   count in the conversation
+--   days_together         calendar days since the first message, in the
+--                         user's time zone, both ends included
+--   mood / relationship   relationship_states score
+-- Deactivating a definition stops new achievements but keeps existing ones.
+--
+-- user_milestones records each achievement once, with when it happened and
+-- the companion's celebratory message. Achievements are never revoked, so a
+-- mood milestone stays achieved when the mood drops again.
+--
+-- notifications is the user's event feed; achieving a milestone adds one.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS milestone_definitions (
+    key            text PRIMARY KEY CHECK (key ~ '^[a-z][a-z0-9_]*$'),
+    title          text NOT NULL,
+    description    text NOT NULL,
+    metric         text NOT NULL CHECK (metric IN ('messages', 'memories', 'days_together', 'mood', 'relationship')),
+    threshold      real NOT NULL CHECK (threshold > 0),
+    display_order  int NOT NULL DEFAULT 0,
+    active         boolean NOT NULL DEFAULT true,
+    created_at     timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE milestone_definitions ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'milestone_definitions' AND policyname = 'milestone_definitions_read_all') THEN
+        CREATE POLICY milestone_definitions_read_all ON milestone_definitions FOR SELECT USING (true);
+    END IF;
+END $$;
+
+-- Seed the original milestones. DO NOTHING so edits made since are kept.
+INSERT INTO milestone_definitions (key, title, description, metric, threshold, display_order) VALUES
+    ('first_message',  'First Words',         'Sent your first message',              'messages',      1,    1),
+    ('messages_50',    'Getting Chatty',      'Exchanged 50 messages',                'messages',      50,   2),
+    ('messages_200',   'Deep Conversations',  'Exchanged 200 messages',               'messages',      200,  3),
+    ('messages_1000',  'Inseparable',         'Exchanged 1,000 messages',             'messages',      1000, 4),
+    ('first_memory',   'First Memory',        'Saved your first memory together',     'memories',      1,    5),
+    ('memories_10',    'Memory Lane',         'Saved 10 memories together',           'memories',      10,   6),
+    ('week_together',  'One Week Together',   'Been connected for 7 days',            'days_together', 7,    7),
+    ('month_together', 'One Month Together',  'Been connected for 30 days',           'days_together', 30,   8),
+    ('mood_happy',     'Warming Up',          'Reached Happy mood level',             'mood',          50,   9),
+    ('mood_attached',  'Deeply Attached',     'Reached Attached mood level',          'mood',          80,   10),
+    ('bond_50',        'Strong Bond',         'Relationship score reached 50',        'relationship',  50,   11),
+    ('bond_max',       'Soulmates',           'Reached maximum relationship score',   'relationship',  100,  12)
+ON CONFLICT (key) DO NOTHING;
+
+-- Milestones reached before this migration are backfilled, without
+-- celebrations, when the table is first created. Counts date from the
+-- message or memory that reached the threshold and mood from the first
+-- snapshot at or above it; relationship scores keep no history, so those
+-- date from the state's last update.
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'user_milestones') THEN
+        CREATE TABLE user_milestones (
+            id             uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+            user_id        uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            companion_id   uuid NOT NULL REFERENCES companions(id) ON DELETE CASCADE,
+            milestone_key  text NOT NULL REFERENCES milestone_definitions(key) ON UPDATE CASCADE,
+            achieved_at    timestamptz NOT NULL,
+            message_id     uuid REFERENCES messages(id) ON DELETE SET NULL,
+            created_at     timestamptz NOT NULL DEFAULT now(),
+            UNIQUE(user_id, companion_id, milestone_key)
+        );
+
+        INSERT INTO user_milestones (user_id, companion_id, milestone_key, achieved_at)
+        SELECT rs.user_id, rs.companion_id, d.key, a.at
+        FROM relationship_states rs
+        JOIN users u ON u.id = rs.user_id
+        CROSS JOIN milestone_definitions d
+        CROSS JOIN LATERAL (
+            SELECT CASE d.metric
+                WHEN 'messages' THEN (
+                    SELECT x.created_at FROM (
+                        SELECT created_at, row_number() OVER (ORDER BY created_at, id) AS n
+                        FROM messages WHERE user_id = rs.user_id AND companion_id = rs.companion_id
+                    ) x WHERE x.n = ceil(d.threshold)
+                )
+                WHEN 'memories' THEN (
+                    SELECT x.created_at FROM (
+                        SELECT created_at, row_number() OVER (ORDER BY created_at, id) AS n
+                        FROM memories WHERE user_id = rs.user_id AND companion_id = rs.companion_id
+                    ) x WHERE x.n = ceil(d.threshold)
+                )
+                WHEN 'days_together' THEN (
+                    SELECT (((min(created_at) AT TIME ZONE u.timezone)::date + ceil(d.threshold)::int - 1)::timestamp) AT TIME ZONE u.timezone
+                    FROM messages WHERE user_id = rs.user_id AND companion_id = rs.companion_id
+                )
+                WHEN 'mood' THEN coalesce(
+                    (
+                        SELECT min(recorded_date)::timestamp AT TIME ZONE u.timezone
+                        FROM mood_history
+                        WHERE user_id = rs.user_id AND companion_id = rs.companion_id AND mood_score >= d.threshold
+                    ),
+                    CASE WHEN rs.mood_score >= d.threshold THEN rs.updated_at END
+                )
+                WHEN 'relationship' THEN CASE WHEN rs.relationship_score >= d.threshold THEN rs.updated_at END
+            END AS at
+        ) a
+        WHERE d.active AND a.at IS NOT NULL AND a.at <= now()
+        ON CONFLICT DO NOTHING;
+    END IF;
+END $$;
+
+ALTER TABLE user_milestones ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'user_milestones' AND policyname = 'user_milestones_own_access') THEN
+        CREATE POLICY user_milestones_own_access ON user_milestones FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    companion_id  uuid REFERENCES companions(id) ON DELETE CASCADE,
+    kind          text NOT NULL,
+    title         text NOT NULL,
+    body          text NOT NULL DEFAULT '',
+    data          jsonb NOT NULL DEFAULT '{}',
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    read_at       timestamptz
+);
+
+-- Query pattern: WHERE user_id = $1 [AND read_at IS NULL]
+-- ORDER BY created_at DESC, id DESC
+CREATE INDEX IF NOT EXISTS idx_notifications_user
+    ON notifications (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread
+    ON notifications (user_id, created_at DESC, id DESC) WHERE read_at IS NULL;
+
+ALTER TABLE notifications ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'notifications' AND policyname = 'notifications_own_access') THEN
+        CREATE POLICY notifications_own_access ON notifications FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+END $$;
@@ -0,0 +1,50 @@
+-- ============================================================================
+-- Conversation analytics snapshots.
+--
+-- The conversation-analytics job analyses each active relationship's recent
+-- messages (a trailing window) and stores one snapshot per pair per day, in
+-- the user's time zone; re-running the job the same day replaces it. A
+-- pair's snapshots over time show how the conversation is changing.
+--
+-- reply_*_seconds measure how long the user takes to answer a companion
+-- message within a session. active_hours counts the user's messages by
+-- local hour (24 entries). sentiment is the mean tone of the user's
+-- messages, -1 to 1, with a per-day breakdown in sentiment_trend. topics
+-- are clusters of the user's messages by embedding similarity, largest
+-- first, labelled with their most distinctive words.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS conversation_snapshots (
+    id                    uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id               uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    companion_id          uuid NOT NULL REFERENCES companions(id) ON DELETE CASCADE,
+    snapshot_date         date NOT NULL,
+    window_start          timestamptz NOT NULL,
+    window_end            timestamptz NOT NULL,
+    user_messages         int NOT NULL DEFAULT 0,
+    companion_messages    int NOT NULL DEFAULT 0,
+    avg_user_length       real NOT NULL DEFAULT 0,
+    avg_companion_length  real NOT NULL DEFAULT 0,
+    replies               int NOT NULL DEFAULT 0,
+    reply_median_seconds  real,
+    reply_p90_seconds     real,
+    active_hours          jsonb NOT NULL DEFAULT '[]',
+    sentiment             real,
+    tone                  text NOT NULL DEFAULT 'neutral',
+    sentiment_trend       jsonb NOT NULL DEFAULT '[]',
+    topics                jsonb NOT NULL DEFAULT '[]',
+    created_at            timestamptz NOT NULL DEFAULT now(),
+    UNIQUE(user_id, companion_id, snapshot_date)
+);
+
+-- Query pattern: WHERE user_id = $1 AND companion_id = $2
+-- ORDER BY snapshot_date DESC — served by the unique index, scanned backwards.
+
+ALTER TABLE conversation_snapshots ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'conversation_snapshots' AND policyname = 'conversation_snapshots_own_access') THEN
+        CREATE POLICY conversation_snapshots_own_access ON conversation_snapshots FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+END $$;
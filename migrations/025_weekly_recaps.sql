@@ -0,0 +1,38 @@
+-- ============================================================================
+-- Weekly relationship recaps.
+--
+-- The weekly-recap job looks back at each relationship the user wrote in
+-- during the last full week, Monday to Sunday in their time zone, and
+-- stores one recap per pair per week: the companion's highlights of the
+-- conversation and a note in their own voice (both written by the LLM),
+-- with the week's message count, new memories, mood trajectory from
+-- mood_history and milestones reached. Recaps are never regenerated, so
+-- the archive reads as it was written.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS weekly_recaps (
+    id            uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    companion_id  uuid NOT NULL REFERENCES companions(id) ON DELETE CASCADE,
+    week_start    date NOT NULL CHECK (extract(isodow FROM week_start) = 1),
+    messages      int NOT NULL DEFAULT 0,
+    highlights    jsonb NOT NULL DEFAULT '[]',
+    memories      jsonb NOT NULL DEFAULT '[]',
+    mood          jsonb NOT NULL DEFAULT '{}',
+    milestones    jsonb NOT NULL DEFAULT '[]',
+    note          text NOT NULL DEFAULT '',
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    UNIQUE(user_id, companion_id, week_start)
+);
+
+-- Query pattern: WHERE user_id = $1 AND companion_id = $2 [AND week_start < $3]
+-- ORDER BY week_start DESC — served by the unique index, scanned backwards.
+
+ALTER TABLE weekly_recaps ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'weekly_recaps' AND policyname = 'weekly_recaps_own_access') THEN
+        CREATE POLICY weekly_recaps_own_access ON weekly_recaps FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+END $$;
@@ -0,0 +1,374 @@
+-- ============================================================================
+-- Insights rollups.
+--
+-- Insights used to count a pair's messages, memories and reactions and pull
+-- every distinct message date into Go on each request, which grows without
+-- bound for heavy users. These tables are kept current by triggers on the
+-- source tables, so every write path is covered, and insights read them
+-- instead:
+--
+--   pair_daily_activity   per pair and local day: messages from each side,
+--                         memories saved and story reactions
+--   pair_stats            per pair: running totals, first and last message,
+--                         chat time, days with a user message and the
+--                         user's messaging streak
+--   user_activity_stats   per user: days with a user message, to any
+--                         companion, and the streak across all of them
+--
+-- Days are the user's local days when the row is written (users.timezone),
+-- like mood_history. Chat time adds each gap between consecutive messages
+-- of at most 30 minutes, the service's chat session gap. A streak is kept
+-- as the latest active day, the run of consecutive days ending there and
+-- the longest run; it is current if that day is today or yesterday.
+--
+-- Deleting messages doesn't shorten streaks, and changing time zone doesn't
+-- move rows already written. rebuild_insights_rollups() recomputes a user's
+-- rollups (or everyone's) from the source tables; cmd/rebuild-rollups runs
+-- it. It is also run once to backfill while the rollups are empty.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS pair_daily_activity (
+    user_id             uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    companion_id        uuid NOT NULL REFERENCES companions(id) ON DELETE CASCADE,
+    activity_date       date NOT NULL,
+    user_messages       int NOT NULL DEFAULT 0,
+    companion_messages  int NOT NULL DEFAULT 0,
+    memories            int NOT NULL DEFAULT 0,
+    reactions           int NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, companion_id, activity_date)
+);
+
+CREATE TABLE IF NOT EXISTS pair_stats (
+    user_id           uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    companion_id      uuid NOT NULL REFERENCES companions(id) ON DELETE CASCADE,
+    total_messages    int NOT NULL DEFAULT 0,
+    total_memories    int NOT NULL DEFAULT 0,
+    total_reactions   int NOT NULL DEFAULT 0,
+    first_message_at  timestamptz,
+    last_message_at   timestamptz,
+    chat_seconds      float8 NOT NULL DEFAULT 0,
+    active_days       int NOT NULL DEFAULT 0,
+    last_active_date  date,
+    current_run       int NOT NULL DEFAULT 0,
+    longest_run       int NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, companion_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_activity_stats (
+    user_id           uuid PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    active_days       int NOT NULL DEFAULT 0,
+    last_active_date  date,
+    current_run       int NOT NULL DEFAULT 0,
+    longest_run       int NOT NULL DEFAULT 0
+);
+
+ALTER TABLE pair_daily_activity ENABLE ROW LEVEL SECURITY;
+ALTER TABLE pair_stats ENABLE ROW LEVEL SECURITY;
+ALTER TABLE user_activity_stats ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'pair_daily_activity' AND policyname = 'pair_daily_activity_own_access') THEN
+        CREATE POLICY pair_daily_activity_own_access ON pair_daily_activity FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'pair_stats' AND policyname = 'pair_stats_own_access') THEN
+        CREATE POLICY pair_stats_own_access ON pair_stats FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'user_activity_stats' AND policyname = 'user_activity_stats_own_access') THEN
+        CREATE POLICY user_activity_stats_own_access ON user_activity_stats FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+END $$;
+
+-- ----------------------------------------------------------------------------
+-- Helpers.
+-- ----------------------------------------------------------------------------
+
+-- The calendar day of at in the user's time zone.
+CREATE OR REPLACE FUNCTION user_local_date(p_user uuid, p_at timestamptz) RETURNS date
+LANGUAGE sql STABLE AS $$
+    SELECT (p_at AT TIME ZONE coalesce((SELECT timezone FROM users WHERE id = p_user), 'UTC'))::date
+$$;
+
+-- The run of consecutive active days after activity on p_day, given the
+-- latest active day and the run ending there. Days before the latest one
+-- leave the run as it is.
+CREATE OR REPLACE FUNCTION streak_run(p_last date, p_run int, p_day date) RETURNS int
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT CASE
+        WHEN p_last IS NULL OR p_day > p_last + 1 THEN 1
+        WHEN p_day = p_last + 1 THEN p_run + 1
+        ELSE p_run
+    END
+$$;
+
+-- Adds delta to one of a pair's daily counters. Decrements only update, so
+-- they never insert rows for a user or companion being deleted.
+CREATE OR REPLACE FUNCTION bump_pair_daily(p_user uuid, p_companion uuid, p_day date, p_column text, p_delta int) RETURNS void
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF p_delta < 0 THEN
+        EXECUTE format(
+            'UPDATE pair_daily_activity SET %1$I = greatest(%1$I + $4, 0)
+             WHERE user_id = $1 AND companion_id = $2 AND activity_date = $3',
+            p_column)
+        USING p_user, p_companion, p_day, p_delta;
+        RETURN;
+    END IF;
+    EXECUTE format(
+        'INSERT INTO pair_daily_activity AS a (user_id, companion_id, activity_date, %1$I) VALUES ($1, $2, $3, $4)
+         ON CONFLICT (user_id, companion_id, activity_date) DO UPDATE SET %1$I = a.%1$I + $4',
+        p_column)
+    USING p_user, p_companion, p_day, p_delta;
+END $$;
+
+-- ----------------------------------------------------------------------------
+-- Triggers.
+-- ----------------------------------------------------------------------------
+
+CREATE OR REPLACE FUNCTION rollup_message() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    d date;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM bump_pair_daily(OLD.user_id, OLD.companion_id, user_local_date(OLD.user_id, OLD.created_at),
+            CASE WHEN OLD.role = 'user' THEN 'user_messages' ELSE 'companion_messages' END, -1);
+        UPDATE pair_stats SET total_messages = greatest(total_messages - 1, 0)
+        WHERE user_id = OLD.user_id AND companion_id = OLD.companion_id;
+        RETURN OLD;
+    END IF;
+
+    d := user_local_date(NEW.user_id, NEW.created_at);
+    PERFORM bump_pair_daily(NEW.user_id, NEW.companion_id, d,
+        CASE WHEN NEW.role = 'user' THEN 'user_messages' ELSE 'companion_messages' END, 1);
+
+    INSERT INTO pair_stats AS ps (user_id, companion_id, total_messages, first_message_at, last_message_at)
+    VALUES (NEW.user_id, NEW.companion_id, 1, NEW.created_at, NEW.created_at)
+    ON CONFLICT (user_id, companion_id) DO UPDATE SET
+        total_messages = ps.total_messages + 1,
+        first_message_at = least(ps.first_message_at, NEW.created_at),
+        last_message_at = greatest(ps.last_message_at, NEW.created_at),
+        chat_seconds = ps.chat_seconds + CASE
+            WHEN NEW.created_at >= ps.last_message_at AND NEW.created_at - ps.last_message_at <= interval '30 minutes'
+            THEN extract(epoch FROM NEW.created_at - ps.last_message_at)
+            ELSE 0
+        END;
+
+    IF NEW.role = 'user' THEN
+        UPDATE pair_stats SET
+            active_days = active_days + (last_active_date IS NULL OR d > last_active_date)::int,
+            current_run = streak_run(last_active_date, current_run, d),
+            longest_run = greatest(longest_run, streak_run(last_active_date, current_run, d)),
+            last_active_date = greatest(last_active_date, d)
+        WHERE user_id = NEW.user_id AND companion_id = NEW.companion_id;
+
+        INSERT INTO user_activity_stats (user_id) VALUES (NEW.user_id) ON CONFLICT DO NOTHING;
+        UPDATE user_activity_stats SET
+            active_days = active_days + (last_active_date IS NULL OR d > last_active_date)::int,
+            current_run = streak_run(last_active_date, current_run, d),
+            longest_run = greatest(longest_run, streak_run(last_active_date, current_run, d)),
+            last_active_date = greatest(last_active_date, d)
+        WHERE user_id = NEW.user_id;
+    END IF;
+    RETURN NEW;
+END $$;
+
+CREATE OR REPLACE TRIGGER messages_rollup
+    AFTER INSERT OR DELETE ON messages
+    FOR EACH ROW EXECUTE FUNCTION rollup_message();
+
+CREATE OR REPLACE FUNCTION rollup_memory() RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM bump_pair_daily(OLD.user_id, OLD.companion_id, user_local_date(OLD.user_id, OLD.created_at), 'memories', -1);
+        UPDATE pair_stats SET total_memories = greatest(total_memories - 1, 0)
+        WHERE user_id = OLD.user_id AND companion_id = OLD.companion_id;
+        RETURN OLD;
+    END IF;
+
+    PERFORM bump_pair_daily(NEW.user_id, NEW.companion_id, user_local_date(NEW.user_id, NEW.created_at), 'memories', 1);
+    INSERT INTO pair_stats AS ps (user_id, companion_id, total_memories)
+    VALUES (NEW.user_id, NEW.companion_id, 1)
+    ON CONFLICT (user_id, companion_id) DO UPDATE SET total_memories = ps.total_memories + 1;
+    RETURN NEW;
+END $$;
+
+CREATE OR REPLACE TRIGGER memories_rollup
+    AFTER INSERT OR DELETE ON memories
+    FOR EACH ROW EXECUTE FUNCTION rollup_memory();
+
+-- Reactions count toward the story's companion. Replacing a reaction
+-- updates the row, so only new and removed reactions change the counts.
+CREATE OR REPLACE FUNCTION rollup_reaction() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    r story_reactions%ROWTYPE;
+    delta int;
+    companion uuid;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        r := OLD;
+        delta := -1;
+    ELSE
+        r := NEW;
+        delta := 1;
+    END IF;
+
+    -- Gone when the story itself is being deleted; stories_rollup has
+    -- already taken its reactions off.
+    SELECT companion_id INTO companion FROM stories WHERE id = r.story_id;
+    IF companion IS NULL THEN
+        RETURN r;
+    END IF;
+
+    PERFORM bump_pair_daily(r.user_id, companion, user_local_date(r.user_id, r.created_at), 'reactions', delta);
+    IF delta < 0 THEN
+        UPDATE pair_stats SET total_reactions = greatest(total_reactions - 1, 0)
+        WHERE user_id = r.user_id AND companion_id = companion;
+    ELSE
+        INSERT INTO pair_stats AS ps (user_id, companion_id, total_reactions)
+        VALUES (r.user_id, companion, 1)
+        ON CONFLICT (user_id, companion_id) DO UPDATE SET total_reactions = ps.total_reactions + 1;
+    END IF;
+    RETURN r;
+END $$;
+
+CREATE OR REPLACE TRIGGER story_reactions_rollup
+    AFTER INSERT OR DELETE ON story_reactions
+    FOR EACH ROW EXECUTE FUNCTION rollup_reaction();
+
+-- Expired stories are deleted with their reactions, which insights stop
+-- counting.
+CREATE OR REPLACE FUNCTION rollup_story_delete() RETURNS trigger
+LANGUAGE plpgsql AS $$
+DECLARE
+    g record;
+BEGIN
+    FOR g IN
+        SELECT user_id, user_local_date(user_id, created_at) AS d, count(*)::int AS n
+        FROM story_reactions WHERE story_id = OLD.id
+        GROUP BY 1, 2
+    LOOP
+        PERFORM bump_pair_daily(g.user_id, OLD.companion_id, g.d, 'reactions', -g.n);
+        UPDATE pair_stats SET total_reactions = greatest(total_reactions - g.n, 0)
+        WHERE user_id = g.user_id AND companion_id = OLD.companion_id;
+    END LOOP;
+    RETURN OLD;
+END $$;
+
+CREATE OR REPLACE TRIGGER stories_rollup
+    BEFORE DELETE ON stories
+    FOR EACH ROW EXECUTE FUNCTION rollup_story_delete();
+
+-- ----------------------------------------------------------------------------
+-- Rebuild.
+-- ----------------------------------------------------------------------------
+
+-- Recomputes the rollups of one user, or of everyone when p_user is NULL,
+-- from the source tables, in the user's current time zone. Writes to the
+-- source tables wait until it finishes.
+CREATE OR REPLACE FUNCTION rebuild_insights_rollups(p_user uuid DEFAULT NULL) RETURNS void
+LANGUAGE plpgsql AS $$
+BEGIN
+    LOCK TABLE messages, memories, story_reactions, stories IN SHARE MODE;
+
+    DELETE FROM pair_daily_activity WHERE p_user IS NULL OR user_id = p_user;
+    DELETE FROM pair_stats WHERE p_user IS NULL OR user_id = p_user;
+    DELETE FROM user_activity_stats WHERE p_user IS NULL OR user_id = p_user;
+
+    INSERT INTO pair_daily_activity (user_id, companion_id, activity_date, user_messages, companion_messages, memories, reactions)
+    SELECT user_id, companion_id, d, sum(um)::int, sum(cm)::int, sum(mem)::int, sum(re)::int
+    FROM (
+        SELECT m.user_id, m.companion_id, (m.created_at AT TIME ZONE u.timezone)::date AS d,
+               (m.role = 'user')::int AS um, (m.role <> 'user')::int AS cm, 0 AS mem, 0 AS re
+        FROM messages m JOIN users u ON u.id = m.user_id
+        WHERE p_user IS NULL OR m.user_id = p_user
+        UNION ALL
+        SELECT mm.user_id, mm.companion_id, (mm.created_at AT TIME ZONE u.timezone)::date, 0, 0, 1, 0
+        FROM memories mm JOIN users u ON u.id = mm.user_id
+        WHERE p_user IS NULL OR mm.user_id = p_user
+        UNION ALL
+        SELECT sr.user_id, s.companion_id, (sr.created_at AT TIME ZONE u.timezone)::date, 0, 0, 0, 1
+        FROM story_reactions sr JOIN stories s ON s.id = sr.story_id JOIN users u ON u.id = sr.user_id
+        WHERE p_user IS NULL OR sr.user_id = p_user
+    ) x
+    GROUP BY user_id, companion_id, d;
+
+    INSERT INTO pair_stats (user_id, companion_id, total_messages, total_memories, total_reactions)
+    SELECT user_id, companion_id,
+           sum(user_messages + companion_messages)::int, sum(memories)::int, sum(reactions)::int
+    FROM pair_daily_activity
+    WHERE p_user IS NULL OR user_id = p_user
+    GROUP BY user_id, companion_id;
+
+    WITH gaps AS (
+        SELECT user_id, companion_id, created_at,
+               created_at - lag(created_at) OVER (PARTITION BY user_id, companion_id ORDER BY created_at, id) AS gap
+        FROM messages
+        WHERE p_user IS NULL OR user_id = p_user
+    )
+    UPDATE pair_stats ps SET
+        first_message_at = g.first_at,
+        last_message_at = g.last_at,
+        chat_seconds = g.seconds
+    FROM (
+        SELECT user_id, companion_id, min(created_at) AS first_at, max(created_at) AS last_at,
+               coalesce(sum(extract(epoch FROM gap)) FILTER (WHERE gap <= interval '30 minutes'), 0)::float8 AS seconds
+        FROM gaps
+        GROUP BY user_id, companion_id
+    ) g
+    WHERE ps.user_id = g.user_id AND ps.companion_id = g.companion_id;
+
+    -- Streaks: consecutive days share d - row_number().
+    WITH runs AS (
+        SELECT user_id, companion_id, max(activity_date) AS last_day, count(*)::int AS len
+        FROM (
+            SELECT user_id, companion_id, activity_date,
+                   activity_date - (row_number() OVER (PARTITION BY user_id, companion_id ORDER BY activity_date))::int AS grp
+            FROM pair_daily_activity
+            WHERE user_messages > 0 AND (p_user IS NULL OR user_id = p_user)
+        ) days
+        GROUP BY user_id, companion_id, grp
+    )
+    UPDATE pair_stats ps SET
+        active_days = s.active_days,
+        last_active_date = s.last_day,
+        current_run = s.current_run,
+        longest_run = s.longest_run
+    FROM (
+        SELECT user_id, companion_id, sum(len)::int AS active_days, max(last_day) AS last_day,
+               (array_agg(len ORDER BY last_day DESC))[1] AS current_run, max(len) AS longest_run
+        FROM runs
+        GROUP BY user_id, companion_id
+    ) s
+    WHERE ps.user_id = s.user_id AND ps.companion_id = s.companion_id;
+
+    WITH days AS (
+        SELECT DISTINCT user_id, activity_date
+        FROM pair_daily_activity
+        WHERE user_messages > 0 AND (p_user IS NULL OR user_id = p_user)
+    ),
+    runs AS (
+        SELECT user_id, max(activity_date) AS last_day, count(*)::int AS len
+        FROM (
+            SELECT user_id, activity_date,
+                   activity_date - (row_number() OVER (PARTITION BY user_id ORDER BY activity_date))::int AS grp
+            FROM days
+        ) d
+        GROUP BY user_id, grp
+    )
+    INSERT INTO user_activity_stats (user_id, active_days, last_active_date, current_run, longest_run)
+    SELECT user_id, sum(len)::int, max(last_day), (array_agg(len ORDER BY last_day DESC))[1], max(len)
+    FROM runs
+    GROUP BY user_id;
+END $$;
+
+-- Backfill while the rollups are empty, i.e. on first run.
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pair_stats) AND EXISTS (SELECT 1 FROM messages) THEN
+        PERFORM rebuild_insights_rollups();
+    END IF;
+END $$;
@@ -0,0 +1,52 @@
+-- ============================================================================
+-- Login sessions and rotating refresh tokens.
+--
+-- Each login starts a session: a device's chain of refresh tokens, with the
+-- user agent and IP it was last used from. Access tokens are short-lived
+-- JWTs carrying the session id, and are rejected once their session is
+-- revoked. Refresh tokens are single use: refreshing marks the presented
+-- token rotated and issues the next one. Only SHA-256 hashes are stored.
+-- Presenting a rotated token again means it leaked, so the whole session
+-- is revoked ('reuse') and every token in its chain stops working.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id              uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent      text NOT NULL DEFAULT '',
+    ip              text NOT NULL DEFAULT '',
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    last_used_at    timestamptz NOT NULL DEFAULT now(),
+    expires_at      timestamptz NOT NULL,
+    revoked_at      timestamptz,
+    revoked_reason  text CHECK (revoked_reason IN ('logout', 'revoked', 'reuse'))
+);
+
+-- Query pattern: WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+-- ORDER BY last_used_at DESC (the session list, revoke-others).
+CREATE INDEX IF NOT EXISTS idx_sessions_user_active
+    ON sessions (user_id, last_used_at DESC) WHERE revoked_at IS NULL;
+
+-- Query pattern: WHERE coalesce(revoked_at, expires_at) < $1 (pruning).
+CREATE INDEX IF NOT EXISTS idx_sessions_ended
+    ON sessions ((coalesce(revoked_at, expires_at)));
+
+ALTER TABLE sessions ENABLE ROW LEVEL SECURITY;
+
+DO $$ BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_policies WHERE tablename = 'sessions' AND policyname = 'sessions_own_access') THEN
+        CREATE POLICY sessions_own_access ON sessions FOR ALL
+            USING (user_id = (select current_setting('app.current_user_id', true))::uuid);
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    token_hash  text PRIMARY KEY,
+    session_id  uuid NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    rotated_at  timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens (session_id);
+
+ALTER TABLE refresh_tokens ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,31 @@
+-- ============================================================================
+-- When a story reaction was last changed.
+--
+-- Changing a reaction updates its row in place, so created_at is when the
+-- user first reacted to the slide. Reaction trends bucket by updated_at
+-- instead, so switching from 'love' to 'angry' a week later counts on the
+-- day of the switch.
+--
+-- created_at still dates the reaction for everything else: the
+-- story_reactions_rollup trigger ignores updates, and pair_daily_activity
+-- and the insights series keep counting a reaction once, on the day it was
+-- first made, as the story analytics rollups do. Existing reactions start
+-- out with updated_at = created_at.
+-- ============================================================================
+
+DO $$ BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'story_reactions' AND column_name = 'updated_at'
+    ) THEN
+        ALTER TABLE story_reactions ADD COLUMN updated_at timestamptz;
+        UPDATE story_reactions SET updated_at = created_at;
+        ALTER TABLE story_reactions
+            ALTER COLUMN updated_at SET DEFAULT now(),
+            ALTER COLUMN updated_at SET NOT NULL;
+    END IF;
+END $$;
+
+-- Query pattern: WHERE user_id = $1 AND updated_at in a range (reaction trends).
+CREATE INDEX IF NOT EXISTS idx_story_reactions_user_updated
+    ON story_reactions (user_id, updated_at);